@@ -67,10 +67,34 @@ func LoadCrontab() {
 				gocron.NewTask(cleanOldSessions),
 				gocron.WithSingletonMode(gocron.LimitModeReschedule),
 			)
-		case "create_clients_passwd":
+		case "purge_revoked_tokens":
 			_, err = scheduler.NewJob(
 				gocron.CronJob(taskConfig.Schedule, false),
-				gocron.NewTask(createClientPasswd),
+				gocron.NewTask(purgeRevokedTokens),
+				gocron.WithSingletonMode(gocron.LimitModeReschedule),
+			)
+		case "rotate_jwt_keys":
+			_, err = scheduler.NewJob(
+				gocron.CronJob(taskConfig.Schedule, false),
+				gocron.NewTask(rotateJwtKeys),
+				gocron.WithSingletonMode(gocron.LimitModeReschedule),
+			)
+		case "migrate_docid_passwd", "create_clients_passwd": // create_clients_passwd is the old name of the task
+			_, err = scheduler.NewJob(
+				gocron.CronJob(taskConfig.Schedule, false),
+				gocron.NewTask(migrateDocidPasswd),
+				gocron.WithSingletonMode(gocron.LimitModeReschedule),
+			)
+		case "import_bank_statements":
+			_, err = scheduler.NewJob(
+				gocron.CronJob(taskConfig.Schedule, false),
+				gocron.NewTask(importBankStatements),
+				gocron.WithSingletonMode(gocron.LimitModeReschedule),
+			)
+		case "autopay_suscripciones":
+			_, err = scheduler.NewJob(
+				gocron.CronJob(taskConfig.Schedule, false),
+				gocron.NewTask(autopaySuscripciones),
 				gocron.WithSingletonMode(gocron.LimitModeReschedule),
 			)
 		case "sinc_tasa_cambio":
@@ -147,10 +171,28 @@ func cleanOldSessions() {
 	}
 }
 
-func createClientPasswd() {
+func purgeRevokedTokens() {
+	defer func() {
+		if r := recover(); r != nil {
+			utils.Logline("Recovered from panic <<purge_revoked_tokens>>: %v", r)
+		}
+	}()
+
+	//set variables for handling pgsql and mysql conn
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	db := models.ConnDb{ConnPgsql: PoolPgsql, Ctx: ctx}
+
+	// run actual task
+	if err := repo.PurgeRevokedTokensCron(db, "cronJob"); err != nil {
+		utils.Logline("Error on purge_revoked_tokens")
+	}
+}
+
+func rotateJwtKeys() {
 	defer func() {
 		if r := recover(); r != nil {
-			utils.Logline("Recovered from panic <<create_clients_passwd>>: %v", r)
+			utils.Logline("Recovered from panic <<rotate_jwt_keys>>: %v", r)
 		}
 	}()
 
@@ -160,8 +202,62 @@ func createClientPasswd() {
 	db := models.ConnDb{ConnPgsql: PoolPgsql, Ctx: ctx}
 
 	// run actual task
-	if err := repo.CreatePasswordsCron(db, "cronJob"); err != nil {
-		utils.Logline("Error on create_clients_passwd")
+	if err := repo.RotateJwtKeysCron(db, "cronJob"); err != nil {
+		utils.Logline("Error on rotate_jwt_keys")
+	}
+}
+
+func importBankStatements() {
+	defer func() {
+		if r := recover(); r != nil {
+			utils.Logline("Recovered from panic <<import_bank_statements>>: %v", r)
+		}
+	}()
+
+	//set variables for handling pgsql and mysql conn
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Second)
+	defer cancel()
+	db := models.ConnDb{ConnPgsql: PoolPgsql, Ctx: ctx}
+
+	// run actual task
+	if err := repo.ImportBankStatementsCron(db, "cronJob"); err != nil {
+		utils.Logline("Error on import_bank_statements")
+	}
+}
+
+func autopaySuscripciones() {
+	defer func() {
+		if r := recover(); r != nil {
+			utils.Logline("Recovered from panic <<autopay_suscripciones>>: %v", r)
+		}
+	}()
+
+	//set variables for handling pgsql and mysql conn
+	ctx, cancel := context.WithTimeout(context.Background(), 55*time.Second)
+	defer cancel()
+	db := models.ConnDb{ConnPgsql: PoolPgsql, Ctx: ctx}
+
+	// run actual task
+	if err := repo.AutopagoSuscripcionesCron(db, "cronJob"); err != nil {
+		utils.Logline("Error on autopay_suscripciones")
+	}
+}
+
+func migrateDocidPasswd() {
+	defer func() {
+		if r := recover(); r != nil {
+			utils.Logline("Recovered from panic <<migrate_docid_passwd>>: %v", r)
+		}
+	}()
+
+	//set variables for handling pgsql and mysql conn
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Second)
+	defer cancel()
+	db := models.ConnDb{ConnPgsql: PoolPgsql, Ctx: ctx}
+
+	// run actual task
+	if err := repo.MigrateDocidPasswdCron(db, "cronJob"); err != nil {
+		utils.Logline("Error on migrate_docid_passwd")
 	}
 }
 
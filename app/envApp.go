@@ -2,6 +2,7 @@ package app
 
 import (
 	"os"
+	"strings"
 
 	"github.com/joho/godotenv"
 	"ired.com/micuenta/utils"
@@ -15,3 +16,15 @@ func LoadEnvVariables() {
 		os.Exit(1)
 	}
 }
+
+// secrets that must be defined, the jwt libraries sign and verify with an empty
+// key, so the app does not start without them
+var requiredSecrets = []string{"FILE_URL_SECRET", "PREAUTH_SECRET", "TOTP_SECRET_KEY", "RECEIPT_VERIFY_SECRET"}
+
+func CheckRequiredSecrets() {
+	for _, name := range requiredSecrets {
+		if strings.TrimSpace(os.Getenv(name)) == "" {
+			utils.Fatalf("%s is not defined", name)
+		}
+	}
+}
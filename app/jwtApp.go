@@ -0,0 +1,12 @@
+package app
+
+import (
+	"ired.com/micuenta/utils"
+)
+
+// load the keys used to sign and verify the jwt tokens
+func InitJwtKeys() {
+	if err := utils.LoadJwtKeys(); err != nil {
+		utils.Fatalf("Error loading jwt keys: %v", err)
+	}
+}
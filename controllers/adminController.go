@@ -0,0 +1,260 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	ginI18n "github.com/gin-contrib/i18n"
+	"github.com/gin-gonic/gin"
+	"ired.com/micuenta/app"
+	"ired.com/micuenta/middlewares"
+	"ired.com/micuenta/models"
+	"ired.com/micuenta/repo"
+)
+
+func AdminRoutes(r *gin.Engine) {
+	admin := r.Group("/admin")
+	{
+		admin.POST("/tokens/revoke", middlewares.BasicAuth(), adminTokensRevoke)
+		admin.POST("/banco/import", middlewares.BasicAuth(), adminBankImport)
+		admin.GET("/banco/revision", middlewares.BasicAuth(), adminBankReview)
+		admin.GET("/suscripcion/show", middlewares.BasicAuth(), adminSuscripcionShow)
+	}
+}
+
+// @Summary        Revoke access tokens
+// @Description    Revokes one access token by its jti, or all the tokens and sessions of the cliente when jti is empty
+// @Tags           Admin
+// @Accept         json
+// @Produce        json
+// @Security       BasicAuth
+// @Param          revoke body models.AdminRevokeReq true "Cliente and optional jti"
+// @Success 200    {object} models.SuccessResponse
+// @Failure 400    {object} models.ErrorResponse "Invalid Request"
+// @Failure 401    {object} models.ErrorResponse "Unauthorized"
+// @Router         /admin/tokens/revoke [post]
+func adminTokensRevoke(c *gin.Context) {
+	// validate if body exist
+	if c.Request.ContentLength == 0 {
+		c.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			models.ErrorResponse{Error: ginI18n.MustGetMessage(c, "errorFailedBody")},
+		)
+		return
+	}
+
+	// Bind and Validate the data and the struct
+	var adminReq models.AdminRevokeReq
+	if err := c.ShouldBindJSON(&adminReq); err != nil {
+		if strings.Contains(err.Error(), "invalid character") || strings.Contains(err.Error(), "unmarshal") {
+			c.AbortWithStatusJSON(
+				http.StatusBadRequest,
+				models.ErrorResponse{Error: ginI18n.MustGetMessage(c, "invalidJson")},
+			)
+			return
+		}
+
+		errorFormJson := models.ParseError(err, c)
+		c.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			models.ErrorResponse{Error: errorFormJson},
+		)
+		return
+	}
+
+	// set variables for handling dbs conns
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	db := models.ConnDb{ConnPgsql: app.PoolPgsql, Ctx: ctx}
+
+	errType, err := repo.AdminRevokeTokens(c, db, adminReq)
+	if err != nil {
+		c.JSON(
+			errType,
+			models.ErrorResponse{Error: ginI18n.MustGetMessage(c, err.Error())},
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		models.SuccessResponse{Notice: ginI18n.MustGetMessage(c, "tokensRevoked")},
+	)
+}
+
+// @Summary        Import bank statement
+// @Description    Imports a csv bank statement with the layout given and reconciles the lines with the pending payments, the exact matches are marked as conciliated for back office and the lines of overlapping statements are skipped
+// @Tags           Admin
+// @Accept         multipart/form-data
+// @Produce        json
+// @Security       BasicAuth
+// @Param          layout formData string true "name of the layout on BANK_LAYOUTS_FILE"
+// @Param          file formData file true "csv statement"
+// @Success 200    {object} models.SuccessResponse{record=models.BancoImportResult}
+// @Failure 400    {object} models.ErrorResponse "Invalid Request"
+// @Failure 401    {object} models.ErrorResponse "Unauthorized"
+// @Failure 409    {object} models.ErrorResponse "Statement already imported"
+// @Router         /admin/banco/import [post]
+func adminBankImport(c *gin.Context) {
+	// Bind and Validate the data and the struct
+	var importReq models.BancoImportReq
+	if err := c.ShouldBind(&importReq); err != nil {
+		errorFormJson := models.ParseError(err, c)
+		c.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			models.ErrorResponse{Error: errorFormJson},
+		)
+		return
+	}
+
+	// validate that file exist
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			models.ErrorResponse{Error: ginI18n.MustGetMessage(c, "bankStatementInvalid")},
+		)
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			models.ErrorResponse{Error: ginI18n.MustGetMessage(c, "bankStatementInvalid")},
+		)
+		return
+	}
+	defer file.Close()
+	content, err := io.ReadAll(file)
+	if err != nil {
+		c.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			models.ErrorResponse{Error: ginI18n.MustGetMessage(c, "bankStatementInvalid")},
+		)
+		return
+	}
+
+	// set variables for handling dbs conns
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Second)
+	defer cancel()
+	db := models.ConnDb{ConnPgsql: app.PoolPgsql, Ctx: ctx}
+
+	result, errType, err := repo.ImportBankStatement(db, importReq.Layout, fileHeader.Filename, content, "restApi")
+	if err != nil {
+		c.JSON(
+			errType,
+			models.ErrorResponse{Error: ginI18n.MustGetMessage(c, err.Error())},
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		models.SuccessResponse{
+			Notice: ginI18n.MustGetMessage(c, "bankStatementImported"),
+			Record: result,
+		},
+	)
+}
+
+// @Summary        Bank lines to review
+// @Description    Lines of the imported statements that could not be reconciled automatically, with the candidate payments
+// @Tags           Admin
+// @Accept         json
+// @Produce        json
+// @Security       BasicAuth
+// @Param          page query int false "Page number" default(1)
+// @Param          limit query int false "Number of records per page" default(10)
+// @Success 200    {object} models.SuccessResponseWithMeta{record=[]models.BancoRevision}
+// @Failure 400    {object} models.ErrorResponse "Invalid Request"
+// @Failure 401    {object} models.ErrorResponse "Unauthorized"
+// @Router         /admin/banco/revision [get]
+func adminBankReview(c *gin.Context) {
+	// Bind and Validate the data and the struct
+	paginatorQueryUri := models.PaginatorQueryUri{Page: json.Number("1"), Limit: json.Number("10")}
+	if err := c.ShouldBind(&paginatorQueryUri); err != nil {
+		errorFormJson := models.ParseError(err, c)
+		c.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			models.ErrorResponse{Error: errorFormJson},
+		)
+		return
+	}
+
+	// trasnform uri into int struct paginator
+	paginatorQuery := models.TransformPaginator(paginatorQueryUri)
+
+	// set variables for handling dbs conns
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	db := models.ConnDb{ConnPgsql: app.PoolPgsql, Ctx: ctx}
+
+	revisionData, paginatorData, err := repo.BankReviewList(db, paginatorQuery)
+	if err != nil {
+		c.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			models.ErrorResponse{Error: ginI18n.MustGetMessage(c, err.Error())},
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		models.SuccessResponseWithMeta{
+			Notice: ginI18n.MustGetMessage(c, "queryOK"),
+			Meta:   paginatorData,
+			Record: revisionData,
+		},
+	)
+}
+
+// @Summary        Suscripcion detail for support
+// @Description    Same view of /suscripcion/show for any suscripcion: plan, install date, current status, saldo, facturas, payments and retenciones
+// @Tags           Admin
+// @Accept         json
+// @Produce        json
+// @Security       BasicAuth
+// @Param          suscripcion_id query string true "Suscripcion Id"
+// @Success 200    {object} models.SuccessResponse{record=models.SuscripcionDetalle}
+// @Failure 400    {object} models.ErrorResponse "Invalid Request"
+// @Failure 401    {object} models.ErrorResponse "Unauthorized"
+// @Failure 404    {object} models.ErrorResponse "Suscripcion not found"
+// @Router         /admin/suscripcion/show [get]
+func adminSuscripcionShow(c *gin.Context) {
+	// Bind and Validate the data and the struct
+	var suscripcionReq models.SuscripcionReqId
+	if err := c.ShouldBind(&suscripcionReq); err != nil {
+		errorFormJson := models.ParseError(err, c)
+		c.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			models.ErrorResponse{Error: errorFormJson},
+		)
+		return
+	}
+
+	// set variables for handling dbs conns
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	db := models.ConnDb{ConnPgsql: app.PoolPgsql, Ctx: ctx}
+
+	suscripcion, errType, err := repo.AdminSuscripcionShow(db, suscripcionReq)
+	if err != nil {
+		c.JSON(
+			errType,
+			models.ErrorResponse{Error: ginI18n.MustGetMessage(c, err.Error())},
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		models.SuccessResponse{
+			Notice: ginI18n.MustGetMessage(c, "queryOK"),
+			Record: suscripcion,
+		},
+	)
+}
@@ -23,7 +23,18 @@ func AuthRoutes(r *gin.Engine) {
 		auth.POST("/refresh", authRefresh)
 		auth.POST("/forgot-password-req", authForgotPasswordReq)
 		auth.POST("/forgot-password-send", authForgotPasswordSend)
+		auth.POST("/forgot-password-sms-req", authForgotPasswordSmsReq)
+		auth.POST("/forgot-password-sms-verify", authForgotPasswordSmsVerify)
+		auth.POST("/activation-req", authActivationReq)
+		auth.POST("/activation-send", authActivationSend)
 		auth.POST("/change-password", middlewares.JwtPasswdAuth, authChangePassword)
+		auth.GET("/sessions", middlewares.JwtAuth, authSessionList)
+		auth.POST("/sessions/revoke", middlewares.JwtAuth, authSessionRevoke)
+		auth.POST("/sessions/revoke-others", middlewares.JwtAuth, authSessionRevokeOthers)
+		auth.POST("/2fa/enroll", middlewares.JwtAuth, authTwoFactorEnroll)
+		auth.POST("/2fa/activate", middlewares.JwtAuth, authTwoFactorActivate)
+		auth.POST("/2fa/disable", middlewares.JwtAuth, authTwoFactorDisable)
+		auth.POST("/2fa/verify", authTwoFactorVerify)
 	}
 }
 
@@ -34,9 +45,11 @@ func AuthRoutes(r *gin.Engine) {
 // @Produce        json
 // @Param          x-access-token header string false "Access Token"
 // @Param          credentials body models.UserRequest true "User Login Data"
-// @Success 200    {object} models.SuccessResponse{record=models.UserResponse}
+// @Success 200    {object} models.SuccessResponse{record=models.UserResponse} "Logged in, or models.TwoFactorChallenge when second factor is required"
 // @Failure 400    {object} models.ErrorResponse "Invalid Request or Already Logged In"
 // @Failure 401    {object} models.ErrorResponse "Unauthorized"
+// @Failure 403    {object} models.ErrorResponse "Migrated Password Expired, Account Must Be Activated"
+// @Failure 429    {object} models.ErrorResponse "Too Many Failed Attempts or Account Locked"
 // @Router         /auth/login [post]
 func authLogin(c *gin.Context) {
 	// validate if session exist and if so stop login process
@@ -84,14 +97,26 @@ func authLogin(c *gin.Context) {
 	db := models.ConnDb{ConnPgsql: app.PoolPgsql, Ctx: ctx}
 
 	// look up requested user
-	userResponse, err := repo.Login(c, db, userReq)
+	userResponse, challenge, errType, err := repo.Login(c, db, userReq)
 	if err != nil {
 		c.AbortWithStatusJSON(
-			http.StatusUnauthorized,
+			errType,
 			models.ErrorResponse{Error: ginI18n.MustGetMessage(c, err.Error())},
 		)
 		//if error clear seassion and auth cookies
-		repo.Logout(c)
+		repo.Logout(c, db)
+		return
+	}
+
+	// user has two-factor enabled, the login continues on /auth/2fa/verify
+	if challenge != nil {
+		c.JSON(
+			http.StatusOK,
+			models.SuccessResponse{
+				Notice: ginI18n.MustGetMessage(c, "twoFactorRequired"),
+				Record: challenge,
+			},
+		)
 		return
 	}
 
@@ -142,7 +167,12 @@ func authRefresh(c *gin.Context) {
 // @Success 200    {object} models.SuccessResponse
 // @Router         /auth/logout [get]
 func authLogout(c *gin.Context) {
-	repo.Logout(c)
+	//set variables for handling dbs conns
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	db := models.ConnDb{ConnPgsql: app.PoolPgsql, Ctx: ctx}
+
+	repo.Logout(c, db)
 
 	c.JSON(
 		http.StatusOK,
@@ -210,14 +240,14 @@ func authChangePassword(c *gin.Context) {
 }
 
 // @Summary        Request password reset
-// @Description    Sends a password reset email to the user after validating the request
+// @Description    Sends a password reset email to the user after validating the request, the response is the same for any docid
 // @Tags           Authentication
 // @Accept         json
 // @Produce        json
 // @Param          user body models.ForgotPasswordRequest true "User email for password reset"
 // @Success 200    {object} models.SuccessResponse
 // @Failure 400    {object} models.ErrorResponse "Invalid Request"
-// @Failure 404    {object} models.ErrorResponse "User Not Found"
+// @Failure 429    {object} models.ErrorResponse "Too Many Requests"
 // @Router         /auth/forgot-password [post]
 func authForgotPasswordReq(c *gin.Context) {
 	// validate if body exist
@@ -253,7 +283,7 @@ func authForgotPasswordReq(c *gin.Context) {
 	defer cancel()
 	db := models.ConnDb{ConnPgsql: app.PoolPgsql, Ctx: ctx}
 
-	email, errType, err := repo.ForgotPasswordReq(c, db, userReq)
+	errType, err := repo.ForgotPasswordReq(c, db, userReq)
 	if err != nil {
 		c.JSON(
 			errType,
@@ -262,9 +292,10 @@ func authForgotPasswordReq(c *gin.Context) {
 		return
 	}
 
+	// same response for any docid to not expose which accounts exist
 	c.JSON(
 		http.StatusOK,
-		models.SuccessResponse{Notice: ginI18n.MustGetMessage(c, "emailPasswordSent") + " " + email + " " + ginI18n.MustGetMessage(c, "emailInstuctions")},
+		models.SuccessResponse{Notice: ginI18n.MustGetMessage(c, "passwordResetSent")},
 	)
 }
 
@@ -326,3 +357,596 @@ func authForgotPasswordSend(c *gin.Context) {
 		models.SuccessResponse{Notice: ginI18n.MustGetMessage(c, "changePasswdOk")},
 	)
 }
+
+// @Summary        Request password reset code by sms
+// @Description    Sends a one-time code to the mobile phone of the account, the response is the same for any docid
+// @Tags           Authentication
+// @Accept         json
+// @Produce        json
+// @Param          user body models.ForgotPasswordRequest true "Docid of the account"
+// @Success 200    {object} models.SuccessResponse
+// @Failure 400    {object} models.ErrorResponse "Invalid Request"
+// @Failure 429    {object} models.ErrorResponse "Too Many Requests"
+// @Router         /auth/forgot-password-sms-req [post]
+func authForgotPasswordSmsReq(c *gin.Context) {
+	// validate if body exist
+	if c.Request.ContentLength == 0 {
+		c.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			models.ErrorResponse{Error: ginI18n.MustGetMessage(c, "errorFailedBody")},
+		)
+		return
+	}
+
+	// Bind and Validate the data and the struct
+	var userReq models.ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&userReq); err != nil {
+		if strings.Contains(err.Error(), "invalid character") || strings.Contains(err.Error(), "unmarshal") {
+			c.AbortWithStatusJSON(
+				http.StatusBadRequest,
+				models.ErrorResponse{Error: ginI18n.MustGetMessage(c, "invalidJson")},
+			)
+			return
+		}
+
+		errorFormJson := models.ParseError(err, c)
+		c.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			models.ErrorResponse{Error: errorFormJson},
+		)
+		return
+	}
+
+	// set variables for handling dbs conns
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	db := models.ConnDb{ConnPgsql: app.PoolPgsql, Ctx: ctx}
+
+	errType, err := repo.ForgotPasswordSmsReq(c, db, userReq)
+	if err != nil {
+		c.JSON(
+			errType,
+			models.ErrorResponse{Error: ginI18n.MustGetMessage(c, err.Error())},
+		)
+		return
+	}
+
+	// same response for any docid to not expose which accounts exist
+	c.JSON(
+		http.StatusOK,
+		models.SuccessResponse{Notice: ginI18n.MustGetMessage(c, "passwordResetSmsSent")},
+	)
+}
+
+// @Summary        Verify password reset code sent by sms
+// @Description    Validates the sms code and returns the token to use on /auth/forgot-password-send
+// @Tags           Authentication
+// @Accept         json
+// @Produce        json
+// @Param          user body models.ForgotPasswordSmsVerify true "Docid and code received"
+// @Success 200    {object} models.SuccessResponse{record=models.ResetTokenResponse}
+// @Failure 400    {object} models.ErrorResponse "Invalid Request"
+// @Failure 401    {object} models.ErrorResponse "Invalid Code"
+// @Failure 429    {object} models.ErrorResponse "Too Many Attempts"
+// @Router         /auth/forgot-password-sms-verify [post]
+func authForgotPasswordSmsVerify(c *gin.Context) {
+	// validate if body exist
+	if c.Request.ContentLength == 0 {
+		c.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			models.ErrorResponse{Error: ginI18n.MustGetMessage(c, "errorFailedBody")},
+		)
+		return
+	}
+
+	// Bind and Validate the data and the struct
+	var userReq models.ForgotPasswordSmsVerify
+	if err := c.ShouldBindJSON(&userReq); err != nil {
+		if strings.Contains(err.Error(), "invalid character") || strings.Contains(err.Error(), "unmarshal") {
+			c.AbortWithStatusJSON(
+				http.StatusBadRequest,
+				models.ErrorResponse{Error: ginI18n.MustGetMessage(c, "invalidJson")},
+			)
+			return
+		}
+
+		errorFormJson := models.ParseError(err, c)
+		c.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			models.ErrorResponse{Error: errorFormJson},
+		)
+		return
+	}
+
+	// set variables for handling dbs conns
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	db := models.ConnDb{ConnPgsql: app.PoolPgsql, Ctx: ctx}
+
+	token, errType, err := repo.ForgotPasswordSmsVerify(c, db, userReq)
+	if err != nil {
+		c.JSON(
+			errType,
+			models.ErrorResponse{Error: ginI18n.MustGetMessage(c, err.Error())},
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		models.SuccessResponse{Notice: ginI18n.MustGetMessage(c, "resetCodeVerified"), Record: token},
+	)
+}
+
+// @Summary        Request activation code
+// @Description    Sends a one-time code to the email of an account without password, the response is the same for any docid
+// @Tags           Authentication
+// @Accept         json
+// @Produce        json
+// @Param          user body models.ActivationRequest true "Docid of the account"
+// @Success 200    {object} models.SuccessResponse
+// @Failure 400    {object} models.ErrorResponse "Invalid Request"
+// @Router         /auth/activation-req [post]
+func authActivationReq(c *gin.Context) {
+	// validate if body exist
+	if c.Request.ContentLength == 0 {
+		c.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			models.ErrorResponse{Error: ginI18n.MustGetMessage(c, "errorFailedBody")},
+		)
+		return
+	}
+
+	// Bind and Validate the data and the struct
+	var userReq models.ActivationRequest
+	if err := c.ShouldBindJSON(&userReq); err != nil {
+		if strings.Contains(err.Error(), "invalid character") || strings.Contains(err.Error(), "unmarshal") {
+			c.AbortWithStatusJSON(
+				http.StatusBadRequest,
+				models.ErrorResponse{Error: ginI18n.MustGetMessage(c, "invalidJson")},
+			)
+			return
+		}
+
+		errorFormJson := models.ParseError(err, c)
+		c.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			models.ErrorResponse{Error: errorFormJson},
+		)
+		return
+	}
+
+	// set variables for handling dbs conns
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	db := models.ConnDb{ConnPgsql: app.PoolPgsql, Ctx: ctx}
+
+	errType, err := repo.ActivationReq(c, db, userReq)
+	if err != nil {
+		c.JSON(
+			errType,
+			models.ErrorResponse{Error: ginI18n.MustGetMessage(c, err.Error())},
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		models.SuccessResponse{Notice: ginI18n.MustGetMessage(c, "activationCodeSent")},
+	)
+}
+
+// @Summary        Activate account
+// @Description    Validates the activation code and sets the password of the account
+// @Tags           Authentication
+// @Accept         json
+// @Produce        json
+// @Param          user body models.ActivationSend true "Docid, code and new password"
+// @Success 200    {object} models.SuccessResponse
+// @Failure 400    {object} models.ErrorResponse "Invalid Request"
+// @Failure 401    {object} models.ErrorResponse "Invalid Code"
+// @Router         /auth/activation-send [post]
+func authActivationSend(c *gin.Context) {
+	// validate if body exist
+	if c.Request.ContentLength == 0 {
+		c.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			models.ErrorResponse{Error: ginI18n.MustGetMessage(c, "errorFailedBody")},
+		)
+		return
+	}
+
+	// Bind and Validate the data and the struct
+	var userReq models.ActivationSend
+	if err := c.ShouldBindJSON(&userReq); err != nil {
+		if strings.Contains(err.Error(), "invalid character") || strings.Contains(err.Error(), "unmarshal") {
+			c.AbortWithStatusJSON(
+				http.StatusBadRequest,
+				models.ErrorResponse{Error: ginI18n.MustGetMessage(c, "invalidJson")},
+			)
+			return
+		}
+
+		errorFormJson := models.ParseError(err, c)
+		c.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			models.ErrorResponse{Error: errorFormJson},
+		)
+		return
+	}
+
+	// set variables for handling dbs conns
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	db := models.ConnDb{ConnPgsql: app.PoolPgsql, Ctx: ctx}
+
+	errType, err := repo.ActivationSend(c, db, userReq)
+	if err != nil {
+		c.JSON(
+			errType,
+			models.ErrorResponse{Error: ginI18n.MustGetMessage(c, err.Error())},
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		models.SuccessResponse{Notice: ginI18n.MustGetMessage(c, "accountActivated")},
+	)
+}
+
+// @Summary        List active sessions
+// @Description    Shows the active sessions (devices) of the logged user
+// @Tags           Authentication
+// @Accept         json
+// @Produce        json
+// @Param          x-access-token header string true "Access Token"
+// @Success 200    {object} models.SuccessResponse{record=[]models.SessionList}
+// @Failure 400    {object} models.ErrorResponse "Invalid Request or Incorrect Data"
+// @Failure 401    {object} models.ErrorResponse "Unauthorized"
+// @Router         /auth/sessions [get]
+func authSessionList(c *gin.Context) {
+	// set variables for handling dbs conns
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	db := models.ConnDb{ConnPgsql: app.PoolPgsql, Ctx: ctx}
+
+	sessionList, errType, err := repo.SessionList(c, db)
+	if err != nil {
+		c.JSON(
+			errType,
+			models.ErrorResponse{Error: ginI18n.MustGetMessage(c, err.Error())},
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		models.SuccessResponse{
+			Notice: ginI18n.MustGetMessage(c, "queryOK"),
+			Record: sessionList,
+		},
+	)
+}
+
+// @Summary        Revoke a session
+// @Description    Closes one of the active sessions of the logged user, e.g. a lost device
+// @Tags           Authentication
+// @Accept         json
+// @Produce        json
+// @Param          x-access-token header string true "Access Token"
+// @Param          session body models.SessionReqId true "Session to revoke"
+// @Success 200    {object} models.SuccessResponse
+// @Failure 400    {object} models.ErrorResponse "Invalid Request or Incorrect Data"
+// @Failure 401    {object} models.ErrorResponse "Unauthorized"
+// @Router         /auth/sessions/revoke [post]
+func authSessionRevoke(c *gin.Context) {
+	// validate if body exist
+	if c.Request.ContentLength == 0 {
+		c.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			models.ErrorResponse{Error: ginI18n.MustGetMessage(c, "errorFailedBody")},
+		)
+		return
+	}
+
+	// Bind and Validate the data and the struct
+	var sessionReq models.SessionReqId
+	if err := c.ShouldBindJSON(&sessionReq); err != nil {
+		if strings.Contains(err.Error(), "invalid character") || strings.Contains(err.Error(), "unmarshal") {
+			c.AbortWithStatusJSON(
+				http.StatusBadRequest,
+				models.ErrorResponse{Error: ginI18n.MustGetMessage(c, "invalidJson")},
+			)
+			return
+		}
+
+		errorFormJson := models.ParseError(err, c)
+		c.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			models.ErrorResponse{Error: errorFormJson},
+		)
+		return
+	}
+
+	// set variables for handling dbs conns
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	db := models.ConnDb{ConnPgsql: app.PoolPgsql, Ctx: ctx}
+
+	errType, err := repo.RevokeSession(c, db, sessionReq)
+	if err != nil {
+		c.JSON(
+			errType,
+			models.ErrorResponse{Error: ginI18n.MustGetMessage(c, err.Error())},
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		models.SuccessResponse{Notice: ginI18n.MustGetMessage(c, "sessionRevoked")},
+	)
+}
+
+// @Summary        Revoke all other sessions
+// @Description    Closes every active session of the logged user except the one making the request
+// @Tags           Authentication
+// @Accept         json
+// @Produce        json
+// @Param          x-access-token header string true "Access Token"
+// @Success 200    {object} models.SuccessResponse
+// @Failure 401    {object} models.ErrorResponse "Unauthorized"
+// @Router         /auth/sessions/revoke-others [post]
+func authSessionRevokeOthers(c *gin.Context) {
+	// set variables for handling dbs conns
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	db := models.ConnDb{ConnPgsql: app.PoolPgsql, Ctx: ctx}
+
+	errType, err := repo.RevokeOtherSessions(c, db)
+	if err != nil {
+		c.JSON(
+			errType,
+			models.ErrorResponse{Error: ginI18n.MustGetMessage(c, err.Error())},
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		models.SuccessResponse{Notice: ginI18n.MustGetMessage(c, "sessionsRevoked")},
+	)
+}
+
+// @Summary        Enroll two-factor authentication
+// @Description    Generates a TOTP secret, its otpauth uri and recovery codes, it must be activated with a valid code
+// @Tags           Authentication
+// @Accept         json
+// @Produce        json
+// @Param          x-access-token header string true "Access Token"
+// @Success 200    {object} models.SuccessResponse{record=models.TwoFactorEnrollResponse}
+// @Failure 400    {object} models.ErrorResponse "Invalid Request or Already Enabled"
+// @Failure 401    {object} models.ErrorResponse "Unauthorized"
+// @Router         /auth/2fa/enroll [post]
+func authTwoFactorEnroll(c *gin.Context) {
+	// set variables for handling dbs conns
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	db := models.ConnDb{ConnPgsql: app.PoolPgsql, Ctx: ctx}
+
+	enrollResponse, errType, err := repo.TwoFactorEnroll(c, db)
+	if err != nil {
+		c.JSON(
+			errType,
+			models.ErrorResponse{Error: ginI18n.MustGetMessage(c, err.Error())},
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		models.SuccessResponse{
+			Notice: ginI18n.MustGetMessage(c, "twoFactorEnroll"),
+			Record: enrollResponse,
+		},
+	)
+}
+
+// @Summary        Activate two-factor authentication
+// @Description    Confirms the enrollment with a code of the authenticator app
+// @Tags           Authentication
+// @Accept         json
+// @Produce        json
+// @Param          x-access-token header string true "Access Token"
+// @Param          code body models.TwoFactorCodeReq true "TOTP code"
+// @Success 200    {object} models.SuccessResponse
+// @Failure 400    {object} models.ErrorResponse "Invalid Request or Incorrect Data"
+// @Failure 401    {object} models.ErrorResponse "Unauthorized or Invalid Code"
+// @Failure 429    {object} models.ErrorResponse "Too Many Invalid Codes"
+// @Router         /auth/2fa/activate [post]
+func authTwoFactorActivate(c *gin.Context) {
+	// validate if body exist
+	if c.Request.ContentLength == 0 {
+		c.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			models.ErrorResponse{Error: ginI18n.MustGetMessage(c, "errorFailedBody")},
+		)
+		return
+	}
+
+	// Bind and Validate the data and the struct
+	var userReq models.TwoFactorCodeReq
+	if err := c.ShouldBindJSON(&userReq); err != nil {
+		if strings.Contains(err.Error(), "invalid character") || strings.Contains(err.Error(), "unmarshal") {
+			c.AbortWithStatusJSON(
+				http.StatusBadRequest,
+				models.ErrorResponse{Error: ginI18n.MustGetMessage(c, "invalidJson")},
+			)
+			return
+		}
+
+		errorFormJson := models.ParseError(err, c)
+		c.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			models.ErrorResponse{Error: errorFormJson},
+		)
+		return
+	}
+
+	// set variables for handling dbs conns
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	db := models.ConnDb{ConnPgsql: app.PoolPgsql, Ctx: ctx}
+
+	errType, err := repo.TwoFactorActivate(c, db, userReq)
+	if err != nil {
+		c.JSON(
+			errType,
+			models.ErrorResponse{Error: ginI18n.MustGetMessage(c, err.Error())},
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		models.SuccessResponse{Notice: ginI18n.MustGetMessage(c, "twoFactorActivated")},
+	)
+}
+
+// @Summary        Disable two-factor authentication
+// @Description    Removes the second factor, requires the password and a TOTP or recovery code
+// @Tags           Authentication
+// @Accept         json
+// @Produce        json
+// @Param          x-access-token header string true "Access Token"
+// @Param          user body models.TwoFactorDisableReq true "Password and code"
+// @Success 200    {object} models.SuccessResponse
+// @Failure 400    {object} models.ErrorResponse "Invalid Request or Incorrect Data"
+// @Failure 401    {object} models.ErrorResponse "Unauthorized or Invalid Code"
+// @Failure 429    {object} models.ErrorResponse "Too Many Invalid Codes"
+// @Router         /auth/2fa/disable [post]
+func authTwoFactorDisable(c *gin.Context) {
+	// validate if body exist
+	if c.Request.ContentLength == 0 {
+		c.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			models.ErrorResponse{Error: ginI18n.MustGetMessage(c, "errorFailedBody")},
+		)
+		return
+	}
+
+	// Bind and Validate the data and the struct
+	var userReq models.TwoFactorDisableReq
+	if err := c.ShouldBindJSON(&userReq); err != nil {
+		if strings.Contains(err.Error(), "invalid character") || strings.Contains(err.Error(), "unmarshal") {
+			c.AbortWithStatusJSON(
+				http.StatusBadRequest,
+				models.ErrorResponse{Error: ginI18n.MustGetMessage(c, "invalidJson")},
+			)
+			return
+		}
+
+		errorFormJson := models.ParseError(err, c)
+		c.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			models.ErrorResponse{Error: errorFormJson},
+		)
+		return
+	}
+
+	// set variables for handling dbs conns
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	db := models.ConnDb{ConnPgsql: app.PoolPgsql, Ctx: ctx}
+
+	errType, err := repo.TwoFactorDisable(c, db, userReq)
+	if err != nil {
+		c.JSON(
+			errType,
+			models.ErrorResponse{Error: ginI18n.MustGetMessage(c, err.Error())},
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		models.SuccessResponse{Notice: ginI18n.MustGetMessage(c, "twoFactorDisabled")},
+	)
+}
+
+// @Summary        Verify the second factor
+// @Description    Finishes the login of a user with two-factor enabled using the pre-auth token and a TOTP or recovery code
+// @Tags           Authentication
+// @Accept         json
+// @Produce        json
+// @Param          user body models.TwoFactorVerifyReq true "Pre-auth token and code"
+// @Success 200    {object} models.SuccessResponse{record=models.UserResponse}
+// @Failure 400    {object} models.ErrorResponse "Invalid Request or Already Logged In"
+// @Failure 401    {object} models.ErrorResponse "Unauthorized or Invalid Code"
+// @Failure 429    {object} models.ErrorResponse "Too Many Invalid Codes"
+// @Router         /auth/2fa/verify [post]
+func authTwoFactorVerify(c *gin.Context) {
+	// validate if session exist and if so stop login process
+	session := sessions.Default(c)
+	refreshTokenSession := session.Get("refresh_token")
+	if refreshTokenSession != nil {
+		c.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			models.ErrorResponse{Error: ginI18n.MustGetMessage(c, "alreadyLogin")},
+		)
+		return
+	}
+
+	// validate if body exist
+	if c.Request.ContentLength == 0 {
+		c.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			models.ErrorResponse{Error: ginI18n.MustGetMessage(c, "errorFailedBody")},
+		)
+		return
+	}
+
+	// Bind and Validate the data and the struct
+	var userReq models.TwoFactorVerifyReq
+	if err := c.ShouldBindJSON(&userReq); err != nil {
+		if strings.Contains(err.Error(), "invalid character") || strings.Contains(err.Error(), "unmarshal") {
+			c.AbortWithStatusJSON(
+				http.StatusBadRequest,
+				models.ErrorResponse{Error: ginI18n.MustGetMessage(c, "invalidJson")},
+			)
+			return
+		}
+
+		errorFormJson := models.ParseError(err, c)
+		c.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			models.ErrorResponse{Error: errorFormJson},
+		)
+		return
+	}
+
+	// set variables for handling dbs conns
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	db := models.ConnDb{ConnPgsql: app.PoolPgsql, Ctx: ctx}
+
+	userResponse, errType, err := repo.TwoFactorVerify(c, db, userReq)
+	if err != nil {
+		c.AbortWithStatusJSON(
+			errType,
+			models.ErrorResponse{Error: ginI18n.MustGetMessage(c, err.Error())},
+		)
+		//if error clear seassion and auth cookies
+		repo.Logout(c, db)
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		models.SuccessResponse{
+			Notice: ginI18n.MustGetMessage(c, "loginSuccessful"),
+			Record: userResponse,
+		},
+	)
+}
@@ -17,7 +17,12 @@ func CronRoutes(r *gin.Engine) {
 	cron := r.Group("/cron")
 	{
 		cron.GET("/clean-old-sessions", middlewares.BasicAuth(), cleanOldSessions)
-		cron.GET("/create-clients-passwd", middlewares.BasicAuth(), createPasswords)
+		cron.GET("/migrate-docid-passwd", middlewares.BasicAuth(), migrateDocidPasswd)
+		cron.GET("/create-clients-passwd", middlewares.BasicAuth(), migrateDocidPasswd) // old name of the task
+		cron.GET("/rotate-jwt-keys", middlewares.BasicAuth(), rotateJwtKeys)
+		cron.GET("/purge-revoked-tokens", middlewares.BasicAuth(), purgeRevokedTokens)
+		cron.GET("/import-bank-statements", middlewares.BasicAuth(), importBankStatements)
+		cron.GET("/autopay-suscripciones", middlewares.BasicAuth(), autopaySuscripciones)
 		cron.GET("/sinc-tasa-cambio", middlewares.BasicAuth(), sincTasaCambio)
 		cron.GET("/sinc-factura-fiscal", middlewares.BasicAuth(), sincFacturaFiscal)
 		cron.GET("/sinc-retencion", middlewares.BasicAuth(), sincRetenciones)
@@ -57,22 +62,138 @@ func cleanOldSessions(c *gin.Context) {
 	)
 }
 
-// @Summary 			Run the task create_client_passwd
-// @Description 	search for all users with password null and set the password the same as the docid
+// @Summary 			Run the task import_bank_statements
+// @Description 	import the bank statements of BANK_STATEMENT_FOLDER and reconcile them with the pending payments
 // @Tags 					Crons
 // @Accept 				json
 // @Produce 			json
 // @Security 			BasicAuth
 // @Success 			200 {object} models.SuccessResponse
 // @Failure 			400 {object} models.ErrorResponse
-// @Router 				/cron/create-clients-passwd [get]
-func createPasswords(c *gin.Context) {
+// @Router 				/cron/import-bank-statements [get]
+func importBankStatements(c *gin.Context) {
+	//set variables for handling pgsql and mysql conn
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Second)
+	defer cancel()
+	db := models.ConnDb{ConnPgsql: app.PoolPgsql, Ctx: ctx}
+
+	if err := repo.ImportBankStatementsCron(db, "restApi"); err != nil {
+		c.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			models.ErrorResponse{Error: err.Error()},
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		models.SuccessResponse{Notice: ginI18n.MustGetMessage(c, "cronOK")},
+	)
+}
+
+// @Summary 			Run the task autopay_suscripciones
+// @Description 	pay with the saldo available the open factura of the suscripciones with auto-pay on its renewal day
+// @Tags 					Crons
+// @Accept 				json
+// @Produce 			json
+// @Security 			BasicAuth
+// @Success 			200 {object} models.SuccessResponse
+// @Failure 			400 {object} models.ErrorResponse
+// @Router 				/cron/autopay-suscripciones [get]
+func autopaySuscripciones(c *gin.Context) {
+	//set variables for handling pgsql and mysql conn
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Second)
+	defer cancel()
+	db := models.ConnDb{ConnPgsql: app.PoolPgsql, Ctx: ctx}
+
+	if err := repo.AutopagoSuscripcionesCron(db, "restApi"); err != nil {
+		c.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			models.ErrorResponse{Error: err.Error()},
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		models.SuccessResponse{Notice: ginI18n.MustGetMessage(c, "cronOK")},
+	)
+}
+
+// @Summary 			Run the task migrate_docid_passwd
+// @Description 	search for users with the password equal to the numbers of the docid and force them to change it before a deadline
+// @Tags 					Crons
+// @Accept 				json
+// @Produce 			json
+// @Security 			BasicAuth
+// @Success 			200 {object} models.SuccessResponse
+// @Failure 			400 {object} models.ErrorResponse
+// @Router 				/cron/migrate-docid-passwd [get]
+func migrateDocidPasswd(c *gin.Context) {
+	//set variables for handling pgsql and mysql conn
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Second)
+	defer cancel()
+	db := models.ConnDb{ConnPgsql: app.PoolPgsql, Ctx: ctx}
+
+	if err := repo.MigrateDocidPasswdCron(db, "restApi"); err != nil {
+		c.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			models.ErrorResponse{Error: err.Error()},
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		models.SuccessResponse{Notice: ginI18n.MustGetMessage(c, "cronOK")},
+	)
+}
+
+// @Summary 			Run the task rotate_jwt_keys
+// @Description 	create a new key to sign the jwt tokens when the current is old and remove the keys that are not used anymore
+// @Tags 					Crons
+// @Accept 				json
+// @Produce 			json
+// @Security 			BasicAuth
+// @Success 			200 {object} models.SuccessResponse
+// @Failure 			400 {object} models.ErrorResponse
+// @Router 				/cron/rotate-jwt-keys [get]
+func rotateJwtKeys(c *gin.Context) {
+	//set variables for handling pgsql and mysql conn
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	db := models.ConnDb{ConnPgsql: app.PoolPgsql, Ctx: ctx}
+
+	if err := repo.RotateJwtKeysCron(db, "restApi"); err != nil {
+		c.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			models.ErrorResponse{Error: err.Error()},
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		models.SuccessResponse{Notice: ginI18n.MustGetMessage(c, "cronOK")},
+	)
+}
+
+// @Summary 			Run the task purge_revoked_tokens
+// @Description 	remove the revoked access tokens that are already expired
+// @Tags 					Crons
+// @Accept 				json
+// @Produce 			json
+// @Security 			BasicAuth
+// @Success 			200 {object} models.SuccessResponse
+// @Failure 			400 {object} models.ErrorResponse
+// @Router 				/cron/purge-revoked-tokens [get]
+func purgeRevokedTokens(c *gin.Context) {
 	//set variables for handling pgsql and mysql conn
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 	db := models.ConnDb{ConnPgsql: app.PoolPgsql, Ctx: ctx}
 
-	if err := repo.CreatePasswordsCron(db, "restApi"); err != nil {
+	if err := repo.PurgeRevokedTokensCron(db, "restApi"); err != nil {
 		c.AbortWithStatusJSON(
 			http.StatusBadRequest,
 			models.ErrorResponse{Error: err.Error()},
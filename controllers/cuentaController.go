@@ -0,0 +1,149 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	ginI18n "github.com/gin-contrib/i18n"
+	"github.com/gin-gonic/gin"
+	"ired.com/micuenta/app"
+	"ired.com/micuenta/middlewares"
+	"ired.com/micuenta/models"
+	"ired.com/micuenta/repo"
+	"ired.com/micuenta/utils"
+)
+
+func CuentaRoutes(r *gin.Engine) {
+	cuenta := r.Group("/cuenta")
+	{
+		cuenta.GET("/estado", middlewares.JwtAuth, estadoCuenta)
+		cuenta.GET("/estado.csv", middlewares.JwtAuth, estadoCuentaCsv)
+		cuenta.GET("/estado.pdf", middlewares.JwtAuth, estadoCuentaPdf)
+	}
+}
+
+// bind the filters and get the estado de cuenta, on error the response is sent
+func getEstadoCuenta(c *gin.Context) (*models.EstadoCuenta, bool) {
+	// Bind and Validate the data and the struct
+	var estadoReq models.EstadoCuentaReq
+	if err := c.ShouldBind(&estadoReq); err != nil {
+		errorFormJson := models.ParseError(err, c)
+		c.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			models.ErrorResponse{Error: errorFormJson},
+		)
+		return nil, false
+	}
+
+	// set variables for handling dbs conns
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	db := models.ConnDb{ConnPgsql: app.PoolPgsql, Ctx: ctx}
+
+	userId, _ := c.Get("userId")
+	estado, errType, err := repo.EstadoCuenta(db, fmt.Sprintf("%s", userId), estadoReq)
+	if err != nil {
+		c.JSON(
+			errType,
+			models.ErrorResponse{Error: ginI18n.MustGetMessage(c, err.Error())},
+		)
+		return nil, false
+	}
+
+	return estado, true
+}
+
+// @Summary        estado de cuenta
+// @Description    movimientos (facturas, pagos procesados, retenciones y transferencias) por suscripcion con el saldo acumulado en dolares y bolivares desde el saldo inicial, por defecto los ultimos 3 meses y maximo un año
+// @Tags           Cuenta
+// @Produce        json
+// @Param          x-access-token header string true "Access Token"
+// @Param          desde query string false "fecha inicial (YYYY-MM-DD)"
+// @Param          hasta query string false "fecha final (YYYY-MM-DD)"
+// @Param          suscripcion_id query int false "id de la suscripcion"
+// @Failure 400    {object} models.ErrorResponse "Invalid Request or Incorrect Data"
+// @Failure 401    {object} models.ErrorResponse "Unauthorized"
+// @Success 			 200 {object} models.SuccessResponse{record=models.EstadoCuenta}
+// @Router         /cuenta/estado [get]
+func estadoCuenta(c *gin.Context) {
+	estado, ok := getEstadoCuenta(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		models.SuccessResponse{
+			Notice: ginI18n.MustGetMessage(c, "queryOK"),
+			Record: estado,
+		},
+	)
+}
+
+// @Summary        estado de cuenta en csv
+// @Description    mismo contenido de /cuenta/estado, una fila por movimiento y el saldo inicial de cada suscripcion
+// @Tags           Cuenta
+// @Produce        text/csv
+// @Param          x-access-token header string true "Access Token"
+// @Param          desde query string false "fecha inicial (YYYY-MM-DD)"
+// @Param          hasta query string false "fecha final (YYYY-MM-DD)"
+// @Param          suscripcion_id query int false "id de la suscripcion"
+// @Failure 400    {object} models.ErrorResponse "Invalid Request or Incorrect Data"
+// @Failure 401    {object} models.ErrorResponse "Unauthorized"
+// @Success 			 200 {file} file
+// @Router         /cuenta/estado.csv [get]
+func estadoCuentaCsv(c *gin.Context) {
+	estado, ok := getEstadoCuenta(c)
+	if !ok {
+		return
+	}
+
+	content, err := utils.EstadoCuentaCsv(*estado)
+	if err != nil {
+		utils.Logline("error generating csv of estado de cuenta", err)
+		c.JSON(
+			http.StatusInternalServerError,
+			models.ErrorResponse{Error: ginI18n.MustGetMessage(c, "errorInternal")},
+		)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="estado_cuenta_%s_%s.csv"`, estado.Desde, estado.Hasta))
+	c.Header("Cache-Control", "private, no-store")
+	c.Data(http.StatusOK, "text/csv; charset=utf-8", content)
+}
+
+// @Summary        estado de cuenta en pdf
+// @Description    mismo contenido de /cuenta/estado en un documento imprimible
+// @Tags           Cuenta
+// @Produce        application/pdf
+// @Param          x-access-token header string true "Access Token"
+// @Param          desde query string false "fecha inicial (YYYY-MM-DD)"
+// @Param          hasta query string false "fecha final (YYYY-MM-DD)"
+// @Param          suscripcion_id query int false "id de la suscripcion"
+// @Failure 400    {object} models.ErrorResponse "Invalid Request or Incorrect Data"
+// @Failure 401    {object} models.ErrorResponse "Unauthorized"
+// @Success 			 200 {file} file
+// @Router         /cuenta/estado.pdf [get]
+func estadoCuentaPdf(c *gin.Context) {
+	estado, ok := getEstadoCuenta(c)
+	if !ok {
+		return
+	}
+
+	content, err := utils.GenerateEstadoCuentaPdf(*estado)
+	if err != nil {
+		utils.Logline("error generating pdf of estado de cuenta", err)
+		c.JSON(
+			http.StatusInternalServerError,
+			models.ErrorResponse{Error: ginI18n.MustGetMessage(c, "errorInternal")},
+		)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`inline; filename="estado_cuenta_%s_%s.pdf"`, estado.Desde, estado.Hasta))
+	c.Header("Cache-Control", "private, no-store")
+	c.Data(http.StatusOK, "application/pdf", content)
+}
@@ -14,6 +14,7 @@ import (
 	"ired.com/micuenta/middlewares"
 	"ired.com/micuenta/models"
 	"ired.com/micuenta/repo"
+	"ired.com/micuenta/utils"
 )
 
 func FacturaRoutes(r *gin.Engine) {
@@ -21,6 +22,7 @@ func FacturaRoutes(r *gin.Engine) {
 	{
 		susc.GET("/list", middlewares.JwtAuth, listFacturas)
 		susc.GET("/show", middlewares.JwtAuth, showFactura)
+		susc.GET("/show.pdf", middlewares.JwtAuth, showFacturaPdf)
 	}
 }
 
@@ -79,6 +81,48 @@ func showFactura(c *gin.Context) {
 	)
 }
 
+// @Summary        factura en pdf
+// @Description    devuelve la factura en pdf, fiscal (fiscal_maquina, fiscal_talonario) o pre-factura (nota) segun su tipo
+// @Tags           Factura
+// @Produce        application/pdf
+// @Param          x-access-token header string true "Access Token"
+// @Param 				 FacturaReqId query string true "facturaId (UUID), created_at(timestamptz)"
+// @Failure 400    {object} models.ErrorResponse "Invalid Request or Incorrect Data"
+// @Failure 401    {object} models.ErrorResponse "Unauthorized"
+// @Success 			 200 {file} file
+// @Router         /factura/show.pdf [get]
+func showFacturaPdf(c *gin.Context) {
+	// Bind and Validate the data and the struct
+	var factura models.FacturaReqId
+	if err := c.ShouldBind(&factura); err != nil {
+		errorFormJson := models.ParseError(err, c)
+		c.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			models.ErrorResponse{Error: errorFormJson},
+		)
+		return
+	}
+
+	// set variables for handling dbs conns
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	db := models.ConnDb{ConnPgsql: app.PoolPgsql, Ctx: ctx}
+
+	userId, _ := c.Get("userId")
+	content, errType, err := repo.FacturaPdf(db, fmt.Sprintf("%s", userId), factura)
+	if err != nil {
+		c.JSON(
+			errType,
+			models.ErrorResponse{Error: ginI18n.MustGetMessage(c, err.Error())},
+		)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`inline; filename="factura_%s.pdf"`, utils.GenerateNcontrolByUuid(factura.Id)))
+	c.Header("Cache-Control", "private, no-store")
+	c.Data(http.StatusOK, "application/pdf", content)
+}
+
 // @Summary 			Listado de facturas
 // @Description 	Retrieve a list of facturas with pagination
 // @Tags 					Factura
@@ -0,0 +1,23 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"ired.com/micuenta/utils"
+)
+
+func JwksRoutes(r *gin.Engine) {
+	r.GET("/.well-known/jwks.json", jwksShow)
+}
+
+// @Summary        Public keys of the access tokens
+// @Description    JSON Web Key Set with the public keys to verify the access tokens signed by MiCuenta, the kid of the token header selects the key
+// @Tags           Authentication
+// @Produce        json
+// @Success 200    {object} models.JwksResponse
+// @Router         /.well-known/jwks.json [get]
+func jwksShow(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, utils.JwtKeysJwks())
+}
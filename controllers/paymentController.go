@@ -14,18 +14,31 @@ import (
 	"ired.com/micuenta/middlewares"
 	"ired.com/micuenta/models"
 	"ired.com/micuenta/repo"
+	"ired.com/micuenta/utils"
 )
 
 func PaymentRoutes(r *gin.Engine) {
 	susc := r.Group("/payment")
 	{
-		susc.POST("/send", middlewares.JwtAuth, sendPayment)
+		susc.POST("/send", middlewares.JwtAuth, middlewares.Idempotency, sendPayment)
+		susc.POST("/cancel", middlewares.JwtAuth, cancelPayment)
+		susc.POST("/amend", middlewares.JwtAuth, amendPayment)
 		susc.POST("/image-upload", middlewares.JwtAuth, imageUpload)
+		susc.GET("/file-url", middlewares.JwtAuth, paymentFileUrl)
+		susc.GET("/file", paymentFile)
 		susc.GET("/show", middlewares.JwtAuth, showPayment)
+		susc.GET("/show.pdf", middlewares.JwtAuth, showPaymentPdf)
+		susc.GET("/verify", verifyReceipt)
 		susc.GET("/list", middlewares.JwtAuth, listPayments)
 		susc.GET("/transfer/balance", middlewares.JwtAuth, balanceAvailable)
-		susc.POST("/transfer/send", middlewares.JwtAuth, sendTransfer)
+		susc.POST("/transfer/send", middlewares.JwtAuth, middlewares.Idempotency, sendTransfer)
+		susc.POST("/transfer/prepare", middlewares.JwtAuth, prepareTransfer)
+		susc.POST("/transfer/confirm", middlewares.JwtAuth, middlewares.Idempotency, confirmTransfer)
 		susc.GET("/transfer/list", middlewares.JwtAuth, listTransfers)
+		susc.GET("/transfer/received", middlewares.JwtAuth, listTransfersReceived)
+		susc.GET("/transfer/history", middlewares.JwtAuth, listTransfersHistory)
+		susc.GET("/transfer/recipient", middlewares.JwtAuth, transferRecipient)
+		susc.GET("/transfer/show.pdf", middlewares.JwtAuth, showTransferPdf)
 	}
 }
 
@@ -35,9 +48,12 @@ func PaymentRoutes(r *gin.Engine) {
 // @Accept         json
 // @Produce        json
 // @Param          x-access-token header string true "Access Token"
+// @Param          Idempotency-Key header string false "unique key of the request, the retries with the same key get the original successful response"
 // @Param 				 payment body models.PaymentReq true "Payment Data"
 // @Failure 400    {object} models.ErrorResponse "Invalid Request or Incorrect Data"
 // @Failure 401    {object} models.ErrorResponse "Unauthorized"
+// @Failure 409    {object} models.ErrorResponse "Request with the same key in progress"
+// @Failure 422    {object} models.ErrorResponse "Key used with other data"
 // @Success 			200 {object} models.SuccessResponse{record=models.PaymentResponse}
 // @Router         /payment/send [post]
 func sendPayment(c *gin.Context) {
@@ -96,14 +112,141 @@ func sendPayment(c *gin.Context) {
 	)
 }
 
-// @Summary					Upload image for payment
-// @Description			Upload an image associated with a payment ID
+// @Summary        anular un pago pendiente
+// @Description    anula el pago reportado mientras este pendiente y no haya sido tomado por administracion
+// @Tags           Payment
+// @Accept         json
+// @Produce        json
+// @Param          x-access-token header string true "Access Token"
+// @Param 				 payment body models.PaymentCancelReq true "Payment Id and motivo"
+// @Failure 400    {object} models.ErrorResponse "Invalid Request or Incorrect Data"
+// @Failure 401    {object} models.ErrorResponse "Unauthorized"
+// @Failure 409    {object} models.ErrorResponse "Payment can not be changed"
+// @Success 			200 {object} models.SuccessResponse
+// @Router         /payment/cancel [post]
+func cancelPayment(c *gin.Context) {
+	// validate if body exist
+	if c.Request.ContentLength == 0 {
+		c.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			models.ErrorResponse{Error: ginI18n.MustGetMessage(c, "errorFailedBody")},
+		)
+		return
+	}
+
+	// Bind and Validate the data and the struct
+	var cancelReq models.PaymentCancelReq
+	if err := c.ShouldBindJSON(&cancelReq); err != nil {
+		if strings.Contains(err.Error(), "invalid character") || strings.Contains(err.Error(), "unmarshal") {
+			c.AbortWithStatusJSON(
+				http.StatusBadRequest,
+				models.ErrorResponse{Error: ginI18n.MustGetMessage(c, "invalidJson")},
+			)
+			return
+		}
+
+		errorFormJson := models.ParseError(err, c)
+		c.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			models.ErrorResponse{Error: errorFormJson},
+		)
+		return
+	}
+
+	//set variables for handling pgsql conn
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	db := models.ConnDb{ConnPgsql: app.PoolPgsql, Ctx: ctx}
+
+	userId, _ := c.Get("userId")
+	errType, err := repo.CancelPayment(db, userId, cancelReq)
+	if err != nil {
+		c.JSON(
+			errType,
+			models.ErrorResponse{Error: ginI18n.MustGetMessage(c, err.Error())},
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		models.SuccessResponse{Notice: ginI18n.MustGetMessage(c, "paymentCanceled")},
+	)
+}
+
+// @Summary        corregir un pago pendiente
+// @Description    reemplaza los datos del pago reportado mientras este pendiente y no haya sido tomado por administracion, se valida igual que /payment/send
+// @Tags           Payment
+// @Accept         json
+// @Produce        json
+// @Param          x-access-token header string true "Access Token"
+// @Param 				 payment body models.PaymentAmendReq true "Payment Id and Payment Data"
+// @Failure 400    {object} models.ErrorResponse "Invalid Request or Incorrect Data"
+// @Failure 401    {object} models.ErrorResponse "Unauthorized"
+// @Failure 409    {object} models.ErrorResponse "Payment can not be changed"
+// @Success 			200 {object} models.SuccessResponse{record=models.PaymentResponse}
+// @Router         /payment/amend [post]
+func amendPayment(c *gin.Context) {
+	// validate if body exist
+	if c.Request.ContentLength == 0 {
+		c.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			models.ErrorResponse{Error: ginI18n.MustGetMessage(c, "errorFailedBody")},
+		)
+		return
+	}
+
+	// Bind and Validate the data and the struct
+	var amendReq models.PaymentAmendReq
+	if err := c.ShouldBindJSON(&amendReq); err != nil {
+		if strings.Contains(err.Error(), "invalid character") || strings.Contains(err.Error(), "unmarshal") {
+			c.AbortWithStatusJSON(
+				http.StatusBadRequest,
+				models.ErrorResponse{Error: ginI18n.MustGetMessage(c, "invalidJson")},
+			)
+			return
+		}
+
+		errorFormJson := models.ParseError(err, c)
+		c.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			models.ErrorResponse{Error: errorFormJson},
+		)
+		return
+	}
+
+	//set variables for handling pgsql conn
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	db := models.ConnDb{ConnPgsql: app.PoolPgsql, Ctx: ctx}
+
+	userId, _ := c.Get("userId")
+	paymentResponse, errType, err := repo.AmendPayment(db, userId, amendReq)
+	if err != nil {
+		c.JSON(
+			errType,
+			models.ErrorResponse{Error: ginI18n.MustGetMessage(c, err.Error())},
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		models.SuccessResponse{
+			Notice: ginI18n.MustGetMessage(c, "paymentAmended"),
+			Record: paymentResponse,
+		},
+	)
+}
+
+// @Summary					Upload files for payment
+// @Description			Upload one or more proofs (png, jpeg or pdf) associated with a payment ID, send the field image once by file
 // @Tags						Payment
 // @Accept					multipart/form-data
 // @Produce					json
 // @Param           x-access-token header string true "Access Token"
 // @Param						payment_id formData string true "paymentId (UUID), created_at(timestamptz)"
-// @Param						image	formData file true "Image file to upload"
+// @Param						image	formData file true "Image or pdf file to upload"
 // @Failure 400    {object} models.ErrorResponse "Invalid Request or Incorrect Data"
 // @Failure 401    {object} models.ErrorResponse "Unauthorized"
 // @Success 				200 {object} models.SuccessResponse
@@ -128,9 +271,9 @@ func imageUpload(c *gin.Context) {
 		return
 	}
 
-	// validate that file exist
-	file, err := c.FormFile("image")
-	if err != nil {
+	// validate that files exist
+	form, err := c.MultipartForm()
+	if err != nil || len(form.File["image"]) == 0 {
 		c.AbortWithStatusJSON(
 			http.StatusBadRequest,
 			models.ErrorResponse{Error: ginI18n.MustGetMessage(c, "veImageRequired")},
@@ -144,7 +287,7 @@ func imageUpload(c *gin.Context) {
 	db := models.ConnDb{ConnPgsql: app.PoolPgsql, Ctx: ctx}
 
 	//  process and check for errors
-	if errType, err := repo.ImageUpload(c, db, file, payment); err != nil {
+	if errType, err := repo.ImageUpload(c, db, form.File["image"], payment); err != nil {
 		c.JSON(
 			errType,
 			models.ErrorResponse{Error: ginI18n.MustGetMessage(c, err.Error())},
@@ -160,6 +303,89 @@ func imageUpload(c *gin.Context) {
 	)
 }
 
+// @Summary        urls de los comprobantes de un pago
+// @Description    devuelve las urls firmadas y de corta duracion para descargar los comprobantes del pago y sus miniaturas
+// @Tags           Payment
+// @Accept         json
+// @Produce        json
+// @Param          x-access-token header string true "Access Token"
+// @Param 				 PaymentReqId query string true "paymentId (UUID), created_at(timestamptz)"
+// @Failure 400    {object} models.ErrorResponse "Invalid Request or Incorrect Data"
+// @Failure 401    {object} models.ErrorResponse "Unauthorized"
+// @Failure 404    {object} models.ErrorResponse "File Not Found"
+// @Success 			200 {object} models.SuccessResponse{record=[]models.PaymentFile}
+// @Router         /payment/file-url [get]
+func paymentFileUrl(c *gin.Context) {
+	// Bind and Validate the data and the struct
+	var payment models.PaymentReqId
+	if err := c.ShouldBind(&payment); err != nil {
+		errorFormJson := models.ParseError(err, c)
+		c.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			models.ErrorResponse{Error: errorFormJson},
+		)
+		return
+	}
+
+	//set variables for handling pgsql conn
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	db := models.ConnDb{ConnPgsql: app.PoolPgsql, Ctx: ctx}
+
+	userId, _ := c.Get("userId")
+	fileUrl, errType, err := repo.PaymentFileUrl(db, fmt.Sprintf("%s", userId), payment)
+	if err != nil {
+		c.JSON(
+			errType,
+			models.ErrorResponse{Error: ginI18n.MustGetMessage(c, err.Error())},
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		models.SuccessResponse{
+			Notice: ginI18n.MustGetMessage(c, "queryOK"),
+			Record: fileUrl,
+		},
+	)
+}
+
+// @Summary        descargar comprobante de un pago
+// @Description    devuelve el archivo del comprobante, el token es el de la url firmada de /payment/file-url. Los archivos que no son png, jpeg o pdf se descargan como application/octet-stream
+// @Tags           Payment
+// @Produce        image/png,image/jpeg,application/pdf,application/octet-stream
+// @Param          token query string true "token of the signed url"
+// @Failure 401    {object} models.ErrorResponse "Invalid or Expired Url"
+// @Failure 404    {object} models.ErrorResponse "File Not Found"
+// @Success 			200 {file} file
+// @Router         /payment/file [get]
+func paymentFile(c *gin.Context) {
+	//set variables for handling pgsql conn
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	db := models.ConnDb{ConnPgsql: app.PoolPgsql, Ctx: ctx}
+
+	content, contentType, errType, err := repo.PaymentFile(db, c.Query("token"))
+	if err != nil {
+		c.JSON(
+			errType,
+			models.ErrorResponse{Error: ginI18n.MustGetMessage(c, err.Error())},
+		)
+		return
+	}
+
+	// the files that are not images or pdf are never shown on the browser
+	disposition := "inline"
+	if contentType == "application/octet-stream" {
+		disposition = "attachment"
+	}
+	c.Header("Cache-Control", "private, no-store")
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Header("Content-Disposition", disposition)
+	c.Data(http.StatusOK, contentType, content)
+}
+
 // @Summary        detalle de un recibo de pago
 // @Description    devuelve toda la data relacionada a un recibo de pago
 // @Tags           Payment
@@ -215,6 +441,48 @@ func showPayment(c *gin.Context) {
 	)
 }
 
+// @Summary        comprobante de pago en pdf
+// @Description    devuelve el comprobante imprimible del recibo de pago con un QR para verificarlo
+// @Tags           Payment
+// @Produce        application/pdf
+// @Param          x-access-token header string true "Access Token"
+// @Param 				 PaymentReqId query string true "paymentId (UUID), created_at(timestamptz)"
+// @Failure 400    {object} models.ErrorResponse "Invalid Request or Incorrect Data"
+// @Failure 401    {object} models.ErrorResponse "Unauthorized"
+// @Success 			200 {file} file
+// @Router         /payment/show.pdf [get]
+func showPaymentPdf(c *gin.Context) {
+	// Bind and Validate the data and the struct
+	var payment models.PaymentReqId
+	if err := c.ShouldBind(&payment); err != nil {
+		errorFormJson := models.ParseError(err, c)
+		c.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			models.ErrorResponse{Error: errorFormJson},
+		)
+		return
+	}
+
+	// set variables for handling dbs conns
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	db := models.ConnDb{ConnPgsql: app.PoolPgsql, Ctx: ctx}
+
+	userId, _ := c.Get("userId")
+	content, errType, err := repo.PaymentPdf(db, fmt.Sprintf("%s", userId), payment)
+	if err != nil {
+		c.JSON(
+			errType,
+			models.ErrorResponse{Error: ginI18n.MustGetMessage(c, err.Error())},
+		)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`inline; filename="pago_%s.pdf"`, utils.GenerateNcontrolByUuid(payment.PaymentId)))
+	c.Header("Cache-Control", "private, no-store")
+	c.Data(http.StatusOK, "application/pdf", content)
+}
+
 // @Summary 			Listado de recibos de pago
 // @Description 	Retrieve a list of recibos de pagos with pagination
 // @Tags 					Payment
@@ -277,7 +545,7 @@ func listPayments(c *gin.Context) {
 }
 
 // @Summary 			Saldo disponible para transferir en la cuenta
-// @Description 	Retrieve the balance available to transfer
+// @Description 	Retrieve the balance available to transfer and what is left of the transfer limits of the cliente
 // @Tags 					Payment
 // @Accept 				json
 // @Produce 			json
@@ -311,15 +579,20 @@ func balanceAvailable(c *gin.Context) {
 }
 
 // @Summary        endpoint para guardar formulario de transferencia
-// @Description    procesa el formulario y valida el mismo, devuelve el id de la transferencia
+// @Description    procesa el formulario y valida el mismo, devuelve el id de la transferencia. Reemplazado por transfer/prepare y transfer/confirm, desactivado salvo con TRANSFER_REQUIRE_CONFIRM=false
 // @Tags           Payment
 // @Accept         json
 // @Produce        json
 // @Param          x-access-token header string true "Access Token"
+// @Param          Idempotency-Key header string false "unique key of the request, the retries with the same key get the original successful response"
 // @Param 				 transfer body models.TransferReq true "Transfer Data"
 // @Failure 400    {object} models.ErrorResponse "Invalid Request or Incorrect Data"
 // @Failure 401    {object} models.ErrorResponse "Unauthorized"
+// @Failure 403    {object} models.ErrorResponse "Transfers must be confirmed with a code"
+// @Failure 409    {object} models.ErrorResponse "Request with the same key in progress"
+// @Failure 422    {object} models.ErrorResponse "Key used with other data or transfer limits exceeded"
 // @Success 			200 {object} models.SuccessResponse{record=models.TransferResponse}
+// @Deprecated
 // @Router         /payment/transfer/send [post]
 func sendTransfer(c *gin.Context) {
 	// validate if body exist
@@ -377,8 +650,125 @@ func sendTransfer(c *gin.Context) {
 	)
 }
 
+// @Summary        Prepara una transferencia y envia el codigo para confirmarla
+// @Description    valida el destinatario, el saldo y los limites, devuelve la cotizacion con la tasa de cambio actual y envia un codigo por email o sms, la transferencia preparada expira en TRANSFER_PREPARE_MAX_AGE segundos
+// @Tags           Payment
+// @Accept         json
+// @Produce        json
+// @Param          x-access-token header string true "Access Token"
+// @Param 				 transfer body models.TransferPrepareReq true "Transfer Data"
+// @Failure 400    {object} models.ErrorResponse "Invalid Request or Incorrect Data"
+// @Failure 401    {object} models.ErrorResponse "Unauthorized"
+// @Failure 404    {object} models.ErrorResponse "Recipient not found"
+// @Failure 422    {object} models.ErrorResponse "Transfer limits exceeded"
+// @Failure 429    {object} models.ErrorResponse "Too many requests"
+// @Success 			200 {object} models.SuccessResponse{record=models.TransferPrepared}
+// @Router         /payment/transfer/prepare [post]
+func prepareTransfer(c *gin.Context) {
+	// Bind and Validate the data and the struct
+	var prepareReq models.TransferPrepareReq
+	if err := c.ShouldBindJSON(&prepareReq); err != nil {
+		if strings.Contains(err.Error(), "invalid character") || strings.Contains(err.Error(), "unmarshal") {
+			c.AbortWithStatusJSON(
+				http.StatusBadRequest,
+				models.ErrorResponse{Error: ginI18n.MustGetMessage(c, "invalidJson")},
+			)
+			return
+		}
+
+		errorFormJson := models.ParseError(err, c)
+		c.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			models.ErrorResponse{Error: errorFormJson},
+		)
+		return
+	}
+
+	// set variables for handling dbs conns
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	db := models.ConnDb{ConnPgsql: app.PoolPgsql, Ctx: ctx}
+
+	userId, _ := c.Get("userId")
+	prepared, errType, err := repo.PrepareTransfer(c, db, fmt.Sprintf("%s", userId), prepareReq)
+	if err != nil {
+		c.JSON(
+			errType,
+			models.ErrorResponse{Error: ginI18n.MustGetMessage(c, err.Error())},
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		models.SuccessResponse{
+			Notice: ginI18n.MustGetMessage(c, "transferPrepared"),
+			Record: prepared,
+		},
+	)
+}
+
+// @Summary        Confirma una transferencia preparada con el codigo enviado
+// @Description    guarda la transferencia con la cotizacion de transfer/prepare, devuelve la transferencia realizada
+// @Tags           Payment
+// @Accept         json
+// @Produce        json
+// @Param          x-access-token header string true "Access Token"
+// @Param          Idempotency-Key header string false "unique key of the request, the retries with the same key get the original successful response"
+// @Param 				 transfer body models.TransferConfirmReq true "Confirmation Data"
+// @Failure 400    {object} models.ErrorResponse "Invalid Request or Incorrect Data"
+// @Failure 401    {object} models.ErrorResponse "Unauthorized or invalid code"
+// @Failure 409    {object} models.ErrorResponse "Request with the same key in progress"
+// @Failure 410    {object} models.ErrorResponse "Prepared transfer expired or already confirmed"
+// @Failure 422    {object} models.ErrorResponse "Key used with other data or transfer limits exceeded"
+// @Success 			200 {object} models.SuccessResponse{record=models.TransferResponse}
+// @Router         /payment/transfer/confirm [post]
+func confirmTransfer(c *gin.Context) {
+	// Bind and Validate the data and the struct
+	var confirmReq models.TransferConfirmReq
+	if err := c.ShouldBindJSON(&confirmReq); err != nil {
+		if strings.Contains(err.Error(), "invalid character") || strings.Contains(err.Error(), "unmarshal") {
+			c.AbortWithStatusJSON(
+				http.StatusBadRequest,
+				models.ErrorResponse{Error: ginI18n.MustGetMessage(c, "invalidJson")},
+			)
+			return
+		}
+
+		errorFormJson := models.ParseError(err, c)
+		c.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			models.ErrorResponse{Error: errorFormJson},
+		)
+		return
+	}
+
+	// set variables for handling dbs conns
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	db := models.ConnDb{ConnPgsql: app.PoolPgsql, Ctx: ctx}
+
+	userId, _ := c.Get("userId")
+	transferResponse, errType, err := repo.ConfirmTransfer(db, fmt.Sprintf("%s", userId), confirmReq)
+	if err != nil {
+		c.JSON(
+			errType,
+			models.ErrorResponse{Error: ginI18n.MustGetMessage(c, err.Error())},
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		models.SuccessResponse{
+			Notice: ginI18n.MustGetMessage(c, "formOK"),
+			Record: transferResponse,
+		},
+	)
+}
+
 // @Summary 			Listado de transferencias
-// @Description 	Retrieve a list of transferencias with pagination
+// @Description 	Retrieve a list of transferencias sent with pagination
 // @Tags 					Payment
 // @Accept 				json
 // @Produce 			json
@@ -390,6 +780,42 @@ func sendTransfer(c *gin.Context) {
 // @Success 			200 {object} models.SuccessResponseWithMeta{record=[]models.TransferList}
 // @Router 				/payment/transfer/list [get]
 func listTransfers(c *gin.Context) {
+	listTransfersDireccion(c, "enviadas")
+}
+
+// @Summary 			Listado de transferencias recibidas
+// @Description 	Retrieve a list of transferencias received with pagination
+// @Tags 					Payment
+// @Accept 				json
+// @Produce 			json
+// @Param         x-access-token header string true "Access Token"
+// @Param 				page query int false "Page number" default(1)
+// @Param 				limit query int false "Number of records per page" default(10)
+// @Failure 400   {object} models.ErrorResponse "Invalid Request or Incorrect Data"
+// @Failure 401   {object} models.ErrorResponse "Unauthorized"
+// @Success 			200 {object} models.SuccessResponseWithMeta{record=[]models.TransferList}
+// @Router 				/payment/transfer/received [get]
+func listTransfersReceived(c *gin.Context) {
+	listTransfersDireccion(c, "recibidas")
+}
+
+// @Summary 			Historial de transferencias
+// @Description 	Retrieve a list of transferencias sent and received with pagination, direccion is enviada or recibida
+// @Tags 					Payment
+// @Accept 				json
+// @Produce 			json
+// @Param         x-access-token header string true "Access Token"
+// @Param 				page query int false "Page number" default(1)
+// @Param 				limit query int false "Number of records per page" default(10)
+// @Failure 400   {object} models.ErrorResponse "Invalid Request or Incorrect Data"
+// @Failure 401   {object} models.ErrorResponse "Unauthorized"
+// @Success 			200 {object} models.SuccessResponseWithMeta{record=[]models.TransferList}
+// @Router 				/payment/transfer/history [get]
+func listTransfersHistory(c *gin.Context) {
+	listTransfersDireccion(c, "todas")
+}
+
+func listTransfersDireccion(c *gin.Context, direccion string) {
 	// Bind and Validate the data and the struct
 	paginatorQueryUri := models.PaginatorQueryUri{Page: json.Number("1"), Limit: json.Number("10")}
 	if err := c.ShouldBind(&paginatorQueryUri); err != nil {
@@ -419,7 +845,7 @@ func listTransfers(c *gin.Context) {
 
 	// look for data
 	userId, _ := c.Get("userId")
-	paymentsData, paginatorData, err := repo.TransferList(db, userId, paginatorQuery)
+	paymentsData, paginatorData, err := repo.TransferList(db, userId, paginatorQuery, direccion)
 	if err != nil {
 		c.AbortWithStatusJSON(
 			http.StatusBadRequest,
@@ -437,3 +863,127 @@ func listTransfers(c *gin.Context) {
 		},
 	)
 }
+
+// @Summary        comprobante de transferencia en pdf
+// @Description    devuelve el comprobante imprimible de la transferencia con un QR para verificarlo
+// @Tags           Payment
+// @Produce        application/pdf
+// @Param          x-access-token header string true "Access Token"
+// @Param 				 TransferReqId query string true "transferId (UUID), created_at(timestamptz)"
+// @Failure 400    {object} models.ErrorResponse "Invalid Request or Incorrect Data"
+// @Failure 401    {object} models.ErrorResponse "Unauthorized"
+// @Success 			200 {file} file
+// @Router         /payment/transfer/show.pdf [get]
+func showTransferPdf(c *gin.Context) {
+	// Bind and Validate the data and the struct
+	var transfer models.TransferReqId
+	if err := c.ShouldBind(&transfer); err != nil {
+		errorFormJson := models.ParseError(err, c)
+		c.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			models.ErrorResponse{Error: errorFormJson},
+		)
+		return
+	}
+
+	// set variables for handling dbs conns
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	db := models.ConnDb{ConnPgsql: app.PoolPgsql, Ctx: ctx}
+
+	userId, _ := c.Get("userId")
+	content, errType, err := repo.TransferPdf(db, fmt.Sprintf("%s", userId), transfer)
+	if err != nil {
+		c.JSON(
+			errType,
+			models.ErrorResponse{Error: ginI18n.MustGetMessage(c, err.Error())},
+		)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`inline; filename="transferencia_%s.pdf"`, utils.GenerateNcontrolByUuid(transfer.TransferId)))
+	c.Header("Cache-Control", "private, no-store")
+	c.Data(http.StatusOK, "application/pdf", content)
+}
+
+// @Summary        verificar un comprobante
+// @Description    endpoint publico del QR de los comprobantes, devuelve los datos impresos en el para validar su autenticidad
+// @Tags           Payment
+// @Produce        json
+// @Param          token query string true "token of the QR"
+// @Failure 401    {object} models.ErrorResponse "Invalid Token"
+// @Failure 404    {object} models.ErrorResponse "Receipt Not Found"
+// @Failure 429    {object} models.ErrorResponse "Too Many Requests"
+// @Success 			200 {object} models.SuccessResponse{record=models.ReceiptVerify}
+// @Router         /payment/verify [get]
+func verifyReceipt(c *gin.Context) {
+	// set variables for handling dbs conns
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	db := models.ConnDb{ConnPgsql: app.PoolPgsql, Ctx: ctx}
+
+	receipt, errType, err := repo.VerifyReceipt(c, db, c.Query("token"))
+	if err != nil {
+		c.JSON(
+			errType,
+			models.ErrorResponse{Error: ginI18n.MustGetMessage(c, err.Error())},
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		models.SuccessResponse{
+			Notice: ginI18n.MustGetMessage(c, "receiptValid"),
+			Record: receipt,
+		},
+	)
+}
+
+// @Summary        destinatario de una transferencia
+// @Description    devuelve el nombre enmascarado y las suscripciones del destinatario para confirmarlo antes de transferir
+// @Tags           Payment
+// @Produce        json
+// @Param          x-access-token header string true "Access Token"
+// @Param          destinatario_docid query string true "docid del destinatario"
+// @Failure 400    {object} models.ErrorResponse "Invalid Request or Incorrect Data"
+// @Failure 401    {object} models.ErrorResponse "Unauthorized"
+// @Failure 404    {object} models.ErrorResponse "Recipient Not Found"
+// @Failure 429    {object} models.ErrorResponse "Too Many Requests"
+// @Success 			200 {object} models.SuccessResponse{record=models.TransferRecipient}
+// @Router         /payment/transfer/recipient [get]
+func transferRecipient(c *gin.Context) {
+	// Bind and Validate the data and the struct
+	var recipientReq models.TransferRecipientReq
+	if err := c.ShouldBind(&recipientReq); err != nil {
+		errorFormJson := models.ParseError(err, c)
+		c.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			models.ErrorResponse{Error: errorFormJson},
+		)
+		return
+	}
+
+	// set variables for handling dbs conns
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	db := models.ConnDb{ConnPgsql: app.PoolPgsql, Ctx: ctx}
+
+	userId, _ := c.Get("userId")
+	recipient, errType, err := repo.TransferRecipient(c, db, fmt.Sprintf("%s", userId), recipientReq)
+	if err != nil {
+		c.JSON(
+			errType,
+			models.ErrorResponse{Error: ginI18n.MustGetMessage(c, err.Error())},
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		models.SuccessResponse{
+			Notice: ginI18n.MustGetMessage(c, "queryOK"),
+			Record: recipient,
+		},
+	)
+}
@@ -0,0 +1,190 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	ginI18n "github.com/gin-contrib/i18n"
+	"github.com/gin-gonic/gin"
+	"ired.com/micuenta/app"
+	"ired.com/micuenta/middlewares"
+	"ired.com/micuenta/models"
+	"ired.com/micuenta/repo"
+)
+
+func PerfilRoutes(r *gin.Engine) {
+	perfil := r.Group("/perfil")
+	{
+		perfil.GET("", middlewares.JwtAuth, showPerfil)
+		perfil.PATCH("", middlewares.JwtAuth, updatePerfil)
+		perfil.POST("/verificar", middlewares.JwtAuth, verifyPerfil)
+	}
+}
+
+// @Summary        perfil del cliente
+// @Description    devuelve nombre, direccion, telefonos y correos del cliente
+// @Tags           Perfil
+// @Accept         json
+// @Produce        json
+// @Param          x-access-token header string true "Access Token"
+// @Failure 400    {object} models.ErrorResponse "Invalid Request or Incorrect Data"
+// @Failure 401    {object} models.ErrorResponse "Unauthorized"
+// @Success 			200 {object} models.SuccessResponse{record=models.UserProfile}
+// @Router         /perfil [get]
+func showPerfil(c *gin.Context) {
+	// set variables for handling dbs conns
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	db := models.ConnDb{ConnPgsql: app.PoolPgsql, Ctx: ctx}
+
+	perfil, errType, err := repo.GetPerfil(c, db)
+	if err != nil {
+		c.JSON(
+			errType,
+			models.ErrorResponse{Error: ginI18n.MustGetMessage(c, err.Error())},
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		models.SuccessResponse{
+			Notice: ginI18n.MustGetMessage(c, "queryOK"),
+			Record: perfil,
+		},
+	)
+}
+
+// @Summary        actualizar perfil del cliente
+// @Description    la direccion se guarda de inmediato, un correo o telefono nuevo (y los que reemplaza) se guarda luego de validar el codigo enviado en /perfil/verificar, los retirados son notificados
+// @Tags           Perfil
+// @Accept         json
+// @Produce        json
+// @Param          x-access-token header string true "Access Token"
+// @Param          perfil body models.PerfilUpdateReq true "fields to change"
+// @Failure 400    {object} models.ErrorResponse "Invalid Request or Incorrect Data"
+// @Failure 401    {object} models.ErrorResponse "Unauthorized"
+// @Failure 429    {object} models.ErrorResponse "Too Many Requests"
+// @Success 			200 {object} models.SuccessResponse{record=models.PerfilResponse}
+// @Router         /perfil [patch]
+func updatePerfil(c *gin.Context) {
+	// validate if body exist
+	if c.Request.ContentLength == 0 {
+		c.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			models.ErrorResponse{Error: ginI18n.MustGetMessage(c, "errorFailedBody")},
+		)
+		return
+	}
+
+	// Bind and Validate the data and the struct
+	var perfilReq models.PerfilUpdateReq
+	if err := c.ShouldBindJSON(&perfilReq); err != nil {
+		if strings.Contains(err.Error(), "invalid character") || strings.Contains(err.Error(), "unmarshal") {
+			c.AbortWithStatusJSON(
+				http.StatusBadRequest,
+				models.ErrorResponse{Error: ginI18n.MustGetMessage(c, "invalidJson")},
+			)
+			return
+		}
+
+		errorFormJson := models.ParseError(err, c)
+		c.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			models.ErrorResponse{Error: errorFormJson},
+		)
+		return
+	}
+
+	// set variables for handling dbs conns
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	db := models.ConnDb{ConnPgsql: app.PoolPgsql, Ctx: ctx}
+
+	perfil, errType, err := repo.UpdatePerfil(c, db, perfilReq)
+	if err != nil {
+		c.JSON(
+			errType,
+			models.ErrorResponse{Error: ginI18n.MustGetMessage(c, err.Error())},
+		)
+		return
+	}
+
+	notice := "perfilUpdated"
+	if len(perfil.Pendientes) > 0 {
+		notice = "perfilPendingVerification"
+	}
+
+	c.JSON(
+		http.StatusOK,
+		models.SuccessResponse{
+			Notice: ginI18n.MustGetMessage(c, notice),
+			Record: perfil,
+		},
+	)
+}
+
+// @Summary        verificar cambio de correo o telefono
+// @Description    valida el codigo enviado al nuevo correo o telefono y lo agrega a los actuales en lugar de los que reemplaza
+// @Tags           Perfil
+// @Accept         json
+// @Produce        json
+// @Param          x-access-token header string true "Access Token"
+// @Param          perfil body models.PerfilVerifyReq true "campo and code received"
+// @Failure 400    {object} models.ErrorResponse "Invalid Request or Incorrect Data"
+// @Failure 401    {object} models.ErrorResponse "Unauthorized or Invalid Code"
+// @Success 			200 {object} models.SuccessResponse{record=models.UserProfile}
+// @Router         /perfil/verificar [post]
+func verifyPerfil(c *gin.Context) {
+	// validate if body exist
+	if c.Request.ContentLength == 0 {
+		c.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			models.ErrorResponse{Error: ginI18n.MustGetMessage(c, "errorFailedBody")},
+		)
+		return
+	}
+
+	// Bind and Validate the data and the struct
+	var verifyReq models.PerfilVerifyReq
+	if err := c.ShouldBindJSON(&verifyReq); err != nil {
+		if strings.Contains(err.Error(), "invalid character") || strings.Contains(err.Error(), "unmarshal") {
+			c.AbortWithStatusJSON(
+				http.StatusBadRequest,
+				models.ErrorResponse{Error: ginI18n.MustGetMessage(c, "invalidJson")},
+			)
+			return
+		}
+
+		errorFormJson := models.ParseError(err, c)
+		c.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			models.ErrorResponse{Error: errorFormJson},
+		)
+		return
+	}
+
+	// set variables for handling dbs conns
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	db := models.ConnDb{ConnPgsql: app.PoolPgsql, Ctx: ctx}
+
+	perfil, errType, err := repo.VerifyPerfil(c, db, verifyReq)
+	if err != nil {
+		c.JSON(
+			errType,
+			models.ErrorResponse{Error: ginI18n.MustGetMessage(c, err.Error())},
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		models.SuccessResponse{
+			Notice: ginI18n.MustGetMessage(c, "perfilUpdated"),
+			Record: perfil,
+		},
+	)
+}
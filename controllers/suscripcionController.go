@@ -2,7 +2,9 @@ package controllers
 
 import (
 	"context"
+	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	ginI18n "github.com/gin-contrib/i18n"
@@ -17,6 +19,8 @@ func SuscripcionRoutes(r *gin.Engine) {
 	susc := r.Group("/suscripcion")
 	{
 		susc.GET("/list", middlewares.JwtAuth, suscList)
+		susc.GET("/show", middlewares.JwtAuth, suscShow)
+		susc.POST("/autopay", middlewares.JwtAuth, suscAutopay)
 	}
 }
 
@@ -53,3 +57,105 @@ func suscList(c *gin.Context) {
 		},
 	)
 }
+
+// @Summary        Suscripcion Show
+// @Description    Shows a suscripcion of the logged user: plan, speed, zone, gps of the station, install date and current status (the status changes are not registered), the billing calendar (next renewal date and the projection of the next 6 months with the exchange rate of today), the saldo processed and pending confirmation, and its latest facturas, payments and retenciones
+// @Tags           Suscripcion
+// @Accept         json
+// @Produce        json
+// @Param          x-access-token header string true "Access Token"
+// @Param          suscripcion_id query string true "Suscripcion Id"
+// @Failure 400    {object} models.ErrorResponse "Invalid Request or Incorrect Data"
+// @Failure 401    {object} models.ErrorResponse "Unauthorized"
+// @Success 			200 {object} models.SuccessResponse{record=models.SuscripcionDetalle}
+// @Router         /suscripcion/show [get]
+func suscShow(c *gin.Context) {
+	// Bind and Validate the data and the struct
+	var suscripcionReq models.SuscripcionReqId
+	if err := c.ShouldBind(&suscripcionReq); err != nil {
+		errorFormJson := models.ParseError(err, c)
+		c.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			models.ErrorResponse{Error: errorFormJson},
+		)
+		return
+	}
+
+	// set variables for handling dbs conns
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	db := models.ConnDb{ConnPgsql: app.PoolPgsql, Ctx: ctx}
+
+	userId, _ := c.Get("userId")
+	suscripcion, errType, err := repo.SuscripcionShow(db, fmt.Sprintf("%s", userId), suscripcionReq)
+	if err != nil {
+		c.JSON(
+			errType,
+			models.ErrorResponse{Error: ginI18n.MustGetMessage(c, err.Error())},
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		models.SuccessResponse{
+			Notice: ginI18n.MustGetMessage(c, "queryOK"),
+			Record: suscripcion,
+		},
+	)
+}
+
+// @Summary        Activa o desactiva el pago automatico de una suscripcion
+// @Description    en el dia de renovacion se paga la factura pendiente de la suscripcion con el saldo disponible, no aplica para suscripciones con excluir_pago
+// @Tags           Suscripcion
+// @Accept         json
+// @Produce        json
+// @Param          x-access-token header string true "Access Token"
+// @Param 				 autopago body models.SuscripcionAutopagoReq true "Autopay Data"
+// @Failure 400    {object} models.ErrorResponse "Invalid Request or Incorrect Data"
+// @Failure 401    {object} models.ErrorResponse "Unauthorized"
+// @Success 			200 {object} models.SuccessResponse{record=models.Suscripcion}
+// @Router         /suscripcion/autopay [post]
+func suscAutopay(c *gin.Context) {
+	// Bind and Validate the data and the struct
+	var autopagoReq models.SuscripcionAutopagoReq
+	if err := c.ShouldBindJSON(&autopagoReq); err != nil {
+		if strings.Contains(err.Error(), "invalid character") || strings.Contains(err.Error(), "unmarshal") {
+			c.AbortWithStatusJSON(
+				http.StatusBadRequest,
+				models.ErrorResponse{Error: ginI18n.MustGetMessage(c, "invalidJson")},
+			)
+			return
+		}
+
+		errorFormJson := models.ParseError(err, c)
+		c.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			models.ErrorResponse{Error: errorFormJson},
+		)
+		return
+	}
+
+	// set variables for handling dbs conns
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	db := models.ConnDb{ConnPgsql: app.PoolPgsql, Ctx: ctx}
+
+	userId, _ := c.Get("userId")
+	suscripcion, errType, err := repo.SetAutopago(db, fmt.Sprintf("%s", userId), autopagoReq)
+	if err != nil {
+		c.JSON(
+			errType,
+			models.ErrorResponse{Error: ginI18n.MustGetMessage(c, err.Error())},
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		models.SuccessResponse{
+			Notice: ginI18n.MustGetMessage(c, "autopayUpdated"),
+			Record: suscripcion,
+		},
+	)
+}
@@ -19,46 +19,81 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/auth/change-password": {
+        "/.well-known/jwks.json": {
+            "get": {
+                "description": "JSON Web Key Set with the public keys to verify the access tokens signed by MiCuenta, the kid of the token header selects the key",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "Public keys of the access tokens",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.JwksResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/banco/import": {
             "post": {
-                "description": "Updates the user's password after validating the request body",
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Imports a csv bank statement with the layout given and reconciles the lines with the pending payments, the exact matches are marked as conciliated for back office and the lines of overlapping statements are skipped",
                 "consumes": [
-                    "application/json"
+                    "multipart/form-data"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Authentication"
+                    "Admin"
                 ],
-                "summary": "Change user password",
+                "summary": "Import bank statement",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Access Token",
-                        "name": "x-access-token",
-                        "in": "header",
+                        "description": "name of the layout on BANK_LAYOUTS_FILE",
+                        "name": "layout",
+                        "in": "formData",
                         "required": true
                     },
                     {
-                        "description": "Password Change Request",
-                        "name": "user",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.UserChangePassword"
-                        }
+                        "type": "file",
+                        "description": "csv statement",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.SuccessResponse"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "record": {
+                                            "$ref": "#/definitions/models.BancoImportResult"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "400": {
-                        "description": "Invalid Request or Incorrect Data",
+                        "description": "Invalid Request",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -68,13 +103,24 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
+                    },
+                    "409": {
+                        "description": "Statement already imported",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/auth/forgot-password": {
-            "post": {
-                "description": "Sends a password reset email to the user after validating the request",
+        "/admin/banco/revision": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Lines of the imported statements that could not be reconciled automatically, with the candidate payments",
                 "consumes": [
                     "application/json"
                 ],
@@ -82,25 +128,45 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Authentication"
+                    "Admin"
                 ],
-                "summary": "Request password reset",
+                "summary": "Bank lines to review",
                 "parameters": [
                     {
-                        "description": "User email for password reset",
-                        "name": "user",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.ForgotPasswordRequest"
-                        }
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Number of records per page",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.SuccessResponse"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.SuccessResponseWithMeta"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "record": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.BancoRevision"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "400": {
@@ -109,8 +175,8 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "User Not Found",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -118,9 +184,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/auth/login": {
-            "post": {
-                "description": "Authenticates a user by validating credentials and returning a session token",
+        "/admin/suscripcion/show": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Same view of /suscripcion/show for any suscripcion: plan, install date, current status, saldo, facturas, payments and retenciones",
                 "consumes": [
                     "application/json"
                 ],
@@ -128,24 +199,16 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Authentication"
+                    "Admin"
                 ],
-                "summary": "Authenticate a user",
+                "summary": "Suscripcion detail for support",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Access Token",
-                        "name": "x-access-token",
-                        "in": "header"
-                    },
-                    {
-                        "description": "User Login Data",
-                        "name": "credentials",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.UserRequest"
-                        }
+                        "description": "Suscripcion Id",
+                        "name": "suscripcion_id",
+                        "in": "query",
+                        "required": true
                     }
                 ],
                 "responses": {
@@ -160,7 +223,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "record": {
-                                            "$ref": "#/definitions/models.UserResponse"
+                                            "$ref": "#/definitions/models.SuscripcionDetalle"
                                         }
                                     }
                                 }
@@ -168,7 +231,7 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "Invalid Request or Already Logged In",
+                        "description": "Invalid Request",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -178,13 +241,24 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
+                    },
+                    "404": {
+                        "description": "Suscripcion not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/auth/logout": {
-            "get": {
-                "description": "Logs out the authenticated user by clearing session data",
+        "/admin/tokens/revoke": {
+            "post": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Revokes one access token by its jti, or all the tokens and sessions of the cliente when jti is empty",
                 "consumes": [
                     "application/json"
                 ],
@@ -192,16 +266,18 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Authentication"
+                    "Admin"
                 ],
-                "summary": "Logout a user",
+                "summary": "Revoke access tokens",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Access Token",
-                        "name": "x-access-token",
-                        "in": "header",
-                        "required": true
+                        "description": "Cliente and optional jti",
+                        "name": "revoke",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.AdminRevokeReq"
+                        }
                     }
                 ],
                 "responses": {
@@ -210,13 +286,25 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/models.SuccessResponse"
                         }
+                    },
+                    "400": {
+                        "description": "Invalid Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/auth/refresh": {
+        "/auth/2fa/activate": {
             "post": {
-                "description": "Refreshes the user's session token if valid and returns a new one",
+                "description": "Confirms the enrollment with a code of the authenticator app",
                 "consumes": [
                     "application/json"
                 ],
@@ -226,7 +314,7 @@ const docTemplate = `{
                 "tags": [
                     "Authentication"
                 ],
-                "summary": "Refresh authentication token",
+                "summary": "Activate two-factor authentication",
                 "parameters": [
                     {
                         "type": "string",
@@ -234,35 +322,38 @@ const docTemplate = `{
                         "name": "x-access-token",
                         "in": "header",
                         "required": true
+                    },
+                    {
+                        "description": "TOTP code",
+                        "name": "code",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.TwoFactorCodeReq"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/models.SuccessResponse"
-                                },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "record": {
-                                            "$ref": "#/definitions/models.UserToken"
-                                        }
-                                    }
-                                }
-                            ]
+                            "$ref": "#/definitions/models.SuccessResponse"
                         }
                     },
                     "400": {
-                        "description": "Bad Request",
+                        "description": "Invalid Request or Incorrect Data",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
                     "401": {
-                        "description": "Unauthorized",
+                        "description": "Unauthorized or Invalid Code",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Invalid Codes",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -270,9 +361,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/auth/reset-password": {
+        "/auth/2fa/disable": {
             "post": {
-                "description": "Processes a password reset request and sends recovery instructions to the user",
+                "description": "Removes the second factor, requires the password and a TOTP or recovery code",
                 "consumes": [
                     "application/json"
                 ],
@@ -282,33 +373,46 @@ const docTemplate = `{
                 "tags": [
                     "Authentication"
                 ],
-                "summary": "Send password reset instructions",
+                "summary": "Disable two-factor authentication",
                 "parameters": [
                     {
-                        "description": "User email for password reset",
+                        "type": "string",
+                        "description": "Access Token",
+                        "name": "x-access-token",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Password and code",
                         "name": "user",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.ForgotPasswordSend"
+                            "$ref": "#/definitions/models.TwoFactorDisableReq"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Password reset instructions sent",
+                        "description": "OK",
                         "schema": {
                             "$ref": "#/definitions/models.SuccessResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid Request",
+                        "description": "Invalid Request or Incorrect Data",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "User Not Found",
+                    "401": {
+                        "description": "Unauthorized or Invalid Code",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Invalid Codes",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -316,9 +420,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/banco/formas-de-pago": {
-            "get": {
-                "description": "devuelve un listado de las formas de pago disponibles para el cliente",
+        "/auth/2fa/enroll": {
+            "post": {
+                "description": "Generates a TOTP secret, its otpauth uri and recovery codes, it must be activated with a valid code",
                 "consumes": [
                     "application/json"
                 ],
@@ -326,9 +430,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Banco"
+                    "Authentication"
                 ],
-                "summary": "listado formas de pago",
+                "summary": "Enroll two-factor authentication",
                 "parameters": [
                     {
                         "type": "string",
@@ -350,7 +454,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "record": {
-                                            "$ref": "#/definitions/models.CuentasBanco"
+                                            "$ref": "#/definitions/models.TwoFactorEnrollResponse"
                                         }
                                     }
                                 }
@@ -358,7 +462,7 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "Invalid Request or Incorrect Data",
+                        "description": "Invalid Request or Already Enabled",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -372,14 +476,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/cron/clean-old-sessions": {
-            "get": {
-                "security": [
-                    {
-                        "BasicAuth": []
-                    }
-                ],
-                "description": "cleaning old session of clientes from postgresDB",
+        "/auth/2fa/verify": {
+            "post": {
+                "description": "Finishes the login of a user with two-factor enabled using the pre-auth token and a TOTP or recovery code",
                 "consumes": [
                     "application/json"
                 ],
@@ -387,18 +486,53 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Crons"
+                    "Authentication"
                 ],
-                "summary": "Run the task sinc_users",
-                "responses": {
-                    "200": {
-                        "description": "OK",
+                "summary": "Verify the second factor",
+                "parameters": [
+                    {
+                        "description": "Pre-auth token and code",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.SuccessResponse"
+                            "$ref": "#/definitions/models.TwoFactorVerifyReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "record": {
+                                            "$ref": "#/definitions/models.UserResponse"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "400": {
-                        "description": "Bad Request",
+                        "description": "Invalid Request or Already Logged In",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized or Invalid Code",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Invalid Codes",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -406,14 +540,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/cron/create-clients-passwd": {
-            "get": {
-                "security": [
-                    {
-                        "BasicAuth": []
-                    }
-                ],
-                "description": "search for all users with password null and set the password the same as the docid",
+        "/auth/activation-req": {
+            "post": {
+                "description": "Sends a one-time code to the email of an account without password, the response is the same for any docid",
                 "consumes": [
                     "application/json"
                 ],
@@ -421,9 +550,20 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Crons"
+                    "Authentication"
+                ],
+                "summary": "Request activation code",
+                "parameters": [
+                    {
+                        "description": "Docid of the account",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ActivationRequest"
+                        }
+                    }
                 ],
-                "summary": "Run the task create_client_passwd",
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -432,7 +572,7 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "Bad Request",
+                        "description": "Invalid Request",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -440,14 +580,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/cron/sinc-factura-fiscal": {
-            "get": {
-                "security": [
-                    {
-                        "BasicAuth": []
-                    }
-                ],
-                "description": "busca registros nuevos en la bd de mysql y sincroniza la data a postgres",
+        "/auth/activation-send": {
+            "post": {
+                "description": "Validates the activation code and sets the password of the account",
                 "consumes": [
                     "application/json"
                 ],
@@ -455,9 +590,20 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Crons"
+                    "Authentication"
+                ],
+                "summary": "Activate account",
+                "parameters": [
+                    {
+                        "description": "Docid, code and new password",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ActivationSend"
+                        }
+                    }
                 ],
-                "summary": "Run the task sinc_factura_fiscal",
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -466,7 +612,13 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "Bad Request",
+                        "description": "Invalid Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid Code",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -474,14 +626,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/cron/sinc-prefactura-anulada": {
-            "get": {
-                "security": [
-                    {
-                        "BasicAuth": []
-                    }
-                ],
-                "description": "busca registros nuevos en la bd de mysql y sincroniza la data a postgres",
+        "/auth/change-password": {
+            "post": {
+                "description": "Updates the user's password after validating the request body",
                 "consumes": [
                     "application/json"
                 ],
@@ -489,9 +636,27 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Crons"
+                    "Authentication"
+                ],
+                "summary": "Change user password",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Access Token",
+                        "name": "x-access-token",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Password Change Request",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UserChangePassword"
+                        }
+                    }
                 ],
-                "summary": "Run the task sinc_prefactura_anuladas",
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -500,7 +665,13 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "Bad Request",
+                        "description": "Invalid Request or Incorrect Data",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -508,14 +679,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/cron/sinc-prefactura-pagadas": {
-            "get": {
-                "security": [
-                    {
-                        "BasicAuth": []
-                    }
-                ],
-                "description": "busca registros nuevos en la bd de mysql y sincroniza la data a postgres",
+        "/auth/forgot-password": {
+            "post": {
+                "description": "Sends a password reset email to the user after validating the request, the response is the same for any docid",
                 "consumes": [
                     "application/json"
                 ],
@@ -523,9 +689,20 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Crons"
+                    "Authentication"
+                ],
+                "summary": "Request password reset",
+                "parameters": [
+                    {
+                        "description": "User email for password reset",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ForgotPasswordRequest"
+                        }
+                    }
                 ],
-                "summary": "Run the task sinc_prefactura_pagadas",
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -534,7 +711,13 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "Bad Request",
+                        "description": "Invalid Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -542,14 +725,55 @@ const docTemplate = `{
                 }
             }
         },
-        "/cron/sinc-recibov-anulado": {
-            "get": {
-                "security": [
+        "/auth/forgot-password-sms-req": {
+            "post": {
+                "description": "Sends a one-time code to the mobile phone of the account, the response is the same for any docid",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "Request password reset code by sms",
+                "parameters": [
                     {
-                        "BasicAuth": []
+                        "description": "Docid of the account",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ForgotPasswordRequest"
+                        }
                     }
                 ],
-                "description": "busca registros nuevos en la bd de mysql y sincroniza la data a postgres",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/forgot-password-sms-verify": {
+            "post": {
+                "description": "Validates the sms code and returns the token to use on /auth/forgot-password-send",
                 "consumes": [
                     "application/json"
                 ],
@@ -557,18 +781,1984 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Crons"
+                    "Authentication"
+                ],
+                "summary": "Verify password reset code sent by sms",
+                "parameters": [
+                    {
+                        "description": "Docid and code received",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ForgotPasswordSmsVerify"
+                        }
+                    }
                 ],
-                "summary": "Run the task sinc_recibov_anulado",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.SuccessResponse"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "record": {
+                                            "$ref": "#/definitions/models.ResetTokenResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid Code",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Attempts",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/login": {
+            "post": {
+                "description": "Authenticates a user by validating credentials and returning a session token",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "Authenticate a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Access Token",
+                        "name": "x-access-token",
+                        "in": "header"
+                    },
+                    {
+                        "description": "User Login Data",
+                        "name": "credentials",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UserRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Logged in, or models.TwoFactorChallenge when second factor is required",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "record": {
+                                            "$ref": "#/definitions/models.UserResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid Request or Already Logged In",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Migrated Password Expired, Account Must Be Activated",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Failed Attempts or Account Locked",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/logout": {
+            "get": {
+                "description": "Logs out the authenticated user by clearing session data",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "Logout a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Access Token",
+                        "name": "x-access-token",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/refresh": {
+            "post": {
+                "description": "Refreshes the user's session token if valid and returns a new one",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "Refresh authentication token",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Access Token",
+                        "name": "x-access-token",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "record": {
+                                            "$ref": "#/definitions/models.UserToken"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/reset-password": {
+            "post": {
+                "description": "Processes a password reset request and sends recovery instructions to the user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "Send password reset instructions",
+                "parameters": [
+                    {
+                        "description": "User email for password reset",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ForgotPasswordSend"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Password reset instructions sent",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/sessions": {
+            "get": {
+                "description": "Shows the active sessions (devices) of the logged user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "List active sessions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Access Token",
+                        "name": "x-access-token",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "record": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.SessionList"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid Request or Incorrect Data",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/sessions/revoke": {
+            "post": {
+                "description": "Closes one of the active sessions of the logged user, e.g. a lost device",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "Revoke a session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Access Token",
+                        "name": "x-access-token",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Session to revoke",
+                        "name": "session",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.SessionReqId"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid Request or Incorrect Data",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/sessions/revoke-others": {
+            "post": {
+                "description": "Closes every active session of the logged user except the one making the request",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "Revoke all other sessions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Access Token",
+                        "name": "x-access-token",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/banco/formas-de-pago": {
+            "get": {
+                "description": "devuelve un listado de las formas de pago disponibles para el cliente",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Banco"
+                ],
+                "summary": "listado formas de pago",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Access Token",
+                        "name": "x-access-token",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "record": {
+                                            "$ref": "#/definitions/models.CuentasBanco"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid Request or Incorrect Data",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/cron/autopay-suscripciones": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "pay with the saldo available the open factura of the suscripciones with auto-pay on its renewal day",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Crons"
+                ],
+                "summary": "Run the task autopay_suscripciones",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/cron/clean-old-sessions": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "cleaning old session of clientes from postgresDB",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Crons"
+                ],
+                "summary": "Run the task sinc_users",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/cron/import-bank-statements": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "import the bank statements of BANK_STATEMENT_FOLDER and reconcile them with the pending payments",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Crons"
+                ],
+                "summary": "Run the task import_bank_statements",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/cron/migrate-docid-passwd": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "search for users with the password equal to the numbers of the docid and force them to change it before a deadline",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Crons"
+                ],
+                "summary": "Run the task migrate_docid_passwd",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/cron/purge-revoked-tokens": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "remove the revoked access tokens that are already expired",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Crons"
+                ],
+                "summary": "Run the task purge_revoked_tokens",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/cron/rotate-jwt-keys": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "create a new key to sign the jwt tokens when the current is old and remove the keys that are not used anymore",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Crons"
+                ],
+                "summary": "Run the task rotate_jwt_keys",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/cron/sinc-factura-fiscal": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "busca registros nuevos en la bd de mysql y sincroniza la data a postgres",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Crons"
+                ],
+                "summary": "Run the task sinc_factura_fiscal",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/cron/sinc-prefactura-anulada": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "busca registros nuevos en la bd de mysql y sincroniza la data a postgres",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Crons"
+                ],
+                "summary": "Run the task sinc_prefactura_anuladas",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/cron/sinc-prefactura-pagadas": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "busca registros nuevos en la bd de mysql y sincroniza la data a postgres",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Crons"
+                ],
+                "summary": "Run the task sinc_prefactura_pagadas",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/cron/sinc-recibov-anulado": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "busca registros nuevos en la bd de mysql y sincroniza la data a postgres",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Crons"
+                ],
+                "summary": "Run the task sinc_recibov_anulado",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/cron/sinc-recibov-procesado": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "busca registros nuevos en la bd de mysql y sincroniza la data a postgres",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Crons"
+                ],
+                "summary": "Run the task sinc_recibov_procesado",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/cron/sinc-retencion": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "busca registros nuevos en la bd de mysql y sincroniza la data a postgres",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Crons"
+                ],
+                "summary": "Run the task sinc_retenciones",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/cron/sinc-tasa-cambio": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "busca registros nuevos en la bd de mysql y sincroniza la data a postgres",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Crons"
+                ],
+                "summary": "Run the task sinc_tasa_cambio",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/cuenta/estado": {
+            "get": {
+                "description": "movimientos (facturas, pagos procesados, retenciones y transferencias) por suscripcion con el saldo acumulado en dolares y bolivares desde el saldo inicial, por defecto los ultimos 3 meses y maximo un año",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Cuenta"
+                ],
+                "summary": "estado de cuenta",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Access Token",
+                        "name": "x-access-token",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "fecha inicial (YYYY-MM-DD)",
+                        "name": "desde",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "fecha final (YYYY-MM-DD)",
+                        "name": "hasta",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "id de la suscripcion",
+                        "name": "suscripcion_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "record": {
+                                            "$ref": "#/definitions/models.EstadoCuenta"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid Request or Incorrect Data",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/cuenta/estado.csv": {
+            "get": {
+                "description": "mismo contenido de /cuenta/estado, una fila por movimiento y el saldo inicial de cada suscripcion",
+                "produces": [
+                    "text/csv"
+                ],
+                "tags": [
+                    "Cuenta"
+                ],
+                "summary": "estado de cuenta en csv",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Access Token",
+                        "name": "x-access-token",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "fecha inicial (YYYY-MM-DD)",
+                        "name": "desde",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "fecha final (YYYY-MM-DD)",
+                        "name": "hasta",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "id de la suscripcion",
+                        "name": "suscripcion_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid Request or Incorrect Data",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/cuenta/estado.pdf": {
+            "get": {
+                "description": "mismo contenido de /cuenta/estado en un documento imprimible",
+                "produces": [
+                    "application/pdf"
+                ],
+                "tags": [
+                    "Cuenta"
+                ],
+                "summary": "estado de cuenta en pdf",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Access Token",
+                        "name": "x-access-token",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "fecha inicial (YYYY-MM-DD)",
+                        "name": "desde",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "fecha final (YYYY-MM-DD)",
+                        "name": "hasta",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "id de la suscripcion",
+                        "name": "suscripcion_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid Request or Incorrect Data",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/factura/list": {
+            "get": {
+                "description": "Retrieve a list of facturas with pagination",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Factura"
+                ],
+                "summary": "Listado de facturas",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Access Token",
+                        "name": "x-access-token",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Number of recibos de pago per page",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.SuccessResponseWithMeta"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "record": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.FacturaList"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid Request or Incorrect Data",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/factura/show": {
+            "get": {
+                "description": "devuelve toda la data relacionada a una factura",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Factura"
+                ],
+                "summary": "detalle de una factura",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Access Token",
+                        "name": "x-access-token",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "retencionId (UUID), created_at(timestamptz)",
+                        "name": "FacturaReqId",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "record": {
+                                            "$ref": "#/definitions/models.FacturaResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid Request or Incorrect Data",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/factura/show.pdf": {
+            "get": {
+                "description": "devuelve la factura en pdf, fiscal (fiscal_maquina, fiscal_talonario) o pre-factura (nota) segun su tipo",
+                "produces": [
+                    "application/pdf"
+                ],
+                "tags": [
+                    "Factura"
+                ],
+                "summary": "factura en pdf",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Access Token",
+                        "name": "x-access-token",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "facturaId (UUID), created_at(timestamptz)",
+                        "name": "FacturaReqId",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid Request or Incorrect Data",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/info/accesibilidad": {
+            "get": {
+                "description": "Retrieve Texto con accesibilidad",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Info"
+                ],
+                "summary": "Texto con accesibilidad para la app movil",
+                "parameters": [
+                    {
+                        "enum": [
+                            "movil",
+                            "web"
+                        ],
+                        "type": "string",
+                        "description": "Tipo de plataforma",
+                        "name": "tipo",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid Request or Incorrect Data",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/info/faqs": {
+            "get": {
+                "description": "Retrieve listado de preguntas frecuentes",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Info"
+                ],
+                "summary": "listado de preguntas frecuentes",
+                "parameters": [
+                    {
+                        "enum": [
+                            "movil",
+                            "web"
+                        ],
+                        "type": "string",
+                        "description": "Tipo de plataforma",
+                        "name": "tipo",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "record": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.InfoFaq"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid Request or Incorrect Data",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/info/legal/privacy_policy": {
+            "get": {
+                "description": "Retrieve Texto con politicas de privacidad",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Info"
+                ],
+                "summary": "Texto con politicas de privacidad",
+                "parameters": [
+                    {
+                        "enum": [
+                            "movil",
+                            "web"
+                        ],
+                        "type": "string",
+                        "description": "Tipo de plataforma",
+                        "name": "tipo",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid Request or Incorrect Data",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/info/legal/terminos_y_condiciones": {
+            "get": {
+                "description": "Retrieve Texto con los terminos y condiciones",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Info"
+                ],
+                "summary": "Texto con los terminos y condiciones",
+                "parameters": [
+                    {
+                        "enum": [
+                            "movil",
+                            "web"
+                        ],
+                        "type": "string",
+                        "description": "Tipo de plataforma",
+                        "name": "tipo",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid Request or Incorrect Data",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/info/oficinas": {
+            "get": {
+                "description": "Retrieve a list of oficinas de la empresa con su ubicacion",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Info"
+                ],
+                "summary": "Listado de oficinas",
+                "parameters": [
+                    {
+                        "enum": [
+                            "movil",
+                            "web"
+                        ],
+                        "type": "string",
+                        "description": "Tipo de plataforma",
+                        "name": "tipo",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "record": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.InfoLocation"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid Request or Incorrect Data",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/payment/amend": {
+            "post": {
+                "description": "reemplaza los datos del pago reportado mientras este pendiente y no haya sido tomado por administracion, se valida igual que /payment/send",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Payment"
+                ],
+                "summary": "corregir un pago pendiente",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Access Token",
+                        "name": "x-access-token",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Payment Id and Payment Data",
+                        "name": "payment",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.PaymentAmendReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "record": {
+                                            "$ref": "#/definitions/models.PaymentResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid Request or Incorrect Data",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Payment can not be changed",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/payment/cancel": {
+            "post": {
+                "description": "anula el pago reportado mientras este pendiente y no haya sido tomado por administracion",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Payment"
+                ],
+                "summary": "anular un pago pendiente",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Access Token",
+                        "name": "x-access-token",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Payment Id and motivo",
+                        "name": "payment",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.PaymentCancelReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid Request or Incorrect Data",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Payment can not be changed",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/payment/file": {
+            "get": {
+                "description": "devuelve el archivo del comprobante, el token es el de la url firmada de /payment/file-url. Los archivos que no son png, jpeg o pdf se descargan como application/octet-stream",
+                "produces": [
+                    "image/png",
+                    "image/jpeg",
+                    "application/pdf",
+                    "application/octet-stream"
+                ],
+                "tags": [
+                    "Payment"
+                ],
+                "summary": "descargar comprobante de un pago",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "token of the signed url",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid or Expired Url",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "File Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/payment/file-url": {
+            "get": {
+                "description": "devuelve las urls firmadas y de corta duracion para descargar los comprobantes del pago y sus miniaturas",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Payment"
+                ],
+                "summary": "urls de los comprobantes de un pago",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Access Token",
+                        "name": "x-access-token",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "paymentId (UUID), created_at(timestamptz)",
+                        "name": "PaymentReqId",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "record": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.PaymentFile"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid Request or Incorrect Data",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "File Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/payment/image-upload": {
+            "post": {
+                "description": "Upload one or more proofs (png, jpeg or pdf) associated with a payment ID, send the field image once by file",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Payment"
+                ],
+                "summary": "Upload files for payment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Access Token",
+                        "name": "x-access-token",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "paymentId (UUID), created_at(timestamptz)",
+                        "name": "payment_id",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "Image or pdf file to upload",
+                        "name": "image",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid Request or Incorrect Data",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/payment/list": {
+            "get": {
+                "description": "Retrieve a list of recibos de pagos with pagination",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Payment"
+                ],
+                "summary": "Listado de recibos de pago",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Access Token",
+                        "name": "x-access-token",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Number of records per page",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.SuccessResponseWithMeta"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "record": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.PaymentList"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid Request or Incorrect Data",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/payment/send": {
+            "post": {
+                "description": "procesa el formulario y valida el mismo, devuelve el id del recibo de pago",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Payment"
+                ],
+                "summary": "endpoint para guardar formulario de pago",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Access Token",
+                        "name": "x-access-token",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "unique key of the request, the retries with the same key get the original successful response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Payment Data",
+                        "name": "payment",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.PaymentReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "record": {
+                                            "$ref": "#/definitions/models.PaymentResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid Request or Incorrect Data",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Request with the same key in progress",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "422": {
+                        "description": "Key used with other data",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -576,14 +2766,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/cron/sinc-recibov-procesado": {
+        "/payment/show": {
             "get": {
-                "security": [
-                    {
-                        "BasicAuth": []
-                    }
-                ],
-                "description": "busca registros nuevos en la bd de mysql y sincroniza la data a postgres",
+                "description": "devuelve toda la data relacionada a un recibo de pago",
                 "consumes": [
                     "application/json"
                 ],
@@ -591,18 +2776,52 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Crons"
+                    "Payment"
+                ],
+                "summary": "detalle de un recibo de pago",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Access Token",
+                        "name": "x-access-token",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "paymentId (UUID), created_at(timestamptz)",
+                        "name": "PaymentReqId",
+                        "in": "query",
+                        "required": true
+                    }
                 ],
-                "summary": "Run the task sinc_recibov_procesado",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.SuccessResponse"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "record": {
+                                            "$ref": "#/definitions/models.PaymentResponse"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "400": {
-                        "description": "Bad Request",
+                        "description": "Invalid Request or Incorrect Data",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -610,33 +2829,47 @@ const docTemplate = `{
                 }
             }
         },
-        "/cron/sinc-retencion": {
+        "/payment/show.pdf": {
             "get": {
-                "security": [
-                    {
-                        "BasicAuth": []
-                    }
-                ],
-                "description": "busca registros nuevos en la bd de mysql y sincroniza la data a postgres",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "devuelve el comprobante imprimible del recibo de pago con un QR para verificarlo",
                 "produces": [
-                    "application/json"
+                    "application/pdf"
                 ],
                 "tags": [
-                    "Crons"
+                    "Payment"
+                ],
+                "summary": "comprobante de pago en pdf",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Access Token",
+                        "name": "x-access-token",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "paymentId (UUID), created_at(timestamptz)",
+                        "name": "PaymentReqId",
+                        "in": "query",
+                        "required": true
+                    }
                 ],
-                "summary": "Run the task sinc_retenciones",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.SuccessResponse"
+                            "type": "file"
                         }
                     },
                     "400": {
-                        "description": "Bad Request",
+                        "description": "Invalid Request or Incorrect Data",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -644,14 +2877,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/cron/sinc-tasa-cambio": {
+        "/payment/transfer/balance": {
             "get": {
-                "security": [
-                    {
-                        "BasicAuth": []
-                    }
-                ],
-                "description": "busca registros nuevos en la bd de mysql y sincroniza la data a postgres",
+                "description": "Retrieve the balance available to transfer and what is left of the transfer limits of the cliente",
                 "consumes": [
                     "application/json"
                 ],
@@ -659,18 +2887,48 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Crons"
+                    "Payment"
+                ],
+                "summary": "Saldo disponible para transferir en la cuenta",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Access Token",
+                        "name": "x-access-token",
+                        "in": "header",
+                        "required": true
+                    }
                 ],
-                "summary": "Run the task sinc_tasa_cambio",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.SuccessResponse"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "record": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.BalanceAvailable"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "400": {
-                        "description": "Bad Request",
+                        "description": "Invalid Request or Incorrect Data",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -678,9 +2936,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/factura/list": {
-            "get": {
-                "description": "Retrieve a list of facturas with pagination",
+        "/payment/transfer/confirm": {
+            "post": {
+                "description": "guarda la transferencia con la cotizacion de transfer/prepare, devuelve la transferencia realizada",
                 "consumes": [
                     "application/json"
                 ],
@@ -688,9 +2946,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Factura"
+                    "Payment"
                 ],
-                "summary": "Listado de facturas",
+                "summary": "Confirma una transferencia preparada con el codigo enviado",
                 "parameters": [
                     {
                         "type": "string",
@@ -700,18 +2958,19 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "type": "integer",
-                        "default": 1,
-                        "description": "Page number",
-                        "name": "page",
-                        "in": "query"
+                        "type": "string",
+                        "description": "unique key of the request, the retries with the same key get the original successful response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     },
                     {
-                        "type": "integer",
-                        "default": 10,
-                        "description": "Number of recibos de pago per page",
-                        "name": "limit",
-                        "in": "query"
+                        "description": "Confirmation Data",
+                        "name": "transfer",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.TransferConfirmReq"
+                        }
                     }
                 ],
                 "responses": {
@@ -720,16 +2979,13 @@ const docTemplate = `{
                         "schema": {
                             "allOf": [
                                 {
-                                    "$ref": "#/definitions/models.SuccessResponseWithMeta"
+                                    "$ref": "#/definitions/models.SuccessResponse"
                                 },
                                 {
                                     "type": "object",
                                     "properties": {
                                         "record": {
-                                            "type": "array",
-                                            "items": {
-                                                "$ref": "#/definitions/models.FacturaList"
-                                            }
+                                            "$ref": "#/definitions/models.TransferResponse"
                                         }
                                     }
                                 }
@@ -743,7 +2999,25 @@ const docTemplate = `{
                         }
                     },
                     "401": {
-                        "description": "Unauthorized",
+                        "description": "Unauthorized or invalid code",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Request with the same key in progress",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "410": {
+                        "description": "Prepared transfer expired or already confirmed",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Key used with other data or transfer limits exceeded",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -751,9 +3025,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/factura/show": {
+        "/payment/transfer/history": {
             "get": {
-                "description": "devuelve toda la data relacionada a una factura",
+                "description": "Retrieve a list of transferencias sent and received with pagination, direccion is enviada or recibida",
                 "consumes": [
                     "application/json"
                 ],
@@ -761,9 +3035,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Factura"
+                    "Payment"
                 ],
-                "summary": "detalle de una factura",
+                "summary": "Historial de transferencias",
                 "parameters": [
                     {
                         "type": "string",
@@ -773,11 +3047,18 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "type": "string",
-                        "description": "retencionId (UUID), created_at(timestamptz)",
-                        "name": "FacturaReqId",
-                        "in": "query",
-                        "required": true
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Number of records per page",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -786,13 +3067,16 @@ const docTemplate = `{
                         "schema": {
                             "allOf": [
                                 {
-                                    "$ref": "#/definitions/models.SuccessResponse"
+                                    "$ref": "#/definitions/models.SuccessResponseWithMeta"
                                 },
                                 {
                                     "type": "object",
                                     "properties": {
                                         "record": {
-                                            "$ref": "#/definitions/models.FacturaResponse"
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.TransferList"
+                                            }
                                         }
                                     }
                                 }
@@ -814,9 +3098,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/info/accesibilidad": {
+        "/payment/transfer/list": {
             "get": {
-                "description": "Retrieve Texto con accesibilidad",
+                "description": "Retrieve a list of transferencias sent with pagination",
                 "consumes": [
                     "application/json"
                 ],
@@ -824,27 +3108,52 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Info"
+                    "Payment"
                 ],
-                "summary": "Texto con accesibilidad para la app movil",
+                "summary": "Listado de transferencias",
                 "parameters": [
                     {
-                        "enum": [
-                            "movil",
-                            "web"
-                        ],
                         "type": "string",
-                        "description": "Tipo de plataforma",
-                        "name": "tipo",
-                        "in": "query",
+                        "description": "Access Token",
+                        "name": "x-access-token",
+                        "in": "header",
                         "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Number of records per page",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.SuccessResponse"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.SuccessResponseWithMeta"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "record": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.TransferList"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "400": {
@@ -852,13 +3161,19 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/info/faqs": {
-            "get": {
-                "description": "Retrieve listado de preguntas frecuentes",
+        "/payment/transfer/prepare": {
+            "post": {
+                "description": "valida el destinatario, el saldo y los limites, devuelve la cotizacion con la tasa de cambio actual y envia un codigo por email o sms, la transferencia preparada expira en TRANSFER_PREPARE_MAX_AGE segundos",
                 "consumes": [
                     "application/json"
                 ],
@@ -866,20 +3181,25 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Info"
+                    "Payment"
                 ],
-                "summary": "listado de preguntas frecuentes",
+                "summary": "Prepara una transferencia y envia el codigo para confirmarla",
                 "parameters": [
                     {
-                        "enum": [
-                            "movil",
-                            "web"
-                        ],
                         "type": "string",
-                        "description": "Tipo de plataforma",
-                        "name": "tipo",
-                        "in": "query",
+                        "description": "Access Token",
+                        "name": "x-access-token",
+                        "in": "header",
                         "required": true
+                    },
+                    {
+                        "description": "Transfer Data",
+                        "name": "transfer",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.TransferPrepareReq"
+                        }
                     }
                 ],
                 "responses": {
@@ -894,10 +3214,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "record": {
-                                            "type": "array",
-                                            "items": {
-                                                "$ref": "#/definitions/models.InfoFaq"
-                                            }
+                                            "$ref": "#/definitions/models.TransferPrepared"
                                         }
                                     }
                                 }
@@ -909,13 +3226,37 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Recipient not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Transfer limits exceeded",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many requests",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/info/legal/privacy_policy": {
+        "/payment/transfer/received": {
             "get": {
-                "description": "Retrieve Texto con politicas de privacidad",
+                "description": "Retrieve a list of transferencias received with pagination",
                 "consumes": [
                     "application/json"
                 ],
@@ -923,27 +3264,52 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Info"
+                    "Payment"
                 ],
-                "summary": "Texto con politicas de privacidad",
+                "summary": "Listado de transferencias recibidas",
                 "parameters": [
                     {
-                        "enum": [
-                            "movil",
-                            "web"
-                        ],
                         "type": "string",
-                        "description": "Tipo de plataforma",
-                        "name": "tipo",
-                        "in": "query",
+                        "description": "Access Token",
+                        "name": "x-access-token",
+                        "in": "header",
                         "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Number of records per page",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.SuccessResponse"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.SuccessResponseWithMeta"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "record": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.TransferList"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "400": {
@@ -951,32 +3317,38 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/info/legal/terminos_y_condiciones": {
+        "/payment/transfer/recipient": {
             "get": {
-                "description": "Retrieve Texto con los terminos y condiciones",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "devuelve el nombre enmascarado y las suscripciones del destinatario para confirmarlo antes de transferir",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Info"
+                    "Payment"
                 ],
-                "summary": "Texto con los terminos y condiciones",
+                "summary": "destinatario de una transferencia",
                 "parameters": [
                     {
-                        "enum": [
-                            "movil",
-                            "web"
-                        ],
                         "type": "string",
-                        "description": "Tipo de plataforma",
-                        "name": "tipo",
+                        "description": "Access Token",
+                        "name": "x-access-token",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "docid del destinatario",
+                        "name": "destinatario_docid",
                         "in": "query",
                         "required": true
                     }
@@ -985,7 +3357,19 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.SuccessResponse"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "record": {
+                                            "$ref": "#/definitions/models.TransferRecipient"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "400": {
@@ -993,13 +3377,31 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Recipient Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/info/oficinas": {
-            "get": {
-                "description": "Retrieve a list of oficinas de la empresa con su ubicacion",
+        "/payment/transfer/send": {
+            "post": {
+                "description": "procesa el formulario y valida el mismo, devuelve el id de la transferencia. Reemplazado por transfer/prepare y transfer/confirm, desactivado salvo con TRANSFER_REQUIRE_CONFIRM=false",
                 "consumes": [
                     "application/json"
                 ],
@@ -1007,20 +3409,32 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Info"
+                    "Payment"
                 ],
-                "summary": "Listado de oficinas",
+                "summary": "endpoint para guardar formulario de transferencia",
+                "deprecated": true,
                 "parameters": [
                     {
-                        "enum": [
-                            "movil",
-                            "web"
-                        ],
                         "type": "string",
-                        "description": "Tipo de plataforma",
-                        "name": "tipo",
-                        "in": "query",
+                        "description": "Access Token",
+                        "name": "x-access-token",
+                        "in": "header",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "unique key of the request, the retries with the same key get the original successful response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Transfer Data",
+                        "name": "transfer",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.TransferReq"
+                        }
                     }
                 ],
                 "responses": {
@@ -1035,10 +3449,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "record": {
-                                            "type": "array",
-                                            "items": {
-                                                "$ref": "#/definitions/models.InfoLocation"
-                                            }
+                                            "$ref": "#/definitions/models.TransferResponse"
                                         }
                                     }
                                 }
@@ -1050,23 +3461,44 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Transfers must be confirmed with a code",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Request with the same key in progress",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Key used with other data or transfer limits exceeded",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/payment/image-upload": {
-            "post": {
-                "description": "Upload an image associated with a payment ID",
-                "consumes": [
-                    "multipart/form-data"
-                ],
+        "/payment/transfer/show.pdf": {
+            "get": {
+                "description": "devuelve el comprobante imprimible de la transferencia con un QR para verificarlo",
                 "produces": [
-                    "application/json"
+                    "application/pdf"
                 ],
                 "tags": [
                     "Payment"
                 ],
-                "summary": "Upload image for payment",
+                "summary": "comprobante de transferencia en pdf",
                 "parameters": [
                     {
                         "type": "string",
@@ -1077,16 +3509,9 @@ const docTemplate = `{
                     },
                     {
                         "type": "string",
-                        "description": "paymentId (UUID), created_at(timestamptz)",
-                        "name": "payment_id",
-                        "in": "formData",
-                        "required": true
-                    },
-                    {
-                        "type": "file",
-                        "description": "Image file to upload",
-                        "name": "image",
-                        "in": "formData",
+                        "description": "transferId (UUID), created_at(timestamptz)",
+                        "name": "TransferReqId",
+                        "in": "query",
                         "required": true
                     }
                 ],
@@ -1094,7 +3519,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.SuccessResponse"
+                            "type": "file"
                         }
                     },
                     "400": {
@@ -1112,40 +3537,23 @@ const docTemplate = `{
                 }
             }
         },
-        "/payment/list": {
+        "/payment/verify": {
             "get": {
-                "description": "Retrieve a list of recibos de pagos with pagination",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "endpoint publico del QR de los comprobantes, devuelve los datos impresos en el para validar su autenticidad",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "Payment"
                 ],
-                "summary": "Listado de recibos de pago",
+                "summary": "verificar un comprobante",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Access Token",
-                        "name": "x-access-token",
-                        "in": "header",
+                        "description": "token of the QR",
+                        "name": "token",
+                        "in": "query",
                         "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "default": 1,
-                        "description": "Page number",
-                        "name": "page",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "default": 10,
-                        "description": "Number of records per page",
-                        "name": "limit",
-                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -1154,30 +3562,33 @@ const docTemplate = `{
                         "schema": {
                             "allOf": [
                                 {
-                                    "$ref": "#/definitions/models.SuccessResponseWithMeta"
+                                    "$ref": "#/definitions/models.SuccessResponse"
                                 },
                                 {
                                     "type": "object",
                                     "properties": {
                                         "record": {
-                                            "type": "array",
-                                            "items": {
-                                                "$ref": "#/definitions/models.PaymentList"
-                                            }
+                                            "$ref": "#/definitions/models.ReceiptVerify"
                                         }
                                     }
                                 }
                             ]
                         }
                     },
-                    "400": {
-                        "description": "Invalid Request or Incorrect Data",
+                    "401": {
+                        "description": "Invalid Token",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "404": {
+                        "description": "Receipt Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -1185,9 +3596,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/payment/send": {
-            "post": {
-                "description": "procesa el formulario y valida el mismo, devuelve el id del recibo de pago",
+        "/perfil": {
+            "get": {
+                "description": "devuelve nombre, direccion, telefonos y correos del cliente",
                 "consumes": [
                     "application/json"
                 ],
@@ -1195,9 +3606,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Payment"
+                    "Perfil"
                 ],
-                "summary": "endpoint para guardar formulario de pago",
+                "summary": "perfil del cliente",
                 "parameters": [
                     {
                         "type": "string",
@@ -1205,15 +3616,6 @@ const docTemplate = `{
                         "name": "x-access-token",
                         "in": "header",
                         "required": true
-                    },
-                    {
-                        "description": "Payment Data",
-                        "name": "payment",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.PaymentReq"
-                        }
                     }
                 ],
                 "responses": {
@@ -1228,7 +3630,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "record": {
-                                            "$ref": "#/definitions/models.PaymentResponse"
+                                            "$ref": "#/definitions/models.UserProfile"
                                         }
                                     }
                                 }
@@ -1248,11 +3650,9 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/payment/show": {
-            "get": {
-                "description": "devuelve toda la data relacionada a un recibo de pago",
+            },
+            "patch": {
+                "description": "la direccion se guarda de inmediato, un correo o telefono nuevo (y los que reemplaza) se guarda luego de validar el codigo enviado en /perfil/verificar, los retirados son notificados",
                 "consumes": [
                     "application/json"
                 ],
@@ -1260,9 +3660,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Payment"
+                    "Perfil"
                 ],
-                "summary": "detalle de un recibo de pago",
+                "summary": "actualizar perfil del cliente",
                 "parameters": [
                     {
                         "type": "string",
@@ -1272,11 +3672,13 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "type": "string",
-                        "description": "paymentId (UUID), created_at(timestamptz)",
-                        "name": "PaymentReqId",
-                        "in": "query",
-                        "required": true
+                        "description": "fields to change",
+                        "name": "perfil",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.PerfilUpdateReq"
+                        }
                     }
                 ],
                 "responses": {
@@ -1291,7 +3693,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "record": {
-                                            "$ref": "#/definitions/models.PaymentResponse"
+                                            "$ref": "#/definitions/models.PerfilResponse"
                                         }
                                     }
                                 }
@@ -1309,13 +3711,19 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/payment/transfer/balance": {
-            "get": {
-                "description": "Retrieve the balance available to transfer",
+        "/perfil/verificar": {
+            "post": {
+                "description": "valida el codigo enviado al nuevo correo o telefono y lo agrega a los actuales en lugar de los que reemplaza",
                 "consumes": [
                     "application/json"
                 ],
@@ -1323,9 +3731,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Payment"
+                    "Perfil"
                 ],
-                "summary": "Saldo disponible para transferir en la cuenta",
+                "summary": "verificar cambio de correo o telefono",
                 "parameters": [
                     {
                         "type": "string",
@@ -1333,6 +3741,15 @@ const docTemplate = `{
                         "name": "x-access-token",
                         "in": "header",
                         "required": true
+                    },
+                    {
+                        "description": "campo and code received",
+                        "name": "perfil",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.PerfilVerifyReq"
+                        }
                     }
                 ],
                 "responses": {
@@ -1347,10 +3764,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "record": {
-                                            "type": "array",
-                                            "items": {
-                                                "$ref": "#/definitions/models.BalanceAvailable"
-                                            }
+                                            "$ref": "#/definitions/models.UserProfile"
                                         }
                                     }
                                 }
@@ -1364,7 +3778,7 @@ const docTemplate = `{
                         }
                     },
                     "401": {
-                        "description": "Unauthorized",
+                        "description": "Unauthorized or Invalid Code",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -1372,9 +3786,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/payment/transfer/list": {
+        "/retencion/list": {
             "get": {
-                "description": "Retrieve a list of transferencias with pagination",
+                "description": "Retrieve a list of retenciones with pagination",
                 "consumes": [
                     "application/json"
                 ],
@@ -1382,9 +3796,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Payment"
+                    "Retencion"
                 ],
-                "summary": "Listado de transferencias",
+                "summary": "Listado de retenciones",
                 "parameters": [
                     {
                         "type": "string",
@@ -1403,7 +3817,7 @@ const docTemplate = `{
                     {
                         "type": "integer",
                         "default": 10,
-                        "description": "Number of records per page",
+                        "description": "Number of recibos de pago per page",
                         "name": "limit",
                         "in": "query"
                     }
@@ -1422,7 +3836,7 @@ const docTemplate = `{
                                         "record": {
                                             "type": "array",
                                             "items": {
-                                                "$ref": "#/definitions/models.TransferList"
+                                                "$ref": "#/definitions/models.RetencionList"
                                             }
                                         }
                                     }
@@ -1445,9 +3859,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/payment/transfer/send": {
-            "post": {
-                "description": "procesa el formulario y valida el mismo, devuelve el id de la transferencia",
+        "/retencion/show": {
+            "get": {
+                "description": "devuelve toda la data relacionada a una retencion",
                 "consumes": [
                     "application/json"
                 ],
@@ -1455,9 +3869,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Payment"
+                    "Retencion"
                 ],
-                "summary": "endpoint para guardar formulario de transferencia",
+                "summary": "detalle de una retencion",
                 "parameters": [
                     {
                         "type": "string",
@@ -1467,13 +3881,11 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "description": "Transfer Data",
-                        "name": "transfer",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.TransferReq"
-                        }
+                        "type": "string",
+                        "description": "retencionId (UUID), created_at(timestamptz)",
+                        "name": "RetencionReqId",
+                        "in": "query",
+                        "required": true
                     }
                 ],
                 "responses": {
@@ -1488,7 +3900,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "record": {
-                                            "$ref": "#/definitions/models.TransferResponse"
+                                            "$ref": "#/definitions/models.RetencionResponse"
                                         }
                                     }
                                 }
@@ -1510,9 +3922,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/retencion/list": {
-            "get": {
-                "description": "Retrieve a list of retenciones with pagination",
+        "/suscripcion/autopay": {
+            "post": {
+                "description": "en el dia de renovacion se paga la factura pendiente de la suscripcion con el saldo disponible, no aplica para suscripciones con excluir_pago",
                 "consumes": [
                     "application/json"
                 ],
@@ -1520,9 +3932,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Retencion"
+                    "Suscripcion"
                 ],
-                "summary": "Listado de retenciones",
+                "summary": "Activa o desactiva el pago automatico de una suscripcion",
                 "parameters": [
                     {
                         "type": "string",
@@ -1532,18 +3944,13 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "type": "integer",
-                        "default": 1,
-                        "description": "Page number",
-                        "name": "page",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "default": 10,
-                        "description": "Number of recibos de pago per page",
-                        "name": "limit",
-                        "in": "query"
+                        "description": "Autopay Data",
+                        "name": "autopago",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.SuscripcionAutopagoReq"
+                        }
                     }
                 ],
                 "responses": {
@@ -1552,16 +3959,13 @@ const docTemplate = `{
                         "schema": {
                             "allOf": [
                                 {
-                                    "$ref": "#/definitions/models.SuccessResponseWithMeta"
+                                    "$ref": "#/definitions/models.SuccessResponse"
                                 },
                                 {
                                     "type": "object",
                                     "properties": {
                                         "record": {
-                                            "type": "array",
-                                            "items": {
-                                                "$ref": "#/definitions/models.RetencionList"
-                                            }
+                                            "$ref": "#/definitions/models.Suscripcion"
                                         }
                                     }
                                 }
@@ -1583,9 +3987,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/retencion/show": {
+        "/suscripcion/list": {
             "get": {
-                "description": "devuelve toda la data relacionada a una retencion",
+                "description": "Shows the list of suscripcion for the logged user",
                 "consumes": [
                     "application/json"
                 ],
@@ -1593,9 +3997,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Retencion"
+                    "Suscripcion"
                 ],
-                "summary": "detalle de una retencion",
+                "summary": "Suscripcion List",
                 "parameters": [
                     {
                         "type": "string",
@@ -1603,13 +4007,6 @@ const docTemplate = `{
                         "name": "x-access-token",
                         "in": "header",
                         "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "retencionId (UUID), created_at(timestamptz)",
-                        "name": "RetencionReqId",
-                        "in": "query",
-                        "required": true
                     }
                 ],
                 "responses": {
@@ -1624,7 +4021,10 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "record": {
-                                            "$ref": "#/definitions/models.RetencionResponse"
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.Suscripcion"
+                                            }
                                         }
                                     }
                                 }
@@ -1646,9 +4046,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/suscripcion/list": {
+        "/suscripcion/show": {
             "get": {
-                "description": "Shows the list of suscripcion for the logged user",
+                "description": "Shows a suscripcion of the logged user: plan, speed, zone, gps of the station, install date and current status (the status changes are not registered), the billing calendar (next renewal date and the projection of the next 6 months with the exchange rate of today), the saldo processed and pending confirmation, and its latest facturas, payments and retenciones",
                 "consumes": [
                     "application/json"
                 ],
@@ -1658,7 +4058,7 @@ const docTemplate = `{
                 "tags": [
                     "Suscripcion"
                 ],
-                "summary": "Suscripcion List",
+                "summary": "Suscripcion Show",
                 "parameters": [
                     {
                         "type": "string",
@@ -1666,6 +4066,13 @@ const docTemplate = `{
                         "name": "x-access-token",
                         "in": "header",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Suscripcion Id",
+                        "name": "suscripcion_id",
+                        "in": "query",
+                        "required": true
                     }
                 ],
                 "responses": {
@@ -1680,10 +4087,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "record": {
-                                            "type": "array",
-                                            "items": {
-                                                "$ref": "#/definitions/models.Suscripcion"
-                                            }
+                                            "$ref": "#/definitions/models.SuscripcionDetalle"
                                         }
                                     }
                                 }
@@ -1707,14 +4111,148 @@ const docTemplate = `{
         }
     },
     "definitions": {
+        "models.ActivationRequest": {
+            "type": "object",
+            "required": [
+                "username"
+            ],
+            "properties": {
+                "canal": {
+                    "type": "string",
+                    "enum": [
+                        "email",
+                        "sms"
+                    ]
+                },
+                "username": {
+                    "type": "string",
+                    "maxLength": 30,
+                    "minLength": 7
+                }
+            }
+        },
+        "models.ActivationSend": {
+            "type": "object",
+            "required": [
+                "code",
+                "password",
+                "username"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "maxLength": 6,
+                    "minLength": 6
+                },
+                "password": {
+                    "type": "string",
+                    "maxLength": 30,
+                    "minLength": 7
+                },
+                "username": {
+                    "type": "string",
+                    "maxLength": 30,
+                    "minLength": 7
+                }
+            }
+        },
+        "models.AdminRevokeReq": {
+            "type": "object",
+            "required": [
+                "cliente_id"
+            ],
+            "properties": {
+                "cliente_id": {
+                    "type": "string"
+                },
+                "jti": {
+                    "type": "string"
+                }
+            }
+        },
         "models.BalanceAvailable": {
             "type": "object",
             "properties": {
+                "limites": {
+                    "$ref": "#/definitions/models.TransferAllowance"
+                },
                 "monto_disponible": {
                     "$ref": "#/definitions/models.Moneda"
                 }
             }
         },
+        "models.BancoCandidato": {
+            "type": "object",
+            "properties": {
+                "cliente_id": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "fecha": {
+                    "type": "string"
+                },
+                "monto": {
+                    "type": "number"
+                },
+                "motivo": {
+                    "type": "string"
+                },
+                "payment_id": {
+                    "type": "string"
+                },
+                "referencia": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.BancoImportResult": {
+            "type": "object",
+            "properties": {
+                "archivo": {
+                    "type": "string"
+                },
+                "conciliados": {
+                    "type": "integer"
+                },
+                "duplicados": {
+                    "type": "integer"
+                },
+                "extracto_id": {
+                    "type": "string"
+                },
+                "lineas": {
+                    "type": "integer"
+                },
+                "revision": {
+                    "type": "integer"
+                },
+                "sin_match": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.BancoMovimiento": {
+            "type": "object",
+            "properties": {
+                "descripcion": {
+                    "type": "string"
+                },
+                "fecha": {
+                    "type": "string"
+                },
+                "linea": {
+                    "type": "integer"
+                },
+                "monto": {
+                    "type": "number"
+                },
+                "referencia": {
+                    "type": "string"
+                }
+            }
+        },
         "models.BancoOrigenList": {
             "type": "object",
             "properties": {
@@ -1732,6 +4270,26 @@ const docTemplate = `{
                 }
             }
         },
+        "models.BancoRevision": {
+            "type": "object",
+            "properties": {
+                "candidatos": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.BancoCandidato"
+                    }
+                },
+                "cuenta_banco_id": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "movimiento": {
+                    "$ref": "#/definitions/models.BancoMovimiento"
+                }
+            }
+        },
         "models.CuentasBanco": {
             "type": "object",
             "properties": {
@@ -1758,6 +4316,84 @@ const docTemplate = `{
                 "error": {}
             }
         },
+        "models.EstadoCuenta": {
+            "type": "object",
+            "properties": {
+                "datos_besser": {
+                    "$ref": "#/definitions/models.FacturaDatosBesser"
+                },
+                "desde": {
+                    "type": "string"
+                },
+                "docid": {
+                    "type": "string"
+                },
+                "hasta": {
+                    "type": "string"
+                },
+                "nombre": {
+                    "type": "string"
+                },
+                "suscripciones": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.EstadoCuentaSuscripcion"
+                    }
+                }
+            }
+        },
+        "models.EstadoCuentaMovimiento": {
+            "type": "object",
+            "properties": {
+                "debe": {
+                    "$ref": "#/definitions/models.Moneda"
+                },
+                "documento": {
+                    "type": "string"
+                },
+                "fecha": {
+                    "type": "string"
+                },
+                "haber": {
+                    "$ref": "#/definitions/models.Moneda"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "ncontrol": {
+                    "type": "string"
+                },
+                "saldo": {
+                    "$ref": "#/definitions/models.Moneda"
+                },
+                "tipo": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.EstadoCuentaSuscripcion": {
+            "type": "object",
+            "properties": {
+                "movimientos": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.EstadoCuentaMovimiento"
+                    }
+                },
+                "ncontrol": {
+                    "type": "string"
+                },
+                "saldo_final": {
+                    "$ref": "#/definitions/models.Moneda"
+                },
+                "saldo_inicial": {
+                    "$ref": "#/definitions/models.Moneda"
+                },
+                "suscripcion_id": {
+                    "type": "integer"
+                }
+            }
+        },
         "models.FacturaDatosBesser": {
             "type": "object",
             "properties": {
@@ -1858,6 +4494,9 @@ const docTemplate = `{
                 "factura_id": {
                     "type": "string"
                 },
+                "fecha": {
+                    "type": "string"
+                },
                 "igtf_base_imponible": {
                     "$ref": "#/definitions/models.Moneda"
                 },
@@ -1873,9 +4512,15 @@ const docTemplate = `{
                 "iva_porcentaje": {
                     "type": "number"
                 },
+                "ncontrol": {
+                    "type": "string"
+                },
                 "nreferencia": {
                     "type": "string"
                 },
+                "numero_factura": {
+                    "type": "string"
+                },
                 "pagos": {
                     "type": "array",
                     "items": {
@@ -1894,11 +4539,20 @@ const docTemplate = `{
                 "subtotal": {
                     "$ref": "#/definitions/models.Moneda"
                 },
+                "tasa_cambio": {
+                    "type": "number"
+                },
                 "telefono": {
                     "type": "string"
                 },
+                "tipo_factura": {
+                    "type": "string"
+                },
                 "total": {
                     "$ref": "#/definitions/models.Moneda"
+                },
+                "updated_at": {
+                    "type": "string"
                 }
             }
         },
@@ -1938,6 +4592,25 @@ const docTemplate = `{
                 }
             }
         },
+        "models.ForgotPasswordSmsVerify": {
+            "type": "object",
+            "required": [
+                "code",
+                "username"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "maxLength": 6,
+                    "minLength": 6
+                },
+                "username": {
+                    "type": "string",
+                    "maxLength": 30,
+                    "minLength": 7
+                }
+            }
+        },
         "models.FormaPagoList": {
             "type": "object",
             "properties": {
@@ -1948,79 +4621,225 @@ const docTemplate = `{
                 "id": {
                     "type": "integer"
                 },
-                "metodo_pago": {
+                "metodo_pago": {
+                    "type": "string"
+                },
+                "moneda": {
+                    "type": "string"
+                },
+                "nombre": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.InfoFaq": {
+            "type": "object",
+            "properties": {
+                "pregunta": {
+                    "type": "string"
+                },
+                "respuesta": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.InfoLocation": {
+            "type": "object",
+            "properties": {
+                "ciudad": {
+                    "type": "string"
+                },
+                "coordenadas": {
+                    "type": "string"
+                },
+                "direccion": {
+                    "type": "string"
+                },
+                "horario": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "nombre": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Jwk": {
+            "type": "object",
+            "properties": {
+                "alg": {
+                    "type": "string"
+                },
+                "crv": {
+                    "type": "string"
+                },
+                "e": {
+                    "type": "string"
+                },
+                "kid": {
+                    "type": "string"
+                },
+                "kty": {
+                    "type": "string"
+                },
+                "n": {
+                    "type": "string"
+                },
+                "use": {
+                    "type": "string"
+                },
+                "x": {
+                    "type": "string"
+                },
+                "y": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.JwksResponse": {
+            "type": "object",
+            "properties": {
+                "keys": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Jwk"
+                    }
+                }
+            }
+        },
+        "models.Moneda": {
+            "type": "object",
+            "properties": {
+                "bolivar": {
+                    "type": "number"
+                },
+                "dolar": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.PaginatorData": {
+            "type": "object",
+            "properties": {
+                "current_page": {
+                    "type": "integer"
+                },
+                "next_page": {
+                    "type": "integer"
+                },
+                "prev_page": {
+                    "type": "integer"
+                },
+                "total_count": {
+                    "type": "integer"
+                },
+                "total_pages": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.PaymentAmendReq": {
+            "type": "object",
+            "required": [
+                "banco_cliente_id",
+                "created_at",
+                "cuenta_banco_id",
+                "fecha",
+                "payment_detail",
+                "payment_id",
+                "profile_id",
+                "referencia",
+                "tasa_cambio"
+            ],
+            "properties": {
+                "banco_cliente_id": {
+                    "type": "string",
+                    "maxLength": 15,
+                    "minLength": 1
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "cuenta_banco_id": {
+                    "type": "string",
+                    "maxLength": 15,
+                    "minLength": 1
+                },
+                "email": {
+                    "type": "string"
+                },
+                "fecha": {
                     "type": "string"
                 },
-                "moneda": {
+                "payment_detail": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.PaymentReqDetail"
+                    }
+                },
+                "payment_id": {
                     "type": "string"
                 },
-                "nombre": {
+                "profile_id": {
+                    "type": "string",
+                    "maxLength": 15,
+                    "minLength": 1
+                },
+                "referencia": {
+                    "type": "string",
+                    "maxLength": 200,
+                    "minLength": 10
+                },
+                "tasa_cambio": {
+                    "type": "number",
+                    "minimum": 0
+                },
+                "telefono": {
                     "type": "string"
                 }
             }
         },
-        "models.InfoFaq": {
+        "models.PaymentCancelReq": {
             "type": "object",
+            "required": [
+                "created_at",
+                "payment_id"
+            ],
             "properties": {
-                "pregunta": {
+                "created_at": {
                     "type": "string"
                 },
-                "respuesta": {
+                "motivo": {
+                    "type": "string",
+                    "maxLength": 200
+                },
+                "payment_id": {
                     "type": "string"
                 }
             }
         },
-        "models.InfoLocation": {
+        "models.PaymentFile": {
             "type": "object",
             "properties": {
-                "ciudad": {
-                    "type": "string"
-                },
-                "coordenadas": {
+                "content_type": {
                     "type": "string"
                 },
-                "direccion": {
+                "expires_at": {
                     "type": "string"
                 },
-                "horario": {
-                    "type": "object",
-                    "additionalProperties": {
-                        "type": "string"
-                    }
-                },
                 "nombre": {
                     "type": "string"
-                }
-            }
-        },
-        "models.Moneda": {
-            "type": "object",
-            "properties": {
-                "bolivar": {
-                    "type": "number"
-                },
-                "dolar": {
-                    "type": "number"
-                }
-            }
-        },
-        "models.PaginatorData": {
-            "type": "object",
-            "properties": {
-                "current_page": {
-                    "type": "integer"
-                },
-                "next_page": {
-                    "type": "integer"
                 },
-                "prev_page": {
+                "size": {
                     "type": "integer"
                 },
-                "total_count": {
-                    "type": "integer"
+                "thumb_url": {
+                    "type": "string"
                 },
-                "total_pages": {
-                    "type": "integer"
+                "url": {
+                    "type": "string"
                 }
             }
         },
@@ -2150,6 +4969,12 @@ const docTemplate = `{
                 "fecha": {
                     "type": "string"
                 },
+                "files": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.PaymentFile"
+                    }
+                },
                 "metodo_pago": {
                     "$ref": "#/definitions/models.FormaPagoList"
                 },
@@ -2188,6 +5013,115 @@ const docTemplate = `{
                 }
             }
         },
+        "models.PerfilPendiente": {
+            "type": "object",
+            "properties": {
+                "campo": {
+                    "type": "string"
+                },
+                "destino": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.PerfilResponse": {
+            "type": "object",
+            "properties": {
+                "pendientes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.PerfilPendiente"
+                    }
+                },
+                "profile": {
+                    "$ref": "#/definitions/models.UserProfile"
+                }
+            }
+        },
+        "models.PerfilUpdateReq": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "maxLength": 9,
+                    "minLength": 6
+                },
+                "correo": {
+                    "type": "array",
+                    "maxItems": 3,
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "direccion": {
+                    "type": "string",
+                    "maxLength": 250,
+                    "minLength": 5
+                },
+                "password": {
+                    "type": "string",
+                    "maxLength": 30
+                },
+                "telefono": {
+                    "type": "array",
+                    "maxItems": 3,
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.PerfilVerifyReq": {
+            "type": "object",
+            "required": [
+                "campo",
+                "code"
+            ],
+            "properties": {
+                "campo": {
+                    "type": "string",
+                    "enum": [
+                        "correo",
+                        "telefono"
+                    ]
+                },
+                "code": {
+                    "type": "string",
+                    "maxLength": 6,
+                    "minLength": 6
+                }
+            }
+        },
+        "models.ReceiptVerify": {
+            "type": "object",
+            "properties": {
+                "estatus": {
+                    "type": "string"
+                },
+                "fecha": {
+                    "type": "string"
+                },
+                "monto": {
+                    "$ref": "#/definitions/models.Moneda"
+                },
+                "ncontrol": {
+                    "type": "string"
+                },
+                "tipo": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ResetTokenResponse": {
+            "type": "object",
+            "properties": {
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
         "models.RetencionList": {
             "type": "object",
             "properties": {
@@ -2238,56 +5172,197 @@ const docTemplate = `{
                 "factura": {
                     "$ref": "#/definitions/models.FacturaList"
                 },
-                "fecha_retencion": {
+                "fecha_retencion": {
+                    "type": "string"
+                },
+                "monto_retenido": {
+                    "$ref": "#/definitions/models.Moneda"
+                },
+                "ncontrol": {
+                    "type": "string"
+                },
+                "num_comprobante": {
+                    "type": "string"
+                },
+                "porcentaje_retencion": {
+                    "type": "number"
+                },
+                "retencion_id": {
+                    "type": "string"
+                },
+                "tipo_retencion": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.SessionList": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "current": {
+                    "type": "boolean"
+                },
+                "device_info": {
+                    "$ref": "#/definitions/models.UserDeviceInfo"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "last_used_at": {
+                    "type": "string"
+                },
+                "session_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.SessionReqId": {
+            "type": "object",
+            "required": [
+                "session_id"
+            ],
+            "properties": {
+                "session_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.SuccessResponse": {
+            "type": "object",
+            "properties": {
+                "notice": {
+                    "type": "string"
+                },
+                "record": {}
+            }
+        },
+        "models.SuccessResponseWithMeta": {
+            "type": "object",
+            "properties": {
+                "_meta": {
+                    "$ref": "#/definitions/models.PaginatorData"
+                },
+                "notice": {
+                    "type": "string"
+                },
+                "record": {}
+            }
+        },
+        "models.Suscripcion": {
+            "type": "object",
+            "properties": {
+                "autopago": {
+                    "type": "boolean"
+                },
+                "calendario": {
+                    "$ref": "#/definitions/models.SuscripcionCalendario"
+                },
+                "coordenadas": {
+                    "type": "string"
+                },
+                "costo": {
+                    "$ref": "#/definitions/models.Moneda"
+                },
+                "estatus": {
+                    "type": "boolean"
+                },
+                "excluir_pago": {
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "ncontrol": {
+                    "type": "string"
+                },
+                "renewal_day": {
                     "type": "string"
                 },
-                "monto_retenido": {
+                "saldo": {
                     "$ref": "#/definitions/models.Moneda"
                 },
-                "ncontrol": {
-                    "type": "string"
-                },
-                "num_comprobante": {
+                "speed_unit": {
                     "type": "string"
                 },
-                "porcentaje_retencion": {
+                "speed_value": {
                     "type": "number"
                 },
-                "retencion_id": {
+                "tipo_conexion": {
                     "type": "string"
                 },
-                "tipo_retencion": {
+                "tipo_servicio": {
                     "type": "string"
                 },
-                "updated_at": {
+                "tipo_servicio_acronimo": {
+                    "type": "string"
+                },
+                "zona": {
                     "type": "string"
                 }
             }
         },
-        "models.SuccessResponse": {
+        "models.SuscripcionAutopagoReq": {
             "type": "object",
+            "required": [
+                "activo",
+                "suscripcion_id"
+            ],
             "properties": {
-                "notice": {
-                    "type": "string"
+                "activo": {
+                    "type": "boolean"
                 },
-                "record": {}
+                "suscripcion_id": {
+                    "type": "string",
+                    "minLength": 1
+                }
             }
         },
-        "models.SuccessResponseWithMeta": {
+        "models.SuscripcionCalendario": {
             "type": "object",
             "properties": {
-                "_meta": {
-                    "$ref": "#/definitions/models.PaginatorData"
+                "dia_renovacion": {
+                    "type": "integer"
                 },
-                "notice": {
+                "dias_restantes": {
+                    "type": "integer"
+                },
+                "fecha_instalacion": {
                     "type": "string"
                 },
-                "record": {}
+                "monto_proyectado": {
+                    "$ref": "#/definitions/models.Moneda"
+                },
+                "proxima_renovacion": {
+                    "type": "string"
+                },
+                "proyeccion": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.SuscripcionProyeccion"
+                    }
+                },
+                "tasa_cambio": {
+                    "type": "number"
+                }
             }
         },
-        "models.Suscripcion": {
+        "models.SuscripcionDetalle": {
             "type": "object",
             "properties": {
+                "autopago": {
+                    "type": "boolean"
+                },
+                "calendario": {
+                    "$ref": "#/definitions/models.SuscripcionCalendario"
+                },
+                "cliente_id": {
+                    "type": "string"
+                },
                 "coordenadas": {
                     "type": "string"
                 },
@@ -2300,18 +5375,45 @@ const docTemplate = `{
                 "excluir_pago": {
                     "type": "boolean"
                 },
+                "facturas": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.FacturaList"
+                    }
+                },
+                "fecha_instalacion": {
+                    "type": "string"
+                },
                 "id": {
                     "type": "integer"
                 },
                 "ncontrol": {
                     "type": "string"
                 },
+                "pagos": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.PaymentList"
+                    }
+                },
+                "plan": {
+                    "type": "string"
+                },
                 "renewal_day": {
                     "type": "string"
                 },
+                "retenciones": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.RetencionList"
+                    }
+                },
                 "saldo": {
                     "$ref": "#/definitions/models.Moneda"
                 },
+                "saldo_detalle": {
+                    "$ref": "#/definitions/models.SuscripcionSaldo"
+                },
                 "speed_unit": {
                     "type": "string"
                 },
@@ -2332,6 +5434,31 @@ const docTemplate = `{
                 }
             }
         },
+        "models.SuscripcionProyeccion": {
+            "type": "object",
+            "properties": {
+                "fecha": {
+                    "type": "string"
+                },
+                "monto": {
+                    "$ref": "#/definitions/models.Moneda"
+                }
+            }
+        },
+        "models.SuscripcionSaldo": {
+            "type": "object",
+            "properties": {
+                "por_confirmar": {
+                    "$ref": "#/definitions/models.Moneda"
+                },
+                "procesado": {
+                    "$ref": "#/definitions/models.Moneda"
+                },
+                "total": {
+                    "$ref": "#/definitions/models.Moneda"
+                }
+            }
+        },
         "models.SuscripcionShortInfo": {
             "type": "object",
             "properties": {
@@ -2361,6 +5488,46 @@ const docTemplate = `{
                 }
             }
         },
+        "models.TransferAllowance": {
+            "type": "object",
+            "properties": {
+                "destinatarios_disponibles": {
+                    "type": "integer"
+                },
+                "disponible_diario": {
+                    "type": "number"
+                },
+                "disponible_mensual": {
+                    "type": "number"
+                },
+                "max_proxima_transferencia": {
+                    "type": "number"
+                },
+                "max_transferencia": {
+                    "type": "number"
+                },
+                "segmento": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.TransferConfirmReq": {
+            "type": "object",
+            "required": [
+                "code",
+                "preparada_id"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "maxLength": 6,
+                    "minLength": 6
+                },
+                "preparada_id": {
+                    "type": "string"
+                }
+            }
+        },
         "models.TransferList": {
             "type": "object",
             "properties": {
@@ -2373,17 +5540,114 @@ const docTemplate = `{
                 "destinatario_docid": {
                     "type": "string"
                 },
+                "direccion": {
+                    "type": "string"
+                },
                 "monto": {
                     "$ref": "#/definitions/models.Moneda"
                 },
                 "ncontrol": {
                     "type": "string"
                 },
+                "remitente_docid": {
+                    "type": "string"
+                },
                 "transfer_id": {
                     "type": "string"
                 }
             }
         },
+        "models.TransferPrepareReq": {
+            "type": "object",
+            "required": [
+                "descripcion",
+                "destinatario_docid",
+                "monto"
+            ],
+            "properties": {
+                "canal": {
+                    "type": "string",
+                    "enum": [
+                        "email",
+                        "sms"
+                    ]
+                },
+                "descripcion": {
+                    "type": "string",
+                    "maxLength": 200,
+                    "minLength": 10
+                },
+                "destinatario_docid": {
+                    "type": "string",
+                    "maxLength": 15,
+                    "minLength": 5
+                },
+                "monto": {
+                    "type": "number",
+                    "minimum": 0
+                }
+            }
+        },
+        "models.TransferPrepared": {
+            "type": "object",
+            "properties": {
+                "canal": {
+                    "type": "string"
+                },
+                "descripcion": {
+                    "type": "string"
+                },
+                "destinatario": {
+                    "$ref": "#/definitions/models.TransferRecipient"
+                },
+                "destino": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "monto": {
+                    "$ref": "#/definitions/models.Moneda"
+                },
+                "preparada_id": {
+                    "type": "string"
+                },
+                "tasa_cambio": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.TransferRecipient": {
+            "type": "object",
+            "properties": {
+                "docid": {
+                    "type": "string"
+                },
+                "nombre": {
+                    "type": "string"
+                },
+                "suscripciones": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.TransferRecipientSuscripcion"
+                    }
+                }
+            }
+        },
+        "models.TransferRecipientSuscripcion": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "ncontrol": {
+                    "type": "string"
+                },
+                "tipo_servicio": {
+                    "type": "string"
+                }
+            }
+        },
         "models.TransferReq": {
             "type": "object",
             "required": [
@@ -2435,6 +5699,72 @@ const docTemplate = `{
                 }
             }
         },
+        "models.TwoFactorCodeReq": {
+            "type": "object",
+            "required": [
+                "code"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "maxLength": 6,
+                    "minLength": 6
+                }
+            }
+        },
+        "models.TwoFactorDisableReq": {
+            "type": "object",
+            "required": [
+                "code",
+                "password"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "maxLength": 9,
+                    "minLength": 6
+                },
+                "password": {
+                    "type": "string",
+                    "maxLength": 30,
+                    "minLength": 7
+                }
+            }
+        },
+        "models.TwoFactorEnrollResponse": {
+            "type": "object",
+            "properties": {
+                "otpauth_uri": {
+                    "type": "string"
+                },
+                "recovery_codes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "secret": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.TwoFactorVerifyReq": {
+            "type": "object",
+            "required": [
+                "code",
+                "pre_auth_token"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "maxLength": 9,
+                    "minLength": 6
+                },
+                "pre_auth_token": {
+                    "type": "string"
+                }
+            }
+        },
         "models.UserChangePassword": {
             "type": "object",
             "required": [
@@ -2459,6 +5789,9 @@ const docTemplate = `{
             "properties": {
                 "change_passwd": {
                     "type": "boolean"
+                },
+                "passwd_deadline": {
+                    "type": "string"
                 }
             }
         },
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"gopkg.in/natefinch/lumberjack.v2"
@@ -22,8 +23,10 @@ import (
 
 func init() {
 	app.LoadEnvVariables()
+	app.CheckRequiredSecrets()
 	app.InitDbMysql()
 	app.InitDbPgsql()
+	app.InitJwtKeys()
 	app.LoadCrontab()
 
 	gin.SetMode(os.Getenv("GIN_MODE"))
@@ -45,6 +48,18 @@ func init() {
 	}()
 }
 
+// proxies of TRUSTED_PROXIES, nil when there is no proxy so the ip of the client is the one of the connection
+func trustedProxies() []string {
+	var proxies []string
+	for _, proxy := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			proxies = append(proxies, proxy)
+		}
+	}
+
+	return proxies
+}
+
 // @Title								MiCuenta Service API
 // @Version							1.0
 // @Description 				service in Go using Gin framework
@@ -58,6 +73,11 @@ func init() {
 func main() {
 	r := gin.Default()
 
+	// the ip of the client is taken from X-Forwarded-For only when the request comes from a trusted proxy
+	if err := r.SetTrustedProxies(trustedProxies()); err != nil {
+		utils.Fatalf("error setting TRUSTED_PROXIES", err)
+	}
+
 	// aply startTimer middleware
 	r.Use(middlewares.StartTimer())
 
@@ -91,13 +111,14 @@ func main() {
 	secureCookie, _ := strconv.ParseBool(os.Getenv("SECURE_COOKIE"))
 	maxAgeCookie, _ := strconv.Atoi(os.Getenv("REFRESH_MAX_AGE"))
 	store.Options(sessions.Options{MaxAge: maxAgeCookie, Path: "/", Secure: secureCookie, HttpOnly: true})
-	r.Use(sessions.Sessions("auth_session", store))
+	r.Use(sessions.Sessions(utils.SessionName, store))
 
 	// load templates
 	r.LoadHTMLGlob("templates/*")
 
-	// load static files
-	r.Static("/public", "./public")
+	// load static files, the files uploaded by the clientes are only served with signed urls
+	r.Static("/public/assets", "./public/assets")
+	r.Static("/public/fonts", "./public/fonts")
 
 	// manual routes
 	controllers.AuthRoutes(r)
@@ -106,8 +127,12 @@ func main() {
 	controllers.PaymentRoutes(r)
 	controllers.FacturaRoutes(r)
 	controllers.RetencionRoutes(r)
+	controllers.PerfilRoutes(r)
+	controllers.CuentaRoutes(r)
 	controllers.InfoRoutes(r)
 	controllers.CronRoutes(r)
+	controllers.AdminRoutes(r)
+	controllers.JwksRoutes(r)
 
 	// load docs
 	controllers.SwaggerRoutes(r)
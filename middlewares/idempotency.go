@@ -0,0 +1,113 @@
+package middlewares
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+
+	ginI18n "github.com/gin-contrib/i18n"
+	"github.com/gin-gonic/gin"
+	"ired.com/micuenta/app"
+	"ired.com/micuenta/models"
+	"ired.com/micuenta/repo"
+	"ired.com/micuenta/utils"
+)
+
+// keep a copy of the response to save it with the key
+type idempotencyWriter struct {
+	gin.ResponseWriter
+	body *bytes.Buffer
+}
+
+func (w idempotencyWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w idempotencyWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// hash of the route and the body, the json is decoded and encoded again so the
+// order of the keys or the spaces do not change it
+func requestFingerprint(c *gin.Context, body []byte) string {
+	var data any
+	if err := json.Unmarshal(body, &data); err == nil {
+		if normalized, err := json.Marshal(data); err == nil {
+			body = normalized
+		}
+	}
+	return utils.HashToken(c.Request.Method + " " + c.FullPath() + "\n" + string(body))
+}
+
+// handle the Idempotency-Key header, must be used after JwtAuth. Retries with
+// the same key and body get the original response, with other body are rejected
+func Idempotency(c *gin.Context) {
+	llave := c.GetHeader("Idempotency-Key")
+	if llave == "" {
+		c.Next()
+		return
+	}
+	if len(llave) < 8 || len(llave) > 100 {
+		c.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			models.ErrorResponse{Error: ginI18n.MustGetMessage(c, "idempotencyKeyInvalid")},
+		)
+		return
+	}
+
+	var body []byte
+	if c.Request.Body != nil {
+		body, _ = io.ReadAll(c.Request.Body)
+	}
+	c.Request.Body = io.NopCloser(bytes.NewBuffer(body))
+
+	userId, _ := c.Get("userId")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	db := models.ConnDb{ConnPgsql: app.PoolPgsql, Ctx: ctx}
+
+	record, errType, err := repo.IdempotencyBegin(db, userId, llave, c.FullPath(), requestFingerprint(c, body))
+	if err != nil {
+		c.AbortWithStatusJSON(
+			errType,
+			models.ErrorResponse{Error: ginI18n.MustGetMessage(c, err.Error())},
+		)
+		return
+	}
+	if record != nil {
+		c.Header("Idempotent-Replayed", "true")
+		c.Data(record.ResponseStatus, "application/json; charset=utf-8", []byte(record.ResponseBody))
+		c.Abort()
+		return
+	}
+
+	// the key is released if the response is not saved, also on panic
+	saved := false
+	defer func() {
+		if !saved {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			repo.IdempotencyRelease(models.ConnDb{ConnPgsql: app.PoolPgsql, Ctx: ctx}, userId, llave)
+		}
+	}()
+
+	writer := idempotencyWriter{ResponseWriter: c.Writer, body: &bytes.Buffer{}}
+	c.Writer = writer
+	c.Next()
+
+	// only successful responses are saved, after an error (validation, saldo, server)
+	// the retry with the same key must be processed again
+	if c.Writer.Status() < http.StatusOK || c.Writer.Status() >= http.StatusMultipleChoices {
+		return
+	}
+
+	ctxSave, cancelSave := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelSave()
+	err = repo.IdempotencySave(models.ConnDb{ConnPgsql: app.PoolPgsql, Ctx: ctxSave}, userId, llave, c.Writer.Status(), writer.body.String())
+	saved = err == nil
+}
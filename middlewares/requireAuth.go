@@ -1,17 +1,46 @@
 package middlewares
 
 import (
+	"context"
 	"encoding/base64"
 	"net/http"
 	"os"
 	"strings"
+	"time"
 
 	ginI18n "github.com/gin-contrib/i18n"
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
+	"ired.com/micuenta/app"
 	"ired.com/micuenta/models"
+	"ired.com/micuenta/repo"
+	"ired.com/micuenta/utils"
 )
 
+// check the revocation list, it aborts the request if the token was revoked
+func tokenRevoked(c *gin.Context, claims *models.Claims) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	db := models.ConnDb{ConnPgsql: app.PoolPgsql, Ctx: ctx}
+
+	revoked, err := repo.IsTokenRevoked(db, claims)
+	if err != nil {
+		c.AbortWithStatusJSON(
+			http.StatusInternalServerError,
+			models.ErrorResponse{Error: ginI18n.MustGetMessage(c, "errorInternal")},
+		)
+		return true
+	}
+	if revoked {
+		c.AbortWithStatusJSON(
+			http.StatusUnauthorized,
+			models.ErrorResponse{Error: ginI18n.MustGetMessage(c, "tokenRevoked")},
+		)
+		return true
+	}
+
+	return false
+}
+
 func JwtAuth(c *gin.Context) {
 	// get the cookie off req
 	authToken := c.GetHeader("Authorization")
@@ -25,12 +54,10 @@ func JwtAuth(c *gin.Context) {
 	}
 	authToken = authToken[7:]
 
-	// decode/validate it
+	// decode/validate it, the refresh token is signed with the same key and is rejected by its type
 	claims := &models.Claims{}
-	token, err := jwt.ParseWithClaims(authToken, claims, func(token *jwt.Token) (interface{}, error) {
-		return []byte(os.Getenv("SECRET")), nil
-	})
-	if err != nil || !token.Valid {
+	token, err := utils.ParseJwt(authToken, claims)
+	if err != nil || !token.Valid || claims.TokenType != models.TokenTypeAccess {
 		c.AbortWithStatusJSON(
 			http.StatusUnauthorized,
 			models.ErrorResponse{Error: ginI18n.MustGetMessage(c, "userNotAuth")},
@@ -38,6 +65,11 @@ func JwtAuth(c *gin.Context) {
 		return
 	}
 
+	// token could be revoked by logout, password change or back office
+	if tokenRevoked(c, claims) {
+		return
+	}
+
 	if claims.ChangePasswd {
 		c.AbortWithStatusJSON(
 			http.StatusUnauthorized,
@@ -65,12 +97,10 @@ func JwtPasswdAuth(c *gin.Context) {
 	}
 	authToken = authToken[7:]
 
-	// decode/validate it
+	// decode/validate it, the refresh token is signed with the same key and is rejected by its type
 	claims := &models.Claims{}
-	token, err := jwt.ParseWithClaims(authToken, claims, func(token *jwt.Token) (interface{}, error) {
-		return []byte(os.Getenv("SECRET")), nil
-	})
-	if err != nil || !token.Valid {
+	token, err := utils.ParseJwt(authToken, claims)
+	if err != nil || !token.Valid || claims.TokenType != models.TokenTypeAccess {
 		c.AbortWithStatusJSON(
 			http.StatusUnauthorized,
 			models.ErrorResponse{Error: ginI18n.MustGetMessage(c, "userNotAuth")},
@@ -78,6 +108,11 @@ func JwtPasswdAuth(c *gin.Context) {
 		return
 	}
 
+	// token could be revoked by logout, password change or back office
+	if tokenRevoked(c, claims) {
+		return
+	}
+
 	// attach to req
 	c.Set("userId", claims.UserId)
 
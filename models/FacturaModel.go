@@ -62,12 +62,18 @@ type FacturaReqId struct {
 type FacturaResponse struct {
 	Id            string               `json:"factura_id"`
 	NumReferencia string               `json:"nreferencia"`
+	NControl      string               `json:"ncontrol"`
+	NFactura      string               `json:"numero_factura"`
+	TipoFactura   string               `json:"tipo_factura"`
+	Fecha         string               `json:"fecha"`
+	TasaCambio    float64              `json:"tasa_cambio"`
 	RazonSocial   string               `json:"razon_social"`
 	DocId         string               `json:"docid"`
 	Telefono      string               `json:"telefono"`
 	Direccion     string               `json:"direccion"`
 	Estatus       string               `json:"estatus"`
 	CreatedAt     time.Time            `json:"created_at"`
+	UpdatedAt     time.Time            `json:"updated_at"`
 	SubTotal      Moneda               `json:"subtotal"`
 	DescPorc      float64              `json:"desc_porcentaje"`
 	DescMonto     Moneda               `json:"desc_monto_dolar"`
@@ -6,6 +6,7 @@ import (
 
 	"github.com/gin-gonic/gin/binding"
 	"github.com/go-playground/validator/v10"
+	"github.com/golang-jwt/jwt/v5"
 )
 
 type PaymentReq struct {
@@ -43,6 +44,7 @@ type PaymentResponse struct {
 	Email         string                 `json:"email"`
 	Telefono      string                 `json:"telefono"`
 	UrlFile       string                 `json:"url_file"`
+	Files         []PaymentFile          `json:"files"`
 	CreatedAt     time.Time              `json:"created_at"`
 	UpdatedAt     time.Time              `json:"updated_at"`
 	MetodoPago    FormaPagoList          `json:"metodo_pago"`
@@ -57,6 +59,39 @@ type PaymentReqId struct {
 	CreatedAt string `form:"created_at" json:"created_at" binding:"required,datetime=2006-01-02T15:04:05-07:00"`
 }
 
+// user request to cancel a payment still pendiente
+type PaymentCancelReq struct {
+	PaymentId string `json:"payment_id" binding:"required,uuid"`
+	CreatedAt string `json:"created_at" binding:"required,datetime=2006-01-02T15:04:05-07:00"`
+	Motivo    string `json:"motivo" binding:"omitempty,alfanumspa,max=200"`
+}
+
+// user request to fix a payment still pendiente, the payment data is replaced
+type PaymentAmendReq struct {
+	PaymentId string `json:"payment_id" binding:"required,uuid"`
+	CreatedAt string `json:"created_at" binding:"required,datetime=2006-01-02T15:04:05-07:00"`
+	PaymentReq
+}
+
+// claims of the signed url to download the file of a payment
+type PaymentFileClaims struct {
+	ClienteId string `json:"cliente_id"`
+	PaymentId string `json:"payment_id"`
+	CreatedAt string `json:"created_at"`
+	FileKey   string `json:"file_key"`
+	jwt.RegisteredClaims
+}
+
+// attachment of a payment with its signed urls, pdf files do not have thumbnail
+type PaymentFile struct {
+	Nombre      string `json:"nombre"`
+	ContentType string `json:"content_type"`
+	Size        int    `json:"size"`
+	Url         string `json:"url"`
+	ThumbUrl    string `json:"thumb_url,omitempty"`
+	ExpiresAt   string `json:"expires_at"`
+}
+
 type PaymentList struct {
 	PaymentId  string        `json:"payment_id"`
 	Ncontrol   string        `json:"ncontrol"`
@@ -77,7 +112,32 @@ func init() {
 }
 
 type BalanceAvailable struct {
-	Monto Moneda `json:"monto_disponible"`
+	Monto   Moneda            `json:"monto_disponible"`
+	Limites TransferAllowance `json:"limites"`
+}
+
+// limits of the transfers of a segment of clientes, amounts in dolares
+type TransferLimite struct {
+	Segmento          string
+	MaxTransferencia  float64
+	MaxDiario         float64
+	MaxMensual        float64
+	MaxDestinatarios  int
+	UsadoDiario       float64
+	UsadoMensual      float64
+	DestinatariosHoy  int
+	DestinatarioUsado bool
+}
+
+// what is left of the limits of the cliente, MaxProximaTransferencia already
+// takes into account the saldo and all the limits
+type TransferAllowance struct {
+	Segmento                 string  `json:"segmento"`
+	MaxTransferencia         float64 `json:"max_transferencia"`
+	DisponibleDiario         float64 `json:"disponible_diario"`
+	DisponibleMensual        float64 `json:"disponible_mensual"`
+	DestinatariosDisponibles int     `json:"destinatarios_disponibles"`
+	MaxProximaTransferencia  float64 `json:"max_proxima_transferencia"`
 }
 
 type TransferReq struct {
@@ -99,12 +159,57 @@ type TransferResponse struct {
 type TransferList struct {
 	TransferId        string    `json:"transfer_id"`
 	Ncontrol          string    `json:"ncontrol"`
+	Direccion         string    `json:"direccion"`
 	DestinatarioDocId string    `json:"destinatario_docid"`
+	RemitenteDocId    string    `json:"remitente_docid"`
 	Monto             Moneda    `json:"monto"`
 	Descripcion       string    `json:"descripcion"`
 	CreatedAt         time.Time `json:"created_at"`
 }
 
+// lookup of the recipient before sending a transfer
+// first step of a transfer, the code to confirm it is sent by canal (email by default)
+type TransferPrepareReq struct {
+	DestinatarioDocId string  `json:"destinatario_docid" binding:"required,min=5,max=15"`
+	Descripcion       string  `json:"descripcion" binding:"required,alfanumspa,min=10,max=200"`
+	Monto             float64 `json:"monto" binding:"required,gte=0,decimals_number=2"`
+	Canal             string  `json:"canal" binding:"omitempty,oneof=email sms"`
+}
+
+// quote of the prepared transfer, it is saved with this tasa_cambio when confirmed before expires_at
+type TransferPrepared struct {
+	PreparadaId  string            `json:"preparada_id"`
+	Destinatario TransferRecipient `json:"destinatario"`
+	Descripcion  string            `json:"descripcion"`
+	Monto        Moneda            `json:"monto"`
+	TasaCambio   float64           `json:"tasa_cambio"`
+	Canal        string            `json:"canal"`
+	Destino      string            `json:"destino"`
+	ExpiresAt    time.Time         `json:"expires_at"`
+}
+
+type TransferConfirmReq struct {
+	PreparadaId string `json:"preparada_id" binding:"required,uuid"`
+	Code        string `json:"code" binding:"required,number,min=6,max=6"`
+}
+
+type TransferRecipientReq struct {
+	DestinatarioDocId string `form:"destinatario_docid" json:"destinatario_docid" binding:"required,min=5,max=15"`
+}
+
+// the name is masked, only enough to confirm who is the recipient
+type TransferRecipient struct {
+	DocId         string                         `json:"docid"`
+	Nombre        string                         `json:"nombre"`
+	Suscripciones []TransferRecipientSuscripcion `json:"suscripciones"`
+}
+
+type TransferRecipientSuscripcion struct {
+	Id           int64  `json:"id"`
+	Ncontrol     string `json:"ncontrol"`
+	TipoServicio string `json:"tipo_servicio"`
+}
+
 type TransferReqId struct {
 	TransferId string `form:"transfer_id" json:"transfer_id" binding:"required,uuid"`
 	CreatedAt  string `form:"created_at" json:"created_at" binding:"required,datetime=2006-01-02T15:04:05-07:00"`
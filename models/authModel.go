@@ -1,6 +1,8 @@
 package models
 
 import (
+	"time"
+
 	"github.com/gin-gonic/gin/binding"
 	"github.com/go-playground/validator/v10"
 	"github.com/golang-jwt/jwt/v5"
@@ -41,12 +43,14 @@ type UserProfile struct {
 }
 
 type UserCredentials struct {
-	Passwd       pgtype.Text `json:"-"`
-	ChangePasswd bool        `json:"change_passwd"`
+	Passwd         pgtype.Text `json:"-"`
+	ChangePasswd   bool        `json:"change_passwd"`
+	PasswdDeadline *time.Time  `json:"passwd_deadline,omitempty"`
 }
 
 type UserToken struct {
 	Auth             string `json:"auth"`
+	AuthId           string `json:"-"`
 	AuthMaxAge       int    `json:"-"`
 	AuthExpiresAt    string `json:"auth_expires_at"`
 	Refresh          string `json:"refresh"`
@@ -66,10 +70,18 @@ func init() {
 	}
 }
 
+// type of token on the typ claim, both are signed with the same keys so the
+// access token is not accepted on the refresh and the other way around
+const (
+	TokenTypeAccess  = "access"
+	TokenTypeRefresh = "refresh"
+)
+
 // claims on auth and refresh token
 type Claims struct {
 	UserId       string
 	ChangePasswd bool
+	TokenType    string `json:"typ"`
 	jwt.RegisteredClaims
 }
 
@@ -84,3 +96,104 @@ type ForgotPasswordSend struct {
 	Password string `json:"password" binding:"required,passwd_strenght,min=7,max=30"`
 	Token    string `json:"token" binding:"required"`
 }
+
+// user request to verify the code sent by sms to reset the password
+type ForgotPasswordSmsVerify struct {
+	Username string `json:"username" binding:"required,min=7,max=30"`
+	Code     string `json:"code" binding:"required,number,min=6,max=6"`
+}
+
+// token to use on /auth/forgot-password-send after the sms code was verified
+type ResetTokenResponse struct {
+	Token string `json:"token"`
+}
+
+// active session of the user stored on cliente_session_store
+type SessionList struct {
+	Id         string         `json:"session_id"`
+	Current    bool           `json:"current"`
+	DeviceInfo UserDeviceInfo `json:"device_info"`
+	CreatedAt  time.Time      `json:"created_at"`
+	LastUsedAt time.Time      `json:"last_used_at"`
+	ExpiresAt  time.Time      `json:"expires_at"`
+}
+
+// user request for revoke a session
+type SessionReqId struct {
+	Id string `json:"session_id" binding:"required,uuid"`
+}
+
+// claims of the pre-auth token issued on login when the second factor is required
+type PreAuthClaims struct {
+	UserId     string
+	Remember   bool
+	DeviceInfo UserDeviceInfo
+	jwt.RegisteredClaims
+}
+
+// login response when the user has two-factor authentication enabled
+type TwoFactorChallenge struct {
+	PreAuthToken string   `json:"pre_auth_token"`
+	ExpiresAt    string   `json:"expires_at"`
+	Methods      []string `json:"methods"`
+}
+
+// data to register the totp secret on the authenticator app
+type TwoFactorEnrollResponse struct {
+	Secret        string   `json:"secret"`
+	OtpauthUri    string   `json:"otpauth_uri"`
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// user request to activate two-factor authentication
+type TwoFactorCodeReq struct {
+	Code string `json:"code" binding:"required,number,min=6,max=6"`
+}
+
+// user request to disable two-factor authentication, code could be a recovery code
+type TwoFactorDisableReq struct {
+	Password string `json:"password" binding:"required,min=7,max=30"`
+	Code     string `json:"code" binding:"required,min=6,max=9"`
+}
+
+// user request to finish the login with the second factor, code could be a recovery code
+type TwoFactorVerifyReq struct {
+	PreAuthToken string `json:"pre_auth_token" binding:"required"`
+	Code         string `json:"code" binding:"required,min=6,max=9"`
+}
+
+// user request for the activation code of an account without password
+type ActivationRequest struct {
+	Username string `json:"username" binding:"required,min=7,max=30"`
+	Canal    string `json:"canal" binding:"omitempty,oneof=email sms"`
+}
+
+// user request to activate the account with the code and the new password
+type ActivationSend struct {
+	Username string `json:"username" binding:"required,min=7,max=30"`
+	Code     string `json:"code" binding:"required,number,min=6,max=6"`
+	Password string `json:"password" binding:"required,passwd_strenght,min=7,max=30"`
+}
+
+// public key in JWK format (RFC 7517) to verify the access tokens
+type Jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+}
+
+type JwksResponse struct {
+	Keys []Jwk `json:"keys"`
+}
+
+// back office request to revoke one access token (jti) or all the tokens and sessions of the cliente
+type AdminRevokeReq struct {
+	ClienteId string `json:"cliente_id" binding:"required,number"`
+	Jti       string `json:"jti" binding:"omitempty,uuid"`
+}
@@ -26,3 +26,60 @@ type Moneda struct {
 	Bolivar float64 `json:"bolivar"`
 	Dolar   float64 `json:"dolar"`
 }
+
+// layout of the csv statement of a bank, Columns are the index of each field
+// and Headers the name of the column on the first row (generic mapping), the
+// fields are fecha, referencia, descripcion and monto
+type BancoLayout struct {
+	Nombre           string            `json:"nombre"`
+	CuentaBancoId    int64             `json:"cuenta_banco_id"`
+	Delimiter        string            `json:"delimiter"`
+	SkipRows         int               `json:"skip_rows"`
+	DateFormat       string            `json:"date_format"`
+	DecimalSeparator string            `json:"decimal_separator"`
+	Columns          map[string]int    `json:"columns"`
+	Headers          map[string]string `json:"headers"`
+}
+
+// credit line of a bank statement
+type BancoMovimiento struct {
+	Linea       int     `json:"linea"`
+	Fecha       string  `json:"fecha"`
+	Referencia  string  `json:"referencia"`
+	Descripcion string  `json:"descripcion"`
+	Monto       float64 `json:"monto"`
+}
+
+// user request to import a statement, the file is sent on the form
+type BancoImportReq struct {
+	Layout string `form:"layout" binding:"required,min=2,max=50"`
+}
+
+type BancoImportResult struct {
+	ExtractoId  string `json:"extracto_id"`
+	Archivo     string `json:"archivo"`
+	Lineas      int    `json:"lineas"`
+	Conciliados int    `json:"conciliados"`
+	Revision    int    `json:"revision"`
+	SinMatch    int    `json:"sin_match"`
+	Duplicados  int    `json:"duplicados"`
+}
+
+// line of a statement that could not be reconciled automatically
+type BancoRevision struct {
+	Id            int64            `json:"id"`
+	CuentaBancoId int64            `json:"cuenta_banco_id"`
+	Movimiento    BancoMovimiento  `json:"movimiento"`
+	Candidatos    []BancoCandidato `json:"candidatos"`
+}
+
+// recibo pendiente that could be the payment of a line of the statement
+type BancoCandidato struct {
+	PaymentId  string  `json:"payment_id"`
+	CreatedAt  string  `json:"created_at"`
+	ClienteId  int64   `json:"cliente_id"`
+	Fecha      string  `json:"fecha"`
+	Referencia string  `json:"referencia"`
+	Monto      float64 `json:"monto"`
+	Motivo     string  `json:"motivo"`
+}
@@ -0,0 +1,38 @@
+package models
+
+// filters of the estado de cuenta, the dates are inclusive
+type EstadoCuentaReq struct {
+	Desde         string `form:"desde" json:"desde" binding:"omitempty,datetime=2006-01-02"`
+	Hasta         string `form:"hasta" json:"hasta" binding:"omitempty,datetime=2006-01-02"`
+	SuscripcionId int64  `form:"suscripcion_id" json:"suscripcion_id" binding:"omitempty,gte=1"`
+}
+
+type EstadoCuenta struct {
+	Nombre        string                    `json:"nombre"`
+	DocId         string                    `json:"docid"`
+	Desde         string                    `json:"desde"`
+	Hasta         string                    `json:"hasta"`
+	Suscripciones []EstadoCuentaSuscripcion `json:"suscripciones"`
+	DatosBesser   FacturaDatosBesser        `json:"datos_besser"`
+}
+
+// ledger of a suscripcion, SuscripcionId 0 has the movements without suscripcion
+type EstadoCuentaSuscripcion struct {
+	SuscripcionId int64                    `json:"suscripcion_id"`
+	Ncontrol      string                   `json:"ncontrol"`
+	SaldoInicial  Moneda                   `json:"saldo_inicial"`
+	SaldoFinal    Moneda                   `json:"saldo_final"`
+	Movimientos   []EstadoCuentaMovimiento `json:"movimientos"`
+}
+
+// the saldo is positive when it is in favor of the cliente
+type EstadoCuentaMovimiento struct {
+	Fecha     string `json:"fecha"`
+	Tipo      string `json:"tipo"`
+	Id        string `json:"id"`
+	Ncontrol  string `json:"ncontrol"`
+	Documento string `json:"documento"`
+	Debe      Moneda `json:"debe"`
+	Haber     Moneda `json:"haber"`
+	Saldo     Moneda `json:"saldo"`
+}
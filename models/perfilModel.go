@@ -0,0 +1,28 @@
+package models
+
+// user request to change the profile, only the fields sent are changed. A change of
+// correo or telefono needs the current password and the code of the second factor if enabled
+type PerfilUpdateReq struct {
+	Direccion string   `json:"direccion" binding:"omitempty,min=5,max=250"`
+	Telefono  []string `json:"telefono" binding:"omitempty,min=1,max=3,dive,min=7,max=20"`
+	Correo    []string `json:"correo" binding:"omitempty,min=1,max=3,dive,email,max=100"`
+	Password  string   `json:"password" binding:"omitempty,max=30"`
+	Code      string   `json:"code" binding:"omitempty,min=6,max=9"`
+}
+
+// user request to confirm the change of correo or telefono with the code received
+type PerfilVerifyReq struct {
+	Campo string `json:"campo" binding:"required,oneof=correo telefono"`
+	Code  string `json:"code" binding:"required,number,min=6,max=6"`
+}
+
+// contact change waiting for the code sent to the new value
+type PerfilPendiente struct {
+	Campo   string `json:"campo"`
+	Destino string `json:"destino"`
+}
+
+type PerfilResponse struct {
+	Profile    UserProfile       `json:"profile"`
+	Pendientes []PerfilPendiente `json:"pendientes,omitempty"`
+}
@@ -0,0 +1,37 @@
+package models
+
+import "github.com/golang-jwt/jwt/v5"
+
+// data of a printable comprobante (payment or transfer)
+type ReceiptPdf struct {
+	Titulo    string
+	Ncontrol  string
+	Fecha     string
+	Lineas    []ReceiptLinea
+	Detalle   []ReceiptLinea
+	Total     Moneda
+	Empresa   FacturaDatosBesser
+	VerifyUrl string
+}
+
+type ReceiptLinea struct {
+	Etiqueta string
+	Valor    string
+}
+
+// claims of the token inside the QR of a comprobante, it does not expire
+type ReceiptClaims struct {
+	Tipo      string `json:"tipo"`
+	Id        string `json:"id"`
+	CreatedAt string `json:"created_at"`
+	jwt.RegisteredClaims
+}
+
+// public data of a comprobante to check that it is authentic
+type ReceiptVerify struct {
+	Tipo     string `json:"tipo"`
+	Ncontrol string `json:"ncontrol"`
+	Fecha    string `json:"fecha"`
+	Estatus  string `json:"estatus"`
+	Monto    Moneda `json:"monto"`
+}
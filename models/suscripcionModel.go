@@ -1,20 +1,62 @@
 package models
 
 type Suscripcion struct {
-	Id                   int64   `json:"id"`
-	Ncontrol             string  `json:"ncontrol"`
-	Zona                 string  `json:"zona"`
-	TipoConexion         string  `json:"tipo_conexion"`
-	TipoServicio         string  `json:"tipo_servicio"`
-	TipoServicioAcronimo string  `json:"tipo_servicio_acronimo"`
-	SpeedValue           float64 `json:"speed_value"`
-	SpeedUnit            string  `json:"speed_unit"`
-	Gps                  string  `json:"coordenadas"`
-	Estatus              bool    `json:"estatus"`
-	ExcluirPago          bool    `json:"excluir_pago"`
-	Renewal              string  `json:"renewal_day"`
-	Saldo                Moneda  `json:"saldo" binding:"omitempty"`
-	Costo                Moneda  `json:"costo"`
+	Id                   int64                  `json:"id"`
+	Ncontrol             string                 `json:"ncontrol"`
+	Zona                 string                 `json:"zona"`
+	TipoConexion         string                 `json:"tipo_conexion"`
+	TipoServicio         string                 `json:"tipo_servicio"`
+	TipoServicioAcronimo string                 `json:"tipo_servicio_acronimo"`
+	SpeedValue           float64                `json:"speed_value"`
+	SpeedUnit            string                 `json:"speed_unit"`
+	Gps                  string                 `json:"coordenadas"`
+	Estatus              bool                   `json:"estatus"`
+	ExcluirPago          bool                   `json:"excluir_pago"`
+	Autopago             bool                   `json:"autopago"`
+	Renewal              string                 `json:"renewal_day"`
+	Saldo                Moneda                 `json:"saldo" binding:"omitempty"`
+	Costo                Moneda                 `json:"costo"`
+	Calendario           *SuscripcionCalendario `json:"calendario,omitempty"`
+}
+
+// full view of a contract for the cliente and the support staff, the lists have the latest records.
+// There is no history of estatus, the back office only keeps the current one
+type SuscripcionDetalle struct {
+	Suscripcion
+	ClienteId        string           `json:"cliente_id"`
+	Plan             string           `json:"plan"`
+	FechaInstalacion string           `json:"fecha_instalacion"`
+	SaldoDetalle     SuscripcionSaldo `json:"saldo_detalle"`
+	Facturas         []FacturaList    `json:"facturas"`
+	Pagos            []PaymentList    `json:"pagos"`
+	Retenciones      []RetencionList  `json:"retenciones"`
+}
+
+// saldo of the suscripcion from venta.get_saldo, PorConfirmar are the payments not processed yet
+type SuscripcionSaldo struct {
+	Procesado    Moneda `json:"procesado"`
+	Total        Moneda `json:"total"`
+	PorConfirmar Moneda `json:"por_confirmar"`
+}
+
+type SuscripcionReqId struct {
+	Id string `form:"suscripcion_id" json:"suscripcion_id" binding:"required,number,min=1"`
+}
+
+// billing calendar of the suscripcion, MontoProyectado is the costo with the tasa_cambio of today
+type SuscripcionCalendario struct {
+	FechaInstalacion  string                  `json:"fecha_instalacion"`
+	DiaRenovacion     int                     `json:"dia_renovacion"`
+	ProximaRenovacion string                  `json:"proxima_renovacion"`
+	DiasRestantes     int                     `json:"dias_restantes"`
+	MontoProyectado   Moneda                  `json:"monto_proyectado"`
+	TasaCambio        float64                 `json:"tasa_cambio"`
+	Proyeccion        []SuscripcionProyeccion `json:"proyeccion"`
+}
+
+type SuscripcionProyeccion struct {
+	Fecha string `json:"fecha"`
+	Monto Moneda `json:"monto"`
 }
 
 type SuscripcionShortInfo struct {
@@ -27,3 +69,16 @@ type SuscripcionShortInfo struct {
 	SpeedValue    float64 `json:"speed_value"`
 	SpeedUnit     string  `json:"speed_unit"`
 }
+
+// user request to turn on/off the auto-pay of a suscripcion with the saldo available
+type SuscripcionAutopagoReq struct {
+	SuscripcionId string `json:"suscripcion_id" binding:"required,number,min=1"`
+	Activo        *bool  `json:"activo" binding:"required"`
+}
+
+// suscripcion to be paid by the task autopay_suscripciones
+type SuscripcionAutopagoCron struct {
+	SuscripcionId string
+	ClienteId     string
+	Ncontrol      string
+}
@@ -89,3 +89,10 @@ func GetDatosBesser() FacturaDatosBesser {
 		Email:     "atcliente@bessersolutions.com",
 	}
 }
+
+// response saved for an idempotency key, replayed on the retries
+type IdempotencyRecord struct {
+	Estatus        string
+	ResponseStatus int
+	ResponseBody   string
+}
@@ -58,6 +58,8 @@ func errorToJson(fieldError validator.FieldError, c *gin.Context) string {
 		return ginI18n.MustGetMessage(c, "veEmail")
 	case "uuid":
 		return ginI18n.MustGetMessage(c, "veUuid")
+	case "oneof":
+		return ginI18n.MustGetMessage(c, "veOneOf") + " " + fieldError.Param()
 	}
 	return fieldError.Error() // default error
 }
@@ -0,0 +1,124 @@
+package repo
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	ginI18n "github.com/gin-contrib/i18n"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+	"ired.com/micuenta/models"
+	"ired.com/micuenta/utils"
+)
+
+// password of the account was migrated from the docid and the change was not done on time
+func passwdDeadlineExpired(user *models.UserResponse) bool {
+	return user.Credentials.ChangePasswd && user.Credentials.PasswdDeadline != nil &&
+		time.Now().After(*user.Credentials.PasswdDeadline)
+}
+
+// account has not usable password and needs the activation code
+func activationPending(user *models.UserResponse) bool {
+	return !user.Credentials.Passwd.Valid || user.Credentials.Passwd.String == "" || passwdDeadlineExpired(user)
+}
+
+// send the activation code to the emails (or mobile phone) on the cliente record, the response is the
+// same if the docid does not exist or is already active to not expose the accounts
+func ActivationReq(c *gin.Context, db models.ConnDb, userReq models.ActivationRequest) (int, error) {
+	errType, err := checkRateLimit(c, db, "activation",
+		rateLimit{Llave: "docid:" + userReq.Username, Max: 3, Ventana: time.Hour},
+		rateLimit{Llave: "ip:" + c.ClientIP(), Max: 10, Ventana: time.Hour},
+	)
+	if err != nil {
+		return errType, err
+	}
+
+	user, err := getUser(db, "docid", userReq.Username)
+	if err != nil {
+		if err.Error() == "recordDontExist" {
+			return http.StatusOK, nil
+		}
+		return http.StatusInternalServerError, errors.New("errorInternal")
+	}
+	if !activationPending(user) {
+		return http.StatusOK, nil
+	}
+	if userReq.Canal == "sms" {
+		// a failure is only logged (on sendOtpSms), an error would tell that the account exists
+		sendOtpSms(db, user, "activation", "tu codigo para activar MiCuenta es")
+		return http.StatusOK, nil
+	}
+
+	if len(user.Profile.Correo) == 0 {
+		utils.Logline("cliente without email for activation", user.Profile.Id)
+		return http.StatusOK, nil
+	}
+
+	code, err := createOtp(db, user.Profile.Id, "activation", "email", user.Profile.Correo[0], nil)
+	if err != nil {
+		return http.StatusInternalServerError, errors.New("errorInternal")
+	}
+
+	// a failure is only logged, an error would tell that the account exists
+	err = sendOtpEmail(user.Profile.Correo, ginI18n.MustGetMessage(c, "titleActivation"), "Activa tu cuenta",
+		"Para activar tu cuenta de MiCuenta y crear tu contraseña utiliza el siguiente codigo:", code)
+	if err != nil {
+		utils.Logline("error sending the email", err)
+	}
+
+	return http.StatusOK, nil
+}
+
+// validate the activation code and save the password chosen by the cliente
+func ActivationSend(c *gin.Context, db models.ConnDb, userReq models.ActivationSend) (int, error) {
+	user, err := getUser(db, "docid", userReq.Username)
+	if err != nil {
+		return http.StatusUnauthorized, errors.New("otpInvalid")
+	}
+	if !activationPending(user) {
+		return http.StatusUnauthorized, errors.New("otpInvalid")
+	}
+
+	otp, errType, err := checkOtp(db, user.Profile.Id, "activation", userReq.Code)
+	if err != nil {
+		return errType, err
+	}
+
+	hash, _ := bcrypt.GenerateFromPassword([]byte(userReq.Password), 10)
+
+	// the code is consumed with the save of the password, it can be used only once
+	// and it is not lost if the password could not be saved
+	tx, err := db.ConnPgsql.Begin(db.Ctx)
+	if err != nil {
+		utils.Logline("error starting transaction of activation", err)
+		return http.StatusInternalServerError, errors.New("errorInternal")
+	}
+	defer tx.Rollback(db.Ctx)
+
+	consumed, err := consumeOtp(db, tx, otp.Id)
+	if err != nil {
+		return http.StatusInternalServerError, errors.New("errorInternal")
+	}
+	if !consumed {
+		return http.StatusUnauthorized, errors.New("otpInvalid")
+	}
+
+	query := `UPDATE publico.cliente SET passwd=$1, change_passwd=false, info=COALESCE(info, '{}') - 'passwd_deadline' WHERE empresa_id=1 AND id=$2`
+	if _, err := tx.Exec(db.Ctx, query, string(hash), user.Profile.Id); err != nil {
+		utils.Logline("error updating passwd of cliente", user.Profile.Id, err)
+		return http.StatusInternalServerError, errors.New("errorInternal")
+	}
+
+	if err := tx.Commit(db.Ctx); err != nil {
+		utils.Logline("error committing activation", user.Profile.Id, err)
+		return http.StatusInternalServerError, errors.New("errorInternal")
+	}
+
+	clearLoginAttempts(db, userReq.Username)
+	revokeClienteTokens(db, user.Profile.Id, "activation")
+	revokeClienteSessions(db, user.Profile.Id, "")
+	logAuthEvent(c, db, user.Profile.Id, "account_activated", nil)
+
+	return http.StatusOK, nil
+}
@@ -0,0 +1,27 @@
+package repo
+
+import (
+	"github.com/gin-gonic/gin"
+	"ired.com/micuenta/models"
+	"ired.com/micuenta/utils"
+)
+
+// save a security event of the cliente, c could be nil when called from a cron
+func logAuthEvent(c *gin.Context, db models.ConnDb, clienteId any, evento string, info map[string]any) {
+	var ip, userAgent string
+	if c != nil {
+		ip = c.ClientIP()
+		userAgent = c.Request.UserAgent()
+	}
+	if info == nil {
+		info = map[string]any{}
+	}
+
+	query := `INSERT INTO publico.cliente_auth_event (empresa_id, cliente_id, evento, ip, user_agent, info) VALUES (1, $1, $2, $3, $4, $5)`
+	if _, err := db.ConnPgsql.Exec(db.Ctx, query, clienteId, evento, ip, userAgent, info); err != nil {
+		utils.Logline("error saving cliente_auth_event", clienteId, evento, err)
+		return
+	}
+
+	utils.Logline("auth event", evento, clienteId, ip)
+}
@@ -6,7 +6,6 @@ import (
 	"net/http"
 	"os"
 	"strconv"
-	"strings"
 	"time"
 
 	ginI18n "github.com/gin-contrib/i18n"
@@ -22,12 +21,13 @@ import (
 
 func getUser(db models.ConnDb, fieldSearch string, value string) (*models.UserResponse, error) {
 	var user models.UserResponse
-	query := `SELECT id, nombre, direccion, telefono, correo, docid, COALESCE(passwd, '') as passwd, change_passwd
+	query := `SELECT id, nombre, direccion, telefono, correo, docid, COALESCE(passwd, '') as passwd, change_passwd,
+			(info->>'passwd_deadline')::timestamptz as passwd_deadline
 		FROM publico.cliente WHERE ` + fieldSearch + ` = $1 AND activo=true
 		LIMIT 1`
 
 	err := db.ConnPgsql.QueryRow(db.Ctx, query, value).Scan(&user.Profile.Id, &user.Profile.Nombre, &user.Profile.Direccion, &user.Profile.Telefono, &user.Profile.Correo,
-		&user.Profile.Username, &user.Credentials.Passwd, &user.Credentials.ChangePasswd)
+		&user.Profile.Username, &user.Credentials.Passwd, &user.Credentials.ChangePasswd, &user.Credentials.PasswdDeadline)
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			return nil, errors.New("recordDontExist")
@@ -40,7 +40,7 @@ func getUser(db models.ConnDb, fieldSearch string, value string) (*models.UserRe
 }
 
 func updateUser(db models.ConnDb, fieldUpdate string, value string, userId string) error {
-	query := `UPDATE publico.cliente SET ` + fieldUpdate + ` = $1, change_passwd=false WHERE empresa_id=1 AND id=$2`
+	query := `UPDATE publico.cliente SET ` + fieldUpdate + ` = $1, change_passwd=false, info=COALESCE(info, '{}') - 'passwd_deadline' WHERE empresa_id=1 AND id=$2`
 
 	_, err := db.ConnPgsql.Exec(db.Ctx, query, value, userId)
 	if err != nil {
@@ -51,59 +51,102 @@ func updateUser(db models.ConnDb, fieldUpdate string, value string, userId strin
 	return nil
 }
 
-func Login(c *gin.Context, db models.ConnDb, userReq models.UserRequest) (*models.UserResponse, error) {
-	// look up requested user
-	user, err := getUser(db, "docid", userReq.Username)
-	if err != nil {
-		return nil, errors.New("invalidLogin")
+// hash compared when the account does not exist, so the answer takes the same time
+var dummyPasswdHash, _ = bcrypt.GenerateFromPassword([]byte("micuenta-dummy-passwd"), 10)
+
+func Login(c *gin.Context, db models.ConnDb, userReq models.UserRequest) (*models.UserResponse, *models.TwoFactorChallenge, int, error) {
+	// the attempt is counted before comparing the password, it is given back when the login is right.
+	// It stops if the docid or ip is locked or must wait
+	if errType, err := reserveLoginAttempt(c, db, userReq.Username); err != nil {
+		return nil, nil, errType, err
 	}
 
-	// handle null value of passwd column
-	passwordBytes := []byte{}
-	if user.Credentials.Passwd.Valid {
-		passwordBytes = []byte(user.Credentials.Passwd.String)
+	// look up requested user, an unknown docid and an account without password (it must be activated
+	// with a code first) get the same answer and time of a wrong password to not expose the accounts
+	user, err := getUser(db, "docid", userReq.Username)
+	if err != nil || !user.Credentials.Passwd.Valid || user.Credentials.Passwd.String == "" {
+		bcrypt.CompareHashAndPassword(dummyPasswdHash, []byte(userReq.Password))
+		return nil, nil, http.StatusUnauthorized, errors.New("invalidLogin")
 	}
+	passwordBytes := []byte(user.Credentials.Passwd.String)
 
 	// compare passwd hash from db to user pass hash
 	err = bcrypt.CompareHashAndPassword([]byte(passwordBytes), []byte(userReq.Password))
 	if err != nil {
-		return nil, errors.New("invalidLogin")
+		return nil, nil, http.StatusUnauthorized, errors.New("invalidLogin")
+	}
+	loginAttemptSucceeded(c, db, userReq.Username)
+
+	// migrated passwords that were not changed on time can not be used anymore, the
+	// password was right so the cliente can be told to activate the account
+	if passwdDeadlineExpired(user) {
+		return nil, nil, http.StatusForbidden, errors.New("activationRequired")
 	}
 
 	//check if remember is true or not
 	remember, err := strconv.ParseBool(userReq.Remember)
 	if err != nil {
 		utils.Logline("error parsing boolean remember on login", err)
-		return nil, errors.New("invalidLogin")
+		return nil, nil, http.StatusUnauthorized, errors.New("invalidLogin")
+	}
+
+	// with second factor enabled the session is started after the code is verified
+	twoFactor, err := getTwoFactor(db, user.Profile.Id)
+	if err != nil {
+		return nil, nil, http.StatusUnauthorized, errors.New("invalidLogin")
+	}
+	if twoFactor != nil && twoFactor.Enabled {
+		challenge, err := generatePreAuthToken(user.Profile.Id, remember, userReq.DeviceInfo)
+		if err != nil {
+			return nil, nil, http.StatusUnauthorized, errors.New("invalidLogin")
+		}
+		return nil, challenge, http.StatusOK, nil
 	}
 
+	if err := startSession(c, db, user, remember, &userReq.DeviceInfo); err != nil {
+		return nil, nil, http.StatusUnauthorized, errors.New("invalidLogin")
+	}
+
+	return user, nil, http.StatusOK, nil
+}
+
+// generate the tokens of the user and create the session, last step of the login
+func startSession(c *gin.Context, db models.ConnDb, user *models.UserResponse, remember bool, deviceInfo *models.UserDeviceInfo) error {
 	// generate authorization token
 	userToken, err := GenerateAuthTokens(user.Profile.Id, remember, user.Credentials.ChangePasswd)
 	if err != nil {
 		utils.Logline("error creating tokens:", err)
-		return nil, errors.New("invalidLogin")
+		return err
 	}
 	user.Tokens = *userToken
 
-	// create session and save refresh token
+	// create session and save refresh token, every login starts a new rotation family
+	familyId := utils.GenerateUUID()
 	session, err := saveSession(c,
 		&sessionInternal{
 			UserId:       user.Profile.Id,
 			ChangePasswd: user.Credentials.ChangePasswd,
 			RefreshToken: userToken.Refresh,
+			AuthJti:      userToken.AuthId,
 			ExpiresAt:    userToken.RefreshExpiresAt,
 			Remember:     remember,
+			FamilyId:     familyId,
 		})
 	if err != nil {
 		utils.Logline("error saving the session", err)
-		return nil, errors.New("invalidLogin")
+		return err
+	}
+
+	err = insertRefreshToken(db, db.ConnPgsql, user.Profile.Id, familyId, session.ID(), userToken.Refresh, userToken.RefreshExpiresAt)
+	if err != nil {
+		return err
 	}
 
 	// associate session with client_id and save also deviceInfo
-	err = updateClienteOnSession(db, session.ID(), user.Profile.Id, &userReq.DeviceInfo)
+	err = updateClienteOnSession(db, session.ID(), user.Profile.Id, deviceInfo)
 	if err != nil {
 		utils.Logline("error update the cliente data on the session", err)
-		return nil, errors.New("invalidLogin")
+		return err
 	}
 
 	// create cookie for authToken
@@ -111,23 +154,26 @@ func Login(c *gin.Context, db models.ConnDb, userReq models.UserRequest) (*model
 	// c.SetSameSite(http.SameSiteLaxMode)
 	// c.SetCookie("auth_token", userToken.Auth, userToken.AuthMaxAge, "/", os.Getenv("DOMAIN"), secureCookie, false)
 
-	return user, nil
+	return nil
 }
 
-func Logout(c *gin.Context) {
+func Logout(c *gin.Context, db models.ConnDb) {
 	session := sessions.Default(c)
+	// the refresh tokens of the session can not be used anymore
+	if session.ID() != "" {
+		revokeSessionRefreshTokens(db, []string{session.ID()})
+	}
+	// neither the last access token given to the session
+	userId, _ := session.Get("user_id").(string)
+	authJti, _ := session.Get("auth_jti").(string)
+	if userId != "" && authJti != "" {
+		revokeToken(db, userId, authJti, "logout")
+	}
 	session.Options(sessions.Options{MaxAge: -1})
 	session.Save()
 }
 
 func RefreshToken(c *gin.Context, db models.ConnDb) (*models.UserToken, int, error) {
-	// validate if session exist and if not stop refresh process
-	session := sessions.Default(c)
-	refreshTokenSession := session.Get("refresh_token")
-	if refreshTokenSession == nil {
-		return nil, http.StatusUnauthorized, errors.New("tokenMissing")
-	}
-
 	// get the token from the header
 	refreshTokenHeader := c.GetHeader("Authorization")
 	if len(refreshTokenHeader) < 8 || refreshTokenHeader[:7] != "Bearer " {
@@ -135,8 +181,36 @@ func RefreshToken(c *gin.Context, db models.ConnDb) (*models.UserToken, int, err
 	}
 	refreshTokenHeader = refreshTokenHeader[7:]
 
+	// validate if session exist and if not stop refresh process, a retired
+	// token presented without the session is also a replay
+	session := sessions.Default(c)
+	refreshTokenSession := session.Get("refresh_token")
+	if refreshTokenSession == nil {
+		if _, errType, err := detectRefreshTokenReuse(c, db, refreshTokenHeader); err != nil {
+			return nil, errType, err
+		}
+		return nil, http.StatusUnauthorized, errors.New("tokenMissing")
+	}
+
 	// validate if token header is the same as the session - CSRF prevention
 	if refreshTokenHeader != refreshTokenSession {
+		reused, errType, err := detectRefreshTokenReuse(c, db, refreshTokenHeader)
+		if err != nil {
+			return nil, errType, err
+		}
+		if reused {
+			Logout(c, db)
+		}
+		return nil, http.StatusUnauthorized, errors.New("tokenInvalid")
+	}
+
+	// only a refresh token signed by us is accepted, an access token can not be used to refresh
+	refreshClaims := &models.Claims{}
+	token, err := utils.ParseJwt(refreshTokenHeader, refreshClaims)
+	if err != nil || !token.Valid {
+		return nil, http.StatusUnauthorized, errors.New("tokenExpired")
+	}
+	if refreshClaims.TokenType != models.TokenTypeRefresh {
 		return nil, http.StatusUnauthorized, errors.New("tokenInvalid")
 	}
 
@@ -161,14 +235,69 @@ func RefreshToken(c *gin.Context, db models.ConnDb) (*models.UserToken, int, err
 		return nil, http.StatusInternalServerError, errors.New("errorInternal")
 	}
 
+	// the rotation is one transaction: the token presented is locked and retired and the new one is
+	// inserted, so if any step fails the token presented is still active and the client can retry with
+	// it. The session is not part of the transaction, it is saved only after the commit
+	tx, err := db.ConnPgsql.Begin(db.Ctx)
+	if err != nil {
+		utils.Logline("error starting transaction of refresh token", err)
+		return nil, http.StatusInternalServerError, errors.New("errorInternal")
+	}
+	defer tx.Rollback(db.Ctx)
+
+	// sessions created before the rotation families existed start a new one here
+	familyId, ok := session.Get("family_id").(string)
+	if !ok || familyId == "" {
+		familyId = utils.GenerateUUID()
+	} else {
+		estatus, retiredAt, err := lockRefreshToken(db, tx, familyId, refreshTokenHeader)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				// the token of the session was never saved on the family, it is not a replay
+				utils.Logline("refresh token of the session not found on its family", familyId)
+				return nil, http.StatusUnauthorized, errors.New("tokenInvalid")
+			}
+			return nil, http.StatusInternalServerError, errors.New("errorInternal")
+		}
+		if estatus != "activo" {
+			// the lock must be released before revoking the family
+			tx.Rollback(db.Ctx)
+
+			// other request rotated the same token right now
+			if estatus == "retirado" && retiredAt != nil && time.Since(*retiredAt) < refreshReuseGrace {
+				return nil, http.StatusUnauthorized, errors.New("tokenInvalid")
+			}
+
+			// token was already rotated before or revoked
+			revokeRefreshFamily(c, db, familyId, userId.(string), "refresh_token_reuse")
+			Logout(c, db)
+			return nil, http.StatusUnauthorized, errors.New("tokenInvalid")
+		}
+		if err := retireRefreshToken(db, tx, familyId, refreshTokenHeader); err != nil {
+			return nil, http.StatusInternalServerError, errors.New("errorInternal")
+		}
+	}
+
+	err = insertRefreshToken(db, tx, userId.(string), familyId, session.ID(), userToken.Refresh, userToken.RefreshExpiresAt)
+	if err != nil {
+		return nil, http.StatusInternalServerError, errors.New("errorInternal")
+	}
+
+	if err := tx.Commit(db.Ctx); err != nil {
+		utils.Logline("error commiting refresh token", err)
+		return nil, http.StatusInternalServerError, errors.New("errorInternal")
+	}
+
 	// update session
 	_, err = saveSession(c,
 		&sessionInternal{
 			UserId:       userId.(string),
 			RefreshToken: userToken.Refresh,
+			AuthJti:      userToken.AuthId,
 			ExpiresAt:    userToken.RefreshExpiresAt,
 			Remember:     remember,
 			ChangePasswd: changePasswd,
+			FamilyId:     familyId,
 		})
 	if err != nil {
 		utils.Logline("error updating session tokens:", err)
@@ -178,45 +307,32 @@ func RefreshToken(c *gin.Context, db models.ConnDb) (*models.UserToken, int, err
 	return userToken, http.StatusOK, nil
 }
 
-func ForgotPasswordReq(c *gin.Context, db models.ConnDb, userReq models.ForgotPasswordRequest) (string, int, error) {
-	// look up requested user
-	user, err := getUser(db, "docid", userReq.Username)
+func ForgotPasswordReq(c *gin.Context, db models.ConnDb, userReq models.ForgotPasswordRequest) (int, error) {
+	// throttle by account and ip, counted for any docid so it does not expose the accounts
+	errType, err := checkRateLimit(c, db, "forgot_password",
+		rateLimit{Llave: "docid:" + userReq.Username, Max: 3, Ventana: time.Hour},
+		rateLimit{Llave: "ip:" + c.ClientIP(), Max: 10, Ventana: time.Hour},
+	)
 	if err != nil {
-		return "", http.StatusBadRequest, errors.New("errorGetData")
+		return errType, err
 	}
 
-	// get MaxAge for the authLink to work
-	linkMaxAge, err := strconv.Atoi(os.Getenv("LINK_MAX_AGE"))
+	// look up requested user, the response is the same if it does not exist
+	user, err := getUser(db, "docid", userReq.Username)
 	if err != nil {
-		utils.Logline("error parsing AUTH_MAX_AGE", err)
-		return "", http.StatusInternalServerError, errors.New("errorInternal")
-	}
-	linkExpirationTime := time.Now().Add(time.Duration(linkMaxAge) * time.Second)
-
-	// create claims for auth token
-	claims := &models.Claims{
-		UserId: userReq.Username,
-		RegisteredClaims: jwt.RegisteredClaims{
-			Issuer:    "micuenta",
-			Subject:   userReq.Username,
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			ExpiresAt: jwt.NewNumericDate(linkExpirationTime),
-		},
+		if err.Error() == "recordDontExist" {
+			return http.StatusOK, nil
+		}
+		return http.StatusInternalServerError, errors.New("errorInternal")
 	}
-	// Sign and get the complete encoded authorization token as a string using the secret
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	TokenString, err := token.SignedString([]byte(os.Getenv("LINK_SECRET")))
-	if err != nil {
-		utils.Logline("error signing auth token", err)
-		return "", http.StatusInternalServerError, errors.New("errorInternal")
+	if len(user.Profile.Correo) == 0 {
+		utils.Logline("cliente without email for password reset", user.Profile.Id)
+		return http.StatusOK, nil
 	}
 
-	// save token to user record
-	query := `UPDATE publico.cliente SET info=jsonb_set(info,'{link_email_token}', '"` + TokenString + `"') WHERE id = $1`
-	_, err = db.ConnPgsql.Exec(db.Ctx, query, user.Profile.Id)
+	TokenString, err := generateResetToken(c, db, user, "email")
 	if err != nil {
-		utils.Logline("error updating user", err)
-		return "", http.StatusInternalServerError, errors.New("errorInternal")
+		return http.StatusInternalServerError, errors.New("errorInternal")
 	}
 
 	linkTokenString := `?username=` + user.Profile.Username + `&uid=` + TokenString
@@ -260,45 +376,72 @@ func ForgotPasswordReq(c *gin.Context, db models.ConnDb, userReq models.ForgotPa
 			</body>
 		</html>
 	`
-	// send email with hashLink
-	err = utils.SendEmail(user.Profile.Correo, ginI18n.MustGetMessage(c, "titleChangePassword"), bodyEmail)
-	if err != nil {
-		utils.Logline("error sending the email", err)
-		return "", http.StatusInternalServerError, errors.New("errorEmail")
-	}
+	// send email with hashLink out of the request, so the time and the response are the same
+	// of an unknown docid. A failure is only logged
+	subject := ginI18n.MustGetMessage(c, "titleChangePassword")
+	go func(clienteId string, correo []string) {
+		if err := utils.SendEmail(correo, subject, bodyEmail); err != nil {
+			utils.Logline("error sending the email", clienteId, err)
+		}
+	}(user.Profile.Id, user.Profile.Correo)
 
-	return strings.Join(user.Profile.Correo, ","), http.StatusOK, nil
+	return http.StatusOK, nil
 }
 
 func ForgotPasswordSend(c *gin.Context, db models.ConnDb, userReq models.ForgotPasswordSend) (int, error) {
-	// save token to user record
-	query := `SELECT id, info->>'link_email_token' as  token FROM publico.cliente WHERE docid=$1 AND info->>'link_email_token'=$2`
-
-	var userId string
-	var tokenString string
-	err := db.ConnPgsql.QueryRow(db.Ctx, query, userReq.Username, userReq.Token).Scan(&userId, &tokenString)
-	if err != nil {
-		if err == pgx.ErrNoRows {
-			return http.StatusUnauthorized, errors.New("tokenInvalid")
-		}
-		utils.Logline("error getting user", err)
-		return http.StatusInternalServerError, errors.New("errorInternal")
-	}
-
+	// validate the token and that it was issued for the docid requested
 	claims := &models.Claims{}
-	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+	token, err := jwt.ParseWithClaims(userReq.Token, claims, func(token *jwt.Token) (interface{}, error) {
 		return []byte(os.Getenv("LINK_SECRET")), nil
-	})
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
 	if err != nil || !token.Valid {
 		return http.StatusUnauthorized, errors.New("tokenExpired")
 	}
+	if claims.Subject != userReq.Username || claims.UserId != userReq.Username {
+		return http.StatusUnauthorized, errors.New("tokenInvalid")
+	}
+
+	user, err := getUser(db, "docid", userReq.Username)
+	if err != nil {
+		return http.StatusUnauthorized, errors.New("tokenInvalid")
+	}
 
 	hash, _ := bcrypt.GenerateFromPassword([]byte(userReq.Password), 10)
-	query = `UPDATE publico.cliente SET change_passwd=false, info=jsonb_set(info,'{link_email_token}', '""'), passwd=$1 WHERE id=$2`
-	_, err = db.ConnPgsql.Exec(db.Ctx, query, string(hash), userId)
+
+	// the token is consumed with the change of the password, it can be used only once
+	// and it is not lost if the password could not be saved
+	tx, err := db.ConnPgsql.Begin(db.Ctx)
 	if err != nil {
+		utils.Logline("error starting transaction of password reset", err)
 		return http.StatusInternalServerError, errors.New("errorInternal")
 	}
+	defer tx.Rollback(db.Ctx)
+
+	consumed, err := consumeResetToken(db, tx, user.Profile.Id, userReq.Token)
+	if err != nil {
+		return http.StatusInternalServerError, errors.New("errorInternal")
+	}
+	if !consumed {
+		return http.StatusUnauthorized, errors.New("tokenInvalid")
+	}
+
+	query := `UPDATE publico.cliente SET change_passwd=false, info=COALESCE(info, '{}') - 'link_email_token' - 'passwd_deadline', passwd=$1 WHERE id=$2`
+	_, err = tx.Exec(db.Ctx, query, string(hash), user.Profile.Id)
+	if err != nil {
+		utils.Logline("error updating passwd of cliente", user.Profile.Id, err)
+		return http.StatusInternalServerError, errors.New("errorInternal")
+	}
+
+	if err := tx.Commit(db.Ctx); err != nil {
+		utils.Logline("error committing password reset", user.Profile.Id, err)
+		return http.StatusInternalServerError, errors.New("errorInternal")
+	}
+
+	// a password reset also unlocks the account and invalidates the access tokens
+	clearLoginAttempts(db, userReq.Username)
+	revokeClienteTokens(db, user.Profile.Id, "password_reset")
+	revokeClienteSessions(db, user.Profile.Id, "")
+	logAuthEvent(c, db, user.Profile.Id, "password_reset", nil)
 
 	return http.StatusOK, nil
 }
@@ -309,6 +452,8 @@ type sessionInternal struct {
 	RefreshToken string
 	ExpiresAt    string
 	Remember     bool
+	FamilyId     string
+	AuthJti      string
 }
 
 func saveSession(c *gin.Context, sessionData *sessionInternal) (sessions.Session, error) {
@@ -318,6 +463,8 @@ func saveSession(c *gin.Context, sessionData *sessionInternal) (sessions.Session
 	session.Set("refresh_token", sessionData.RefreshToken)
 	session.Set("remember", sessionData.Remember)
 	session.Set("exp", sessionData.ExpiresAt)
+	session.Set("family_id", sessionData.FamilyId)
+	session.Set("auth_jti", sessionData.AuthJti)
 	if sessionData.Remember {
 		// use this maxAge for refresh token and session if remember is true
 		maxAgeRefreshRemember, err := strconv.Atoi(os.Getenv("REFRESH_MAX_AGE_REMEMBER"))
@@ -351,6 +498,10 @@ func ChangePassword(c *gin.Context, db models.ConnDb, userReq models.UserChangeP
 		return http.StatusInternalServerError, errors.New("errorInternal")
 	}
 
+	// access tokens and the other sessions opened with the old password can not be used anymore
+	revokeClienteTokens(db, user.Profile.Id, "password_change")
+	revokeClienteSessions(db, user.Profile.Id, session.ID())
+
 	session.Set("change_passwd", false)
 	err = session.Save()
 	if err != nil {
@@ -372,20 +523,22 @@ func GenerateAuthTokens(userId string, remember bool, changePassword bool) (*mod
 	}
 	authExpirationTime := time.Now().Add(time.Duration(authMaxAge) * time.Second)
 
-	// create claims for auth token
+	// create claims for auth token, jti is used to revoke it
+	authId := utils.GenerateUUID()
 	claims := &models.Claims{
 		UserId:       userId,
 		ChangePasswd: changePassword,
+		TokenType:    models.TokenTypeAccess,
 		RegisteredClaims: jwt.RegisteredClaims{
 			Issuer:    "micuenta",
 			Subject:   userId,
+			ID:        authId,
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			ExpiresAt: jwt.NewNumericDate(authExpirationTime),
 		},
 	}
-	// Sign and get the complete encoded authorization token as a string using the secret
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	authTokenString, err := token.SignedString([]byte(os.Getenv("SECRET")))
+	// Sign and get the complete encoded authorization token as a string using the current key
+	authTokenString, err := utils.SignJwt(claims)
 	if err != nil {
 		utils.Logline("error signing auth token", err)
 		return nil, err
@@ -412,16 +565,17 @@ func GenerateAuthTokens(userId string, remember bool, changePassword bool) (*mod
 	claims = &models.Claims{
 		UserId:       userId,
 		ChangePasswd: changePassword,
+		TokenType:    models.TokenTypeRefresh,
 		RegisteredClaims: jwt.RegisteredClaims{
 			Issuer:    "micuenta",
 			Subject:   userId,
+			ID:        utils.GenerateUUID(),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			ExpiresAt: jwt.NewNumericDate(refreshExpirationTime),
 		},
 	}
-	// Sign and get the complete encoded authorization token as a string using the secret
-	token = jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	refreshTokenString, err := token.SignedString([]byte(os.Getenv("SECRET")))
+	// Sign and get the complete encoded refresh token as a string using the current key
+	refreshTokenString, err := utils.SignJwt(claims)
 	if err != nil {
 		utils.Logline("error signing refresh token", err)
 		return nil, err
@@ -429,6 +583,7 @@ func GenerateAuthTokens(userId string, remember bool, changePassword bool) (*mod
 
 	return &models.UserToken{
 		Auth:             authTokenString,
+		AuthId:           authId,
 		AuthMaxAge:       authMaxAge,
 		AuthExpiresAt:    authExpirationTime.Format(time.RFC3339),
 		Refresh:          refreshTokenString,
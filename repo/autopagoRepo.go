@@ -0,0 +1,380 @@
+package repo
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"ired.com/micuenta/models"
+	"ired.com/micuenta/utils"
+)
+
+// turn on/off the auto-pay of a suscripcion of the cliente, the suscripciones excluded
+// from the payments (convenio) can not use it
+func SetAutopago(db models.ConnDb, clienteId string, autopagoReq models.SuscripcionAutopagoReq) (*models.Suscripcion, int, error) {
+	var excluirPago bool
+	query := `SELECT COALESCE((info->>'convenio')::boolean, false) FROM administracion.suscripcion WHERE cliente_id=$1 AND id=$2`
+	if err := db.ConnPgsql.QueryRow(db.Ctx, query, clienteId, autopagoReq.SuscripcionId).Scan(&excluirPago); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, http.StatusBadRequest, errors.New("veSuscripcion")
+		}
+		utils.Logline("error getting suscripcion for autopago", clienteId, autopagoReq, err)
+		return nil, http.StatusBadRequest, errors.New("errorGetData")
+	}
+	if excluirPago && *autopagoReq.Activo {
+		return nil, http.StatusBadRequest, errors.New("autopayExcluded")
+	}
+
+	query = `INSERT INTO publico.suscripcion_autopago (empresa_id, suscripcion_id, cliente_id, activo)
+		VALUES (1, $1, $2, $3)
+		ON CONFLICT (suscripcion_id) DO UPDATE SET activo=EXCLUDED.activo, cliente_id=EXCLUDED.cliente_id, updated_at=NOW()`
+	if _, err := db.ConnPgsql.Exec(db.Ctx, query, autopagoReq.SuscripcionId, clienteId, *autopagoReq.Activo); err != nil {
+		utils.Logline("error saving suscripcion_autopago", clienteId, autopagoReq, err)
+		return nil, http.StatusBadRequest, errors.New("errorUpdateRecord")
+	}
+
+	suscripcion, errType, err := GetSuscripcion(db, clienteId, autopagoReq.SuscripcionId)
+	if err != nil {
+		return nil, errType, err
+	}
+	suscripcion.Autopago = *autopagoReq.Activo
+
+	return suscripcion, http.StatusOK, nil
+}
+
+// pay with the saldo available of the cliente the open factura of the suscripciones with auto-pay
+// on its renewal day (or the last day of the month), if the cron did not run that day it is paid on
+// the next run of the month. A partial payment is retried on the next runs until the factura is paid.
+// The days are the ones of Caracas, the same of the billing calendar
+func AutopagoSuscripcionesCron(db models.ConnDb, caller string) error {
+	//show status of worker
+	utils.ShowStatusWorker(db, "autopay_suscripciones", caller+"/begin")
+
+	cuentaBancoId, err := strconv.Atoi(os.Getenv("AUTOPAY_CUENTA_BANCO_ID"))
+	if err != nil {
+		utils.Logline("error parsing AUTOPAY_CUENTA_BANCO_ID", err)
+		return err
+	}
+
+	hoy := utils.NowCaracas()
+	query := `SELECT a.suscripcion_id::text, s.cliente_id::text, TRIM(TO_CHAR((s.info->>'oldid')::integer, '000000')) as ncontrol
+		FROM publico.suscripcion_autopago as a
+		JOIN administracion.suscripcion as s ON s.id=a.suscripcion_id AND s.cliente_id=a.cliente_id
+		WHERE a.activo=true AND s.activo=true AND COALESCE((s.info->>'convenio')::boolean, false)=false
+			AND COALESCE(a.ultimo_periodo, '')<>TO_CHAR($1::date, 'YYYY-MM')
+			AND LEAST(EXTRACT(DAY FROM (s.info->>'fecha_install')::date),
+				EXTRACT(DAY FROM date_trunc('month', $1::date) + INTERVAL '1 month - 1 day')) <= EXTRACT(DAY FROM $1::date)
+		ORDER BY s.cliente_id, s.id`
+	rows, err := db.ConnPgsql.Query(db.Ctx, query, hoy.Format(time.DateOnly))
+	if err != nil {
+		utils.Logline("error getting suscripciones with autopago", err)
+		return err
+	}
+
+	var suscripciones []models.SuscripcionAutopagoCron
+	for rows.Next() {
+		var suscripcion models.SuscripcionAutopagoCron
+		if err := rows.Scan(&suscripcion.SuscripcionId, &suscripcion.ClienteId, &suscripcion.Ncontrol); err != nil {
+			rows.Close()
+			utils.Logline("error scanning suscripciones with autopago", err)
+			return err
+		}
+		suscripciones = append(suscripciones, suscripcion)
+	}
+	rows.Close()
+
+	var tasaCambio float64
+	query = `SELECT publico.latest_tasa_cambio($1) as tasa_cambio`
+	if err := db.ConnPgsql.QueryRow(db.Ctx, query, 1).Scan(&tasaCambio); err != nil {
+		utils.Logline("tasa cambio no encontrada", err)
+		return err
+	}
+
+	pagados := 0
+	for _, suscripcion := range suscripciones {
+		monto, err := autopagoSuscripcion(db, suscripcion, cuentaBancoId, tasaCambio, hoy)
+		if err != nil {
+			utils.Logline("error on autopago of suscripcion", suscripcion, err)
+			continue
+		}
+		if monto > 0 {
+			pagados++
+		}
+	}
+
+	//show status of worker
+	utils.Logline(fmt.Sprintf("there were (%d) suscripciones paid with autopago of (%d)", pagados, len(suscripciones)))
+	utils.ShowStatusWorker(db, "autopay_suscripciones", caller+"/ending")
+
+	return nil
+}
+
+// saldo available of the suscripciones with saldo in favor: the procesado one less the autopagos still
+// pendiente (the lower of procesado and total), the payments not confirmed are not used. The
+// second value is the saldo total of the cliente, the one that changes with a recibo pendiente
+func getAutopagoSaldos(db models.ConnDb, tx pgx.Tx, clienteId string) (map[string]float64, float64, error) {
+	query := `SELECT t.suscripcion_id::text, ROUND(LEAST(COALESCE(p.saldo[1], 0), t.saldo[1]),2), ROUND(t.saldo[1],2)
+		FROM venta.get_saldo(1, $1, 'total') as t
+		LEFT JOIN venta.get_saldo(1, $1, 'procesado') as p ON p.suscripcion_id=t.suscripcion_id
+		WHERE t.suscripcion_id IS NOT NULL`
+	rows, err := tx.Query(db.Ctx, query, clienteId)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	saldos := map[string]float64{}
+	total := 0.0
+	for rows.Next() {
+		var suscripcionId string
+		var saldo, saldoTotal float64
+		if err := rows.Scan(&suscripcionId, &saldo, &saldoTotal); err != nil {
+			return nil, 0, err
+		}
+		total += saldoTotal
+		if saldo > 0 {
+			saldos[suscripcionId] += saldo
+		}
+	}
+
+	return saldos, utils.RoundToTwoDecimalPlaces(total), rows.Err()
+}
+
+// pay the oldest open factura of the suscripcion applying the saldo in favor of the cliente, returns the
+// amount in dolares paid. The recibo does not bring new money: it debits the saldo of the suscripciones
+// with saldo in favor (this one first) and credits the factura, all in one transaction with the lock of the
+// transfers of the cliente, and it is discarded when the saldo does not go down by the amount paid.
+// The recibo is saved pendiente, the back office processes it on mysql like the other payments and it
+// comes back procesado with the synchronization of the recibos
+func autopagoSuscripcion(db models.ConnDb, suscripcion models.SuscripcionAutopagoCron, cuentaBancoId int, tasaCambio float64, hoy time.Time) (float64, error) {
+	tx, err := db.ConnPgsql.Begin(db.Ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(db.Ctx)
+
+	if _, err := tx.Exec(db.Ctx, `SELECT pg_advisory_xact_lock(hashtext('transferenciav:' || $1))`, suscripcion.ClienteId); err != nil {
+		return 0, err
+	}
+
+	var facturaId, facturaCreatedAt string
+	var pendiente float64
+	query := `SELECT fv.id::text, fv.created_at::text, ROUND(fv.total[1] - COALESCE(pagos.monto, 0), 2) as pendiente
+		FROM venta.facturav as fv
+		LEFT JOIN LATERAL (
+			SELECT SUM((payment->'monto'->>'dolar')::numeric) as monto
+			FROM venta.recibo_pagov as rp, jsonb_array_elements(rp.info->'payment_detail') AS payment
+			WHERE rp.cliente_id=fv.cliente_id AND rp.estatus<>'anulado' AND payment->'factura'->>'id'=fv.id::text
+		) as pagos ON true
+		WHERE fv.cliente_id=$1 AND fv.estatus IN ('pendiente', 'abonado')
+			AND EXISTS (
+				SELECT 1 FROM venta.facturav_det as det
+				WHERE det.facturav_id=fv.id AND det.created_at=fv.created_at AND det.info->'suscripcion'->>'id'=$2
+			)
+		ORDER BY fv.created_at ASC
+		LIMIT 1`
+	err = tx.QueryRow(db.Ctx, query, suscripcion.ClienteId, suscripcion.SuscripcionId).Scan(&facturaId, &facturaCreatedAt, &pendiente)
+	if err != nil {
+		// the factura of the month could be not synchronized yet
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	// paid by other means
+	if pendiente <= 0 {
+		if err := marcarAutopago(db, tx, suscripcion.SuscripcionId, hoy); err != nil {
+			return 0, err
+		}
+		return 0, tx.Commit(db.Ctx)
+	}
+
+	saldos, saldoAntes, err := getAutopagoSaldos(db, tx, suscripcion.ClienteId)
+	if err != nil {
+		return 0, err
+	}
+
+	disponible := 0.0
+	for _, saldo := range saldos {
+		disponible += saldo
+	}
+	monto := utils.RoundToTwoDecimalPlaces(min(disponible, saldoAntes, pendiente))
+	if monto <= 0 {
+		return 0, nil
+	}
+
+	suscDetalle, _, err := GetSuscripcion(db, suscripcion.ClienteId, suscripcion.SuscripcionId)
+	if err != nil {
+		return 0, err
+	}
+
+	// the saldo is taken from this suscripcion first and then from the others of the cliente
+	otras := []string{}
+	for suscripcionId := range saldos {
+		if suscripcionId != suscripcion.SuscripcionId {
+			otras = append(otras, suscripcionId)
+		}
+	}
+	sort.Slice(otras, func(i, j int) bool { return saldos[otras[i]] > saldos[otras[j]] })
+	origenes := otras
+	if saldos[suscripcion.SuscripcionId] > 0 {
+		origenes = append([]string{suscripcion.SuscripcionId}, otras...)
+	}
+
+	paymentDetails := []map[string]any{}
+	porDebitar := monto
+	for _, suscripcionId := range origenes {
+		if porDebitar <= 0 {
+			break
+		}
+		suscOrigen := suscDetalle
+		if suscripcionId != suscripcion.SuscripcionId {
+			if suscOrigen, _, err = GetSuscripcion(db, suscripcion.ClienteId, suscripcionId); err != nil {
+				return 0, err
+			}
+		}
+		debito := utils.RoundToTwoDecimalPlaces(min(saldos[suscripcionId], porDebitar))
+		paymentDetails = append(paymentDetails, map[string]any{
+			"monto":       models.Moneda{Dolar: -debito, Bolivar: -utils.RoundToTwoDecimalPlaces(debito * tasaCambio)},
+			"suscripcion": *suscOrigen,
+		})
+		porDebitar = utils.RoundToTwoDecimalPlaces(porDebitar - debito)
+	}
+
+	montoPago := models.Moneda{Dolar: monto, Bolivar: utils.RoundToTwoDecimalPlaces(monto * tasaCambio)}
+	paymentDetails = append(paymentDetails, map[string]any{
+		"monto":       montoPago,
+		"suscripcion": *suscDetalle,
+		"factura":     models.FacturaReciboCron{Id: facturaId, CreatedAt: facturaCreatedAt},
+	})
+	infoStruct := map[string]any{
+		"autopago":       true,
+		"payment_detail": paymentDetails,
+		"url_file":       "",
+		"tasa_cambio": map[string]any{
+			"servidor": tasaCambio,
+		},
+	}
+
+	// one payment by suscripcion and day, the referencia can not be repeated. The total
+	// is zero, the saldo debited is the one credited to the factura
+	referencia := "autopago" + suscripcion.SuscripcionId + hoy.Format("20060102")
+	var paymentId, paymentCreatedAt string
+	query = `SELECT id, (created_at)::text FROM venta.insert_recibo_pagov($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`
+	err = tx.QueryRow(db.Ctx, query, 1, suscripcion.ClienteId, cuentaBancoId, nil, hoy.Format(time.DateOnly), referencia,
+		[]float64{0, 0}, tasaCambio, "pendiente", 1, 1, infoStruct).Scan(&paymentId, &paymentCreatedAt)
+	if err != nil {
+		return 0, err
+	}
+
+	_, saldoDespues, err := getAutopagoSaldos(db, tx, suscripcion.ClienteId)
+	if err != nil {
+		return 0, err
+	}
+	if utils.RoundToTwoDecimalPlaces(saldoAntes-saldoDespues) != monto {
+		utils.Logline("autopago discarded, the saldo does not match the amount paid", suscripcion, saldoAntes, saldoDespues, monto)
+		return 0, errors.New("autopago saldo mismatch")
+	}
+
+	if monto >= pendiente {
+		if err := marcarAutopago(db, tx, suscripcion.SuscripcionId, hoy); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(db.Ctx); err != nil {
+		return 0, err
+	}
+
+	notifyAutopago(db, suscripcion, facturaId, montoPago, paymentId, monto >= pendiente)
+
+	return monto, nil
+}
+
+// the factura of the month is paid
+func marcarAutopago(db models.ConnDb, tx pgx.Tx, suscripcionId string, hoy time.Time) error {
+	query := `UPDATE publico.suscripcion_autopago SET ultimo_periodo=$2, ultimo_pago_at=NOW(), updated_at=NOW()
+		WHERE suscripcion_id=$1`
+	if _, err := tx.Exec(db.Ctx, query, suscripcionId, hoy.Format("2006-01")); err != nil {
+		utils.Logline("error updating suscripcion_autopago", suscripcionId, err)
+		return err
+	}
+
+	return nil
+}
+
+// let the cliente know about the payment by email, or sms when it does not have email
+func notifyAutopago(db models.ConnDb, suscripcion models.SuscripcionAutopagoCron, facturaId string, monto models.Moneda, paymentId string, total bool) {
+	user, err := getUser(db, "id", suscripcion.ClienteId)
+	if err != nil {
+		return
+	}
+
+	estado := "El pago cubre el total de la factura."
+	if !total {
+		estado = "El saldo disponible no cubre el total de la factura, el resto se pagara cuando tengas saldo disponible."
+	}
+
+	if len(user.Profile.Correo) == 0 {
+		celular := utils.FirstCelular(user.Profile.Telefono)
+		if celular == "" {
+			return
+		}
+		message := "Besser Solutions: se aplico tu saldo " + utils.FormatMoneda(monto) + " a la factura " +
+			utils.GenerateNcontrolByUuid(facturaId) + " de la suscripcion " + suscripcion.Ncontrol + ", pendiente por confirmar."
+		if err := utils.SendSms(celular, message); err != nil {
+			utils.Logline("error sending the sms of autopago", suscripcion, err)
+		}
+		return
+	}
+
+	bodyEmail := `
+		<!DOCTYPE html>
+		<html lang="en">
+			<head>
+				<meta charset="UTF-8">
+				<meta name="viewport" content="width=device-width, initial-scale=1.0">
+				<title>Pago automatico de tu suscripcion</title>
+				<style>
+					body {font-family: Arial, sans-serif; background-color: #f6f8fa; margin: 0;padding: 0; }
+					.container {width: 100%; max-width: 600px; margin: 0 auto; padding: 20px;}
+					.header {text-align: center; padding: 20px 0;}
+					.header img {width: 200px;}
+					.content {padding: 20px; border: 1px solid #e1e4e8; border-radius: 5px;}
+					.content p {font-size: 16px; color: #333333;}
+					.monto {display: block; margin: 20px auto; text-align: center; font-size: 24px; font-weight: bold; color: #28a745;}
+					.footer {text-align: center; padding: 20px; font-size: 12px; color: #666666;}
+				</style>
+			</head>
+			<body>
+				<div class="container">
+					<div class="header">
+						<img src="cid:image001" alt="Besser Solutions Logo">
+						<h1>Pago automatico de tu suscripcion</h1>
+					</div>
+					<div class="content">
+						<b>Hola, </b>
+						<p>Con el saldo disponible de tu cuenta se realizo el pago de la factura ` + utils.GenerateNcontrolByUuid(facturaId) +
+		` de la suscripcion ` + suscripcion.Ncontrol + ` por:</p>
+						<span class="monto">` + utils.FormatMoneda(monto) + `</span>
+						<p>` + estado + ` El comprobante es el pago ` + utils.GenerateNcontrolByUuid(paymentId) + `, sera confirmado por administracion.</p>
+						<p>Gracias,<br>El equipo de Besser Solutions</p>
+					</div>
+					<div class="footer">
+						<p>Recibiste este correo electrónico porque activaste el pago automatico de tu suscripcion en MiCuenta.</p>
+						<p>Besser Solutions, C.A. • Santa Irene, Calle San Miguel, Edif. Asdrubal Jose PB • Punto Fijo, Falcon 4102</p>
+					</div>
+				</div>
+			</body>
+		</html>
+	`
+	if err := utils.SendEmail(user.Profile.Correo, "[Besser Solutions] Pago Automatico De Tu Suscripcion", bodyEmail); err != nil {
+		utils.Logline("error sending the email of autopago", suscripcion, err)
+	}
+}
@@ -0,0 +1,358 @@
+package repo
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"ired.com/micuenta/models"
+	"ired.com/micuenta/utils"
+)
+
+// import a bank statement and reconcile its lines with the pending payments of the cuenta, all
+// in one transaction: if a line fails nothing is saved and the file can be imported again.
+// The lines already imported by another statement of the cuenta (overlapping dates) are skipped
+func ImportBankStatement(db models.ConnDb, layoutName string, archivo string, content []byte, origen string) (*models.BancoImportResult, int, error) {
+	layout, err := utils.FindBankLayout(layoutName)
+	if err != nil {
+		utils.Logline("error loading bank layout", layoutName, err)
+		return nil, http.StatusBadRequest, errors.New("bankLayoutNotFound")
+	}
+
+	movimientos, err := utils.ParseBankStatement(content, *layout)
+	if err != nil {
+		utils.Logline("error parsing bank statement", archivo, err)
+		return nil, http.StatusBadRequest, errors.New("bankStatementInvalid")
+	}
+
+	var moneda string
+	query := `SELECT moneda FROM publico.cuenta_banco WHERE id=$1`
+	if err := db.ConnPgsql.QueryRow(db.Ctx, query, layout.CuentaBancoId).Scan(&moneda); err != nil {
+		utils.Logline("cuenta_banco of bank layout not found", layout.Nombre, layout.CuentaBancoId, err)
+		return nil, http.StatusBadRequest, errors.New("bankLayoutNotFound")
+	}
+
+	tx, err := db.ConnPgsql.Begin(db.Ctx)
+	if err != nil {
+		utils.Logline("error starting transaction of banco_extracto", archivo, err)
+		return nil, http.StatusInternalServerError, errors.New("errorInsertRecord")
+	}
+	defer tx.Rollback(db.Ctx)
+
+	// the statements of the same cuenta are imported one at a time to compare the lines between them
+	if _, err := tx.Exec(db.Ctx, `SELECT pg_advisory_xact_lock(hashtext('banco_extracto'), $1::integer)`, layout.CuentaBancoId); err != nil {
+		utils.Logline("error locking cuenta_banco of banco_extracto", layout.CuentaBancoId, err)
+		return nil, http.StatusInternalServerError, errors.New("errorInsertRecord")
+	}
+
+	result := models.BancoImportResult{Archivo: filepath.Base(archivo), Lineas: len(movimientos)}
+	query = `INSERT INTO publico.banco_extracto (empresa_id, cuenta_banco_id, layout, archivo, hash, origen)
+		VALUES (1, $1, $2, $3, $4, $5) ON CONFLICT (hash) DO NOTHING RETURNING id::text`
+	err = tx.QueryRow(db.Ctx, query, layout.CuentaBancoId, layout.Nombre, result.Archivo, utils.HashToken(string(content)), origen).Scan(&result.ExtractoId)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, http.StatusConflict, errors.New("bankStatementDuplicated")
+		}
+		utils.Logline("error inserting banco_extracto", archivo, err)
+		return nil, http.StatusInternalServerError, errors.New("errorInsertRecord")
+	}
+
+	// times each movimiento is repeated on the file, two equal movements of the bank are two lines
+	repetidos := map[string]int{}
+	for _, movimiento := range movimientos {
+		key := fmt.Sprintf("%s|%s|%.2f", movimiento.Fecha, movimiento.Referencia, movimiento.Monto)
+		repetidos[key]++
+		duplicado, err := isMovimientoImported(db, tx, result.ExtractoId, layout.CuentaBancoId, movimiento, repetidos[key])
+		if err != nil {
+			return nil, http.StatusInternalServerError, errors.New("errorInsertRecord")
+		}
+		if duplicado {
+			result.Duplicados++
+			continue
+		}
+
+		estatus, err := reconcileMovimiento(db, tx, result.ExtractoId, layout.CuentaBancoId, moneda, movimiento)
+		if err != nil {
+			return nil, http.StatusInternalServerError, errors.New("errorInsertRecord")
+		}
+
+		switch estatus {
+		case "conciliado":
+			result.Conciliados++
+		case "revision":
+			result.Revision++
+		default:
+			result.SinMatch++
+		}
+	}
+
+	query = `UPDATE publico.banco_extracto SET resumen=$2 WHERE id=$1`
+	if _, err := tx.Exec(db.Ctx, query, result.ExtractoId, result); err != nil {
+		utils.Logline("error updating banco_extracto", result.ExtractoId, err)
+		return nil, http.StatusInternalServerError, errors.New("errorInsertRecord")
+	}
+
+	if err := tx.Commit(db.Ctx); err != nil {
+		utils.Logline("error commiting banco_extracto", archivo, err)
+		return nil, http.StatusInternalServerError, errors.New("errorInsertRecord")
+	}
+
+	utils.Logline(fmt.Sprintf("bank statement %s imported, (%d) lines, (%d) conciliados, (%d) revision, (%d) sin match, (%d) duplicados",
+		result.Archivo, result.Lineas, result.Conciliados, result.Revision, result.SinMatch, result.Duplicados))
+	return &result, http.StatusOK, nil
+}
+
+// the movimiento was imported by another statement of the cuenta, ocurrencia is the number of times
+// it is repeated on the current file so the equal movements of the bank are not merged
+func isMovimientoImported(db models.ConnDb, tx pgx.Tx, extractoId string, cuentaBancoId int64, movimiento models.BancoMovimiento, ocurrencia int) (bool, error) {
+	var importados int
+	query := `SELECT COUNT(*) FROM publico.banco_movimiento
+		WHERE cuenta_banco_id=$1 AND fecha=$2 AND referencia=$3 AND ROUND(monto, 2)=ROUND($4::numeric, 2) AND extracto_id<>$5`
+	err := tx.QueryRow(db.Ctx, query, cuentaBancoId, movimiento.Fecha, movimiento.Referencia, movimiento.Monto, extractoId).Scan(&importados)
+	if err != nil {
+		utils.Logline("error checking banco_movimiento imported", extractoId, movimiento, err)
+		return false, err
+	}
+	return ocurrencia <= importados, nil
+}
+
+// save the line and look for the recibo pendiente of the cuenta that matches it. The exact match
+// (same referencia and monto) is marked as conciliado for the back office, the recibo stays pendiente
+// until it is processed there and synchronized from mysql. The partial ones are left for review.
+// The lines are saved by statement and number, two equal movements of the bank are two lines
+func reconcileMovimiento(db models.ConnDb, tx pgx.Tx, extractoId string, cuentaBancoId int64, moneda string, movimiento models.BancoMovimiento) (string, error) {
+	var movimientoId int64
+	query := `INSERT INTO publico.banco_movimiento (empresa_id, extracto_id, cuenta_banco_id, linea, fecha, referencia, descripcion, monto, estatus)
+		VALUES (1, $1, $2, $3, $4, $5, $6, $7, 'sin_match') RETURNING id`
+	err := tx.QueryRow(db.Ctx, query, extractoId, cuentaBancoId, movimiento.Linea, movimiento.Fecha, movimiento.Referencia,
+		movimiento.Descripcion, movimiento.Monto).Scan(&movimientoId)
+	if err != nil {
+		utils.Logline("error inserting banco_movimiento", extractoId, movimiento, err)
+		return "", err
+	}
+
+	candidatos, err := getBancoCandidatos(db, tx, cuentaBancoId, moneda, movimiento)
+	if err != nil {
+		return "", err
+	}
+
+	var exactos []models.BancoCandidato
+	for _, candidato := range candidatos {
+		if candidato.Motivo == "exacto" {
+			exactos = append(exactos, candidato)
+		}
+	}
+
+	estatus := "sin_match"
+	if len(candidatos) > 0 {
+		estatus = "revision"
+	}
+
+	if len(exactos) == 1 {
+		exacto := exactos[0]
+		conciliacion := map[string]any{
+			"extracto_id":   extractoId,
+			"movimiento_id": movimientoId,
+			"fecha":         time.Now().Format(time.RFC3339),
+		}
+		query = `UPDATE venta.recibo_pagov SET updated_at=NOW(), info=info || jsonb_build_object('conciliacion', $3::jsonb)
+			WHERE id=$1 AND created_at=$2 AND estatus='pendiente' AND info->'conciliacion' IS NULL`
+		result, err := tx.Exec(db.Ctx, query, exacto.PaymentId, exacto.CreatedAt, conciliacion)
+		if err != nil {
+			utils.Logline("error updating conciliacion of recibo_pagov", exacto.PaymentId, err)
+			return "", err
+		}
+		if result.RowsAffected() == 1 {
+			query = `UPDATE publico.banco_movimiento SET estatus='conciliado', recibo_pagov_id=$2, recibo_pagov_created_at=$3 WHERE id=$1`
+			if _, err := tx.Exec(db.Ctx, query, movimientoId, exacto.PaymentId, exacto.CreatedAt); err != nil {
+				utils.Logline("error updating banco_movimiento", movimientoId, err)
+				return "", err
+			}
+			utils.Logline("recibo_pagov conciliado", exacto.PaymentId, "banco_movimiento", movimientoId)
+			return "conciliado", nil
+		}
+	}
+
+	query = `UPDATE publico.banco_movimiento SET estatus=$2, candidatos=$3 WHERE id=$1`
+	if _, err := tx.Exec(db.Ctx, query, movimientoId, estatus, candidatos); err != nil {
+		utils.Logline("error updating banco_movimiento", movimientoId, err)
+		return "", err
+	}
+
+	return estatus, nil
+}
+
+// pending payments of the cuenta not conciliated yet inside the date window (BANK_MATCH_DAYS)
+// with the same referencia or monto of the line, only the same referencia is an exact match
+func getBancoCandidatos(db models.ConnDb, tx pgx.Tx, cuentaBancoId int64, moneda string, movimiento models.BancoMovimiento) ([]models.BancoCandidato, error) {
+	matchDays, err := strconv.Atoi(os.Getenv("BANK_MATCH_DAYS"))
+	if err != nil {
+		matchDays = 3
+	}
+
+	// the monto of the recibo is [dolar, bolivar], the one of the moneda of the cuenta is compared
+	montoIndex := 2
+	if moneda == "dolar" {
+		montoIndex = 1
+	}
+
+	query := `SELECT id::text, created_at, cliente_id, fecha::text, COALESCE(referencia, ''), ROUND(monto[$4], 2)
+		FROM venta.recibo_pagov
+		WHERE empresa_id=1 AND estatus='pendiente' AND metodo_pago_id=$1 AND info->'conciliacion' IS NULL
+			AND fecha BETWEEN $2::date - $3::integer AND $2::date + $3::integer`
+	rows, err := tx.Query(db.Ctx, query, cuentaBancoId, movimiento.Fecha, matchDays, montoIndex)
+	if err != nil {
+		utils.Logline("error getting recibo_pagov to reconcile", cuentaBancoId, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	candidatos := []models.BancoCandidato{}
+	for rows.Next() {
+		var candidato models.BancoCandidato
+		var createdAt time.Time
+		if err := rows.Scan(&candidato.PaymentId, &createdAt, &candidato.ClienteId, &candidato.Fecha, &candidato.Referencia, &candidato.Monto); err != nil {
+			utils.Logline("error scanning recibo_pagov to reconcile", cuentaBancoId, err)
+			return nil, err
+		}
+		candidato.CreatedAt = createdAt.Format(time.RFC3339Nano)
+
+		referencia := referenciaMatch(candidato.Referencia, movimiento.Referencia)
+		montoOk := math.Abs(candidato.Monto-movimiento.Monto) < 0.01
+		switch {
+		case referencia == "igual" && montoOk:
+			candidato.Motivo = "exacto"
+		case referencia != "":
+			candidato.Motivo = "referencia"
+		case montoOk:
+			candidato.Motivo = "monto"
+		default:
+			continue
+		}
+		candidatos = append(candidatos, candidato)
+	}
+	rows.Close()
+
+	return candidatos, nil
+}
+
+// igual when the referencias are the same, sufijo when only the last digits (at least 6) are
+// equal because some banks cut the referencia, that match is only a candidate for review
+func referenciaMatch(referenciaRecibo string, referenciaBanco string) string {
+	recibo := utils.NormalizeReferencia(referenciaRecibo)
+	banco := utils.NormalizeReferencia(referenciaBanco)
+	if recibo == "" || banco == "" {
+		return ""
+	}
+	if recibo == banco {
+		return "igual"
+	}
+	if len(recibo) < 6 || len(banco) < 6 {
+		return ""
+	}
+	if strings.HasSuffix(recibo, banco) || strings.HasSuffix(banco, recibo) {
+		return "sufijo"
+	}
+	return ""
+}
+
+// lines of the statements waiting for back office
+func BankReviewList(db models.ConnDb, pageQuery models.PaginatorQuery) (*[]models.BancoRevision, *models.PaginatorData, error) {
+	currentPage := pageQuery.Page
+	limit := pageQuery.Limit
+	offset := (currentPage - 1) * limit
+
+	var totalCount int
+	if err := db.ConnPgsql.QueryRow(db.Ctx, "SELECT COUNT(*) FROM publico.banco_movimiento WHERE estatus='revision'").Scan(&totalCount); err != nil {
+		utils.Logline("error on query count", err)
+		return nil, nil, errors.New("errorGetData")
+	}
+	paginatorData := models.GetPaginatorMeta(currentPage, limit, totalCount)
+
+	//validate if current page is possible to offset
+	if currentPage > paginatorData.TotalPages {
+		return nil, nil, errors.New("errorPage")
+	}
+
+	query := `SELECT id, cuenta_banco_id, linea, fecha::text, referencia, descripcion, ROUND(monto, 2), candidatos
+		FROM publico.banco_movimiento
+		WHERE estatus='revision'
+		ORDER BY fecha ASC, id ASC
+		LIMIT $1
+		OFFSET $2`
+	rows, err := db.ConnPgsql.Query(db.Ctx, query, limit, offset)
+	if err != nil {
+		utils.Logline("error on select banco_movimiento", err)
+		return nil, nil, errors.New("errorGetData")
+	}
+	defer rows.Close()
+
+	revisionList := []models.BancoRevision{}
+	for rows.Next() {
+		var revision models.BancoRevision
+		err := rows.Scan(&revision.Id, &revision.CuentaBancoId, &revision.Movimiento.Linea, &revision.Movimiento.Fecha, &revision.Movimiento.Referencia,
+			&revision.Movimiento.Descripcion, &revision.Movimiento.Monto, &revision.Candidatos)
+		if err != nil {
+			utils.Logline("error scanning banco_movimiento", err)
+			return nil, nil, errors.New("errorGetData")
+		}
+		revisionList = append(revisionList, revision)
+	}
+	rows.Close()
+
+	return &revisionList, &paginatorData, nil
+}
+
+// import the csv files of BANK_STATEMENT_FOLDER, the name of the file must start with
+// the name of the layout (banesco_20250101.csv), then it is moved to processed or failed
+func ImportBankStatementsCron(db models.ConnDb, caller string) error {
+	//show status of worker
+	utils.ShowStatusWorker(db, "import_bank_statements", caller+"/begin")
+
+	folder := os.Getenv("BANK_STATEMENT_FOLDER")
+	if folder == "" {
+		return errors.New("BANK_STATEMENT_FOLDER is not defined")
+	}
+
+	files, err := filepath.Glob(filepath.Join(folder, "*.csv"))
+	if err != nil {
+		utils.Logline("error reading bank statement folder", folder, err)
+		return err
+	}
+
+	for _, file := range files {
+		layoutName, _, _ := strings.Cut(filepath.Base(file), "_")
+
+		destino := "processed"
+		content, err := os.ReadFile(file)
+		if err != nil {
+			utils.Logline("error reading bank statement", file, err)
+			destino = "failed"
+		} else if _, _, err := ImportBankStatement(db, layoutName, file, content, caller); err != nil {
+			utils.Logline("error importing bank statement", file, err)
+			destino = "failed"
+		}
+
+		if err := os.MkdirAll(filepath.Join(folder, destino), 0755); err != nil {
+			utils.Logline("error creating bank statement folder", destino, err)
+			return err
+		}
+		if err := os.Rename(file, filepath.Join(folder, destino, filepath.Base(file))); err != nil {
+			utils.Logline("error moving bank statement", file, err)
+			return err
+		}
+	}
+
+	//show status of worker
+	utils.Logline(fmt.Sprintf("there were (%d) bank statements processed", len(files)))
+	utils.ShowStatusWorker(db, "import_bank_statements", caller+"/ending")
+
+	return nil
+}
@@ -3,7 +3,10 @@ package repo
 import (
 	"context"
 	"fmt"
+	"os"
+	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"golang.org/x/crypto/bcrypt"
@@ -31,26 +34,118 @@ func CleanOldSessionsCron(db models.ConnDb, caller string) error {
 
 	utils.Logline("it was remove (" + count + ") records")
 
+	// retired tokens are kept until they expire to detect a replay
+	query = `DELETE FROM publico.cliente_refresh_token WHERE expires_at<NOW()`
+	result, err := db.ConnPgsql.Exec(db.Ctx, query)
+	if err != nil {
+		utils.Logline("error deleting old refresh tokens", err)
+		return err
+	}
+
+	utils.Logline("it was remove (" + strconv.FormatInt(result.RowsAffected(), 10) + ") refresh tokens")
+
+	// failed login counters that are not locked and out of the window
+	query = `DELETE FROM publico.auth_login_attempt WHERE ultimo_fallo < NOW() - make_interval(hours => $1) AND (bloqueado_hasta IS NULL OR bloqueado_hasta < NOW())`
+	result, err = db.ConnPgsql.Exec(db.Ctx, query, loginFailWindowHrs)
+	if err != nil {
+		utils.Logline("error deleting old login attempts", err)
+		return err
+	}
+
+	utils.Logline("it was remove (" + strconv.FormatInt(result.RowsAffected(), 10) + ") login attempts")
+
+	// reset tokens and throttle counters that are not needed anymore
+	query = `DELETE FROM publico.cliente_reset_token WHERE expires_at < NOW() - INTERVAL '1 day'`
+	result, err = db.ConnPgsql.Exec(db.Ctx, query)
+	if err != nil {
+		utils.Logline("error deleting old reset tokens", err)
+		return err
+	}
+
+	utils.Logline("it was remove (" + strconv.FormatInt(result.RowsAffected(), 10) + ") reset tokens")
+
+	query = `DELETE FROM publico.auth_rate_limit WHERE ventana_inicio < NOW() - INTERVAL '1 day'`
+	if _, err = db.ConnPgsql.Exec(db.Ctx, query); err != nil {
+		utils.Logline("error deleting old rate limits", err)
+		return err
+	}
+
+	// idempotency keys out of the replay window
+	query = `DELETE FROM publico.idempotency_key WHERE created_at < NOW() - make_interval(hours => $1)`
+	result, err = db.ConnPgsql.Exec(db.Ctx, query, idempotencyKeyHrs)
+	if err != nil {
+		utils.Logline("error deleting old idempotency keys", err)
+		return err
+	}
+
+	utils.Logline("it was remove (" + strconv.FormatInt(result.RowsAffected(), 10) + ") idempotency keys")
+
 	//show status of worker
 	utils.ShowStatusWorker(db, "sinc_users", caller+"/ending")
 
 	return nil
 }
 
+func PurgeRevokedTokensCron(db models.ConnDb, caller string) error {
+	//show status of worker
+	utils.ShowStatusWorker(db, "purge_revoked_tokens", caller+"/begin")
+
+	// once the token expires the revocation is not needed anymore
+	query := `DELETE FROM publico.cliente_token_revocado WHERE expires_at<NOW()`
+	result, err := db.ConnPgsql.Exec(db.Ctx, query)
+	if err != nil {
+		utils.Logline("error deleting old revoked tokens", err)
+		return err
+	}
+
+	utils.Logline("it was remove (" + strconv.FormatInt(result.RowsAffected(), 10) + ") revoked tokens")
+
+	//show status of worker
+	utils.ShowStatusWorker(db, "purge_revoked_tokens", caller+"/ending")
+
+	return nil
+}
+
+func RotateJwtKeysCron(db models.ConnDb, caller string) error {
+	//show status of worker
+	utils.ShowStatusWorker(db, "rotate_jwt_keys", caller+"/begin")
+
+	if err := utils.RotateJwtKeys(); err != nil {
+		utils.Logline("error rotating jwt keys", err)
+		return err
+	}
+
+	//show status of worker
+	utils.ShowStatusWorker(db, "rotate_jwt_keys", caller+"/ending")
+
+	return nil
+}
+
 type clientPasswdCron struct {
 	id     string
 	docid  string
-	nombre string
+	passwd string
 }
 
-func CreatePasswordsCron(db models.ConnDb, caller string) error {
+// accounts whose password is still the numbers of the docid (created by the old
+// create_clients_passwd task) are forced to change it before a deadline, after it
+// they must use the activation code. New clientes keep passwd NULL until activation.
+func MigrateDocidPasswdCron(db models.ConnDb, caller string) error {
 	//show status of worker
-	utils.ShowStatusWorker(db, "create_client_passwd", caller+"/begin")
+	utils.ShowStatusWorker(db, "migrate_docid_passwd", caller+"/begin")
 
-	query := `SELECT id, docid, nombre FROM publico.cliente WHERE passwd IS NULL ORDER BY id ASC LIMIT 2000`
+	migrationDays, err := strconv.Atoi(os.Getenv("PASSWD_MIGRATION_DAYS"))
+	if err != nil {
+		utils.Logline("error parsing PASSWD_MIGRATION_DAYS", err)
+		return err
+	}
+
+	query := `SELECT id, docid, passwd FROM publico.cliente 
+		WHERE passwd IS NOT NULL AND passwd<>'' AND NOT COALESCE((info->>'passwd_checked')::boolean, false)
+		ORDER BY id ASC LIMIT 500`
 	rows, err := db.ConnPgsql.Query(db.Ctx, query)
 	if err != nil {
-		utils.Logline("error deleting old sessions", err)
+		utils.Logline("error getting clientes for password migration", err)
 		return err
 	}
 	defer rows.Close()
@@ -58,55 +153,63 @@ func CreatePasswordsCron(db models.ConnDb, caller string) error {
 	var clientes []clientPasswdCron
 	for rows.Next() {
 		var cliente clientPasswdCron
-		err = rows.Scan(&cliente.id, &cliente.docid, &cliente.nombre)
+		err = rows.Scan(&cliente.id, &cliente.docid, &cliente.passwd)
 		if err != nil {
-			utils.Logline("error scanning cliente for password change:", err)
-			return fmt.Errorf("error scanning cliente for password change: %w", err)
+			utils.Logline("error scanning cliente for password migration:", err)
+			return fmt.Errorf("error scanning cliente for password migration: %w", err)
 		}
 		clientes = append(clientes, cliente)
 	}
 	rows.Close()
 
+	deadline := time.Now().AddDate(0, 0, migrationDays).Format(time.RFC3339)
+
 	// Worker pool size (Adjust for optimal performance)
 	var wg sync.WaitGroup
 	const workerPoolSize = 10
 	sem := make(chan struct{}, workerPoolSize) // Semaphore to limit concurrency
 
-	var contador int
+	var contador atomic.Int32
 	for _, cliente := range clientes {
 		wg.Add(1)
 		sem <- struct{}{} // Limit concurrency
 
 		go func(cliente clientPasswdCron) {
 			defer wg.Done()
+			defer func() { <-sem }() // Release semaphore
 
-			ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
 			defer cancel()
 
-			// create password hash based on numbers from docid
-			password := utils.ExtractNumbers(cliente.docid)
-			hash, _ := bcrypt.GenerateFromPassword([]byte(password), 10)
+			// check if the password is still the numbers of the docid
+			docidPasswd := bcrypt.CompareHashAndPassword([]byte(cliente.passwd), []byte(utils.ExtractNumbers(cliente.docid))) == nil
 
 			//update cliente on db
-			query = `UPDATE publico.cliente SET passwd=$1 WHERE id=$2`
-			_, err := db.ConnPgsql.Exec(ctx, query, hash, cliente.id)
+			query := `UPDATE publico.cliente SET info=jsonb_set(COALESCE(info, '{}'), '{passwd_checked}', 'true') WHERE id=$1`
+			args := []any{cliente.id}
+			if docidPasswd {
+				query = `UPDATE publico.cliente SET change_passwd=true, 
+					info=COALESCE(info, '{}') || jsonb_build_object('passwd_checked', true, 'passwd_deadline', $2::text) WHERE id=$1`
+				args = append(args, deadline)
+			}
+			_, err := db.ConnPgsql.Exec(ctx, query, args...)
 			if err != nil {
-				utils.Logline("error updating user", cliente, err)
-			} else {
-				utils.Logline("password created sucessfully", cliente)
+				utils.Logline("error updating user", cliente.id, err)
+				return
 			}
 
-			<-sem // Release semaphore
+			if docidPasswd {
+				contador.Add(1)
+				utils.Logline("docid password migrated sucessfully", cliente.id)
+			}
 		}(cliente)
-
-		contador++
 	}
 
 	wg.Wait()
 
 	//show status of worker
-	utils.Logline(fmt.Sprintf("there were (%d) passwords sincronized", contador))
-	utils.ShowStatusWorker(db, "create_client_passwd", caller+"/ending")
+	utils.Logline(fmt.Sprintf("there were (%d) of (%d) docid passwords migrated", contador.Load(), len(clientes)))
+	utils.ShowStatusWorker(db, "migrate_docid_passwd", caller+"/ending")
 
 	return nil
 }
@@ -0,0 +1,288 @@
+package repo
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"sort"
+	"time"
+
+	"ired.com/micuenta/models"
+	"ired.com/micuenta/utils"
+)
+
+type estadoCuentaMovimiento struct {
+	suscripcionId int64
+	movimiento    models.EstadoCuentaMovimiento
+	monto         models.Moneda
+}
+
+// max days of a statement, the movements are read in one query
+const estadoCuentaMaxDias = 366
+
+// statement of the cliente by suscripcion, the movements are the facturas, the payments
+// procesados (and the part of them not assigned to a factura), the retenciones and the transfers
+// (sent and received). The opening balance is the sum of the movements before desde and the
+// running balance goes forward from it
+func EstadoCuenta(db models.ConnDb, clienteId string, req models.EstadoCuentaReq) (*models.EstadoCuenta, int, error) {
+	hasta := utils.NowCaracas()
+	if req.Hasta != "" {
+		hasta, _ = time.Parse(time.DateOnly, req.Hasta)
+	}
+	desde := hasta.AddDate(0, -3, 0)
+	if req.Desde != "" {
+		desde, _ = time.Parse(time.DateOnly, req.Desde)
+	}
+	if desde.After(hasta) {
+		return nil, http.StatusBadRequest, errors.New("veDateRange")
+	}
+	if hasta.Sub(desde) > estadoCuentaMaxDias*24*time.Hour {
+		return nil, http.StatusBadRequest, errors.New("veDateRangeMax")
+	}
+	desdeStr, hastaStr := desde.Format(time.DateOnly), hasta.Format(time.DateOnly)
+
+	user, err := getUser(db, "id", clienteId)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+
+	// ncontrol of the suscripciones
+	query := `SELECT id, COALESCE(TRIM(TO_CHAR((info->>'oldid')::integer, '000000')), '') as ncontrol
+		FROM administracion.suscripcion WHERE cliente_id=$1`
+	rows, err := db.ConnPgsql.Query(db.Ctx, query, clienteId)
+	if err != nil {
+		utils.Logline("error on select suscripcion for estado de cuenta", clienteId, err)
+		return nil, http.StatusBadRequest, errors.New("errorGetData")
+	}
+	defer rows.Close()
+
+	suscripciones := map[int64]*models.EstadoCuentaSuscripcion{}
+	for rows.Next() {
+		var suscripcion models.EstadoCuentaSuscripcion
+		if err := rows.Scan(&suscripcion.SuscripcionId, &suscripcion.Ncontrol); err != nil {
+			utils.Logline("error scanning suscripcion for estado de cuenta", clienteId, err)
+			return nil, http.StatusBadRequest, errors.New("errorGetData")
+		}
+		suscripcion.Movimientos = []models.EstadoCuentaMovimiento{}
+		suscripciones[suscripcion.SuscripcionId] = &suscripcion
+	}
+	rows.Close()
+
+	getSuscripcion := func(suscripcionId int64) *models.EstadoCuentaSuscripcion {
+		suscripcion, ok := suscripciones[suscripcionId]
+		if !ok {
+			suscripcion = &models.EstadoCuentaSuscripcion{SuscripcionId: suscripcionId, Movimientos: []models.EstadoCuentaMovimiento{}}
+			suscripciones[suscripcionId] = suscripcion
+		}
+		return suscripcion
+	}
+
+	// opening balance, all the movements before desde
+	query = `SELECT COALESCE(mov.suscripcion_id, 0), COALESCE(SUM(mov.monto_dolar), 0), COALESCE(SUM(mov.monto_bolivar), 0)
+		FROM (` + estadoCuentaMovimientosQuery + `) as mov
+		WHERE mov.fecha < $2::date
+		GROUP BY 1`
+	rows, err = db.ConnPgsql.Query(db.Ctx, query, clienteId, desdeStr)
+	if err != nil {
+		utils.Logline("error on select saldo inicial for estado de cuenta", clienteId, err)
+		return nil, http.StatusBadRequest, errors.New("errorGetData")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var suscripcionId int64
+		var saldo models.Moneda
+		if err := rows.Scan(&suscripcionId, &saldo.Dolar, &saldo.Bolivar); err != nil {
+			utils.Logline("error scanning saldo inicial for estado de cuenta", clienteId, err)
+			return nil, http.StatusBadRequest, errors.New("errorGetData")
+		}
+		getSuscripcion(suscripcionId).SaldoInicial = saldo
+	}
+	rows.Close()
+
+	movimientos, err := getEstadoCuentaMovimientos(db, clienteId, desdeStr, hastaStr)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+
+	// running balance until hasta
+	for _, suscripcion := range suscripciones {
+		suscripcion.SaldoFinal = suscripcion.SaldoInicial
+	}
+	for _, mov := range movimientos {
+		suscripcion := getSuscripcion(mov.suscripcionId)
+		suscripcion.SaldoFinal.Dolar += mov.monto.Dolar
+		suscripcion.SaldoFinal.Bolivar += mov.monto.Bolivar
+		mov.movimiento.Saldo = models.Moneda{
+			Dolar:   utils.RoundToTwoDecimalPlaces(suscripcion.SaldoFinal.Dolar),
+			Bolivar: utils.RoundToTwoDecimalPlaces(suscripcion.SaldoFinal.Bolivar),
+		}
+		suscripcion.Movimientos = append(suscripcion.Movimientos, mov.movimiento)
+	}
+
+	estado := models.EstadoCuenta{
+		Nombre:        user.Profile.Nombre,
+		DocId:         user.Profile.Username,
+		Desde:         desde.Format(time.DateOnly),
+		Hasta:         hastaStr,
+		Suscripciones: []models.EstadoCuentaSuscripcion{},
+		DatosBesser:   models.GetDatosBesser(),
+	}
+	for _, suscripcion := range suscripciones {
+		if req.SuscripcionId != 0 && suscripcion.SuscripcionId != req.SuscripcionId {
+			continue
+		}
+		// the movements without suscripcion are shown only when there are any
+		if suscripcion.SuscripcionId == 0 && len(suscripcion.Movimientos) == 0 && suscripcion.SaldoFinal == (models.Moneda{}) {
+			continue
+		}
+		suscripcion.SaldoInicial = models.Moneda{
+			Dolar:   utils.RoundToTwoDecimalPlaces(suscripcion.SaldoInicial.Dolar),
+			Bolivar: utils.RoundToTwoDecimalPlaces(suscripcion.SaldoInicial.Bolivar),
+		}
+		suscripcion.SaldoFinal = models.Moneda{
+			Dolar:   utils.RoundToTwoDecimalPlaces(suscripcion.SaldoFinal.Dolar),
+			Bolivar: utils.RoundToTwoDecimalPlaces(suscripcion.SaldoFinal.Bolivar),
+		}
+		estado.Suscripciones = append(estado.Suscripciones, *suscripcion)
+	}
+	// until today the final balance must be the saldo of the cliente
+	if hastaStr == utils.NowCaracas().Format(time.DateOnly) {
+		checkEstadoCuentaSaldo(db, clienteId, suscripciones)
+	}
+
+	if req.SuscripcionId != 0 && len(estado.Suscripciones) == 0 {
+		return nil, http.StatusBadRequest, errors.New("veSuscripcion")
+	}
+	sort.Slice(estado.Suscripciones, func(i, j int) bool {
+		return estado.Suscripciones[i].Ncontrol < estado.Suscripciones[j].Ncontrol
+	})
+
+	return &estado, http.StatusOK, nil
+}
+
+// movements of the cliente ($1) with fecha, created_at, tipo, id, documento, suscripcion_id and the monto
+// (positive in favor of the cliente), the facturas and retenciones belong to the suscripcion of the
+// first line of the factura and the part of a payment not assigned to the one of its first detail
+const estadoCuentaMovimientosQuery = `WITH factura_susc AS (
+			SELECT DISTINCT ON (d.facturav_id, d.created_at) d.facturav_id, d.created_at, (d.info->'suscripcion'->>'id')::bigint as suscripcion_id
+			FROM venta.facturav_det as d
+			JOIN venta.facturav as fv ON fv.id=d.facturav_id AND fv.created_at=d.created_at
+			WHERE fv.cliente_id=$1
+			ORDER BY d.facturav_id, d.created_at
+		)
+		SELECT * FROM (
+			SELECT fv.fecha::date as fecha, fv.created_at, 'factura' as tipo, fv.id::text as id, COALESCE(fv.nfactura, '') as documento, fs.suscripcion_id,
+				-fv.total[1] as monto_dolar, -fv.total[2] as monto_bolivar
+			FROM venta.facturav as fv
+			LEFT JOIN factura_susc as fs ON fs.facturav_id=fv.id AND fs.created_at=fv.created_at
+			WHERE fv.cliente_id=$1 AND fv.estatus<>'anulado'
+			UNION ALL
+			SELECT rp.fecha::date, rp.created_at, 'pago', rp.id::text, COALESCE(rp.referencia, ''), COALESCE((p->'suscripcion'->>'id')::bigint, fs.suscripcion_id),
+				(p->'monto'->>'dolar')::numeric, (p->'monto'->>'bolivar')::numeric
+			FROM venta.recibo_pagov as rp
+			CROSS JOIN LATERAL jsonb_array_elements(COALESCE(rp.info->'payment_detail', '[]'::jsonb)) as p
+			LEFT JOIN factura_susc as fs ON fs.facturav_id::text=p->'factura'->>'id'
+			WHERE rp.cliente_id=$1 AND rp.estatus='procesado'
+			UNION ALL
+			SELECT rp.fecha::date, rp.created_at, 'pago_sin_asignar', rp.id::text, COALESCE(rp.referencia, ''), (rp.info->'payment_detail'->0->'suscripcion'->>'id')::bigint,
+				rp.monto[1] - COALESCE(d.dolar, 0), rp.monto[2] - COALESCE(d.bolivar, 0)
+			FROM venta.recibo_pagov as rp
+			LEFT JOIN LATERAL (
+				SELECT SUM((p->'monto'->>'dolar')::numeric) as dolar, SUM((p->'monto'->>'bolivar')::numeric) as bolivar
+				FROM jsonb_array_elements(COALESCE(rp.info->'payment_detail', '[]'::jsonb)) as p
+			) as d ON true
+			WHERE rp.cliente_id=$1 AND rp.estatus='procesado' AND ROUND(rp.monto[1] - COALESCE(d.dolar, 0), 2)<>0
+			UNION ALL
+			SELECT r.fecha_retencion::date, r.created_at, 'retencion', r.id::text, COALESCE(r.num_comprobante, ''), fs.suscripcion_id,
+				r.monto_retenido[1], r.monto_retenido[2]
+			FROM venta.facturav_retencion as r
+			JOIN venta.facturav as fv ON fv.id=r.facturav_id AND fv.created_at=r.facturav_created_at
+			LEFT JOIN factura_susc as fs ON fs.facturav_id=fv.id AND fs.created_at=fv.created_at
+			WHERE fv.cliente_id=$1 AND r.estatus<>'anulado'
+			UNION ALL
+			SELECT t.created_at::date, t.created_at, 'transferencia_enviada', t.id::text, COALESCE(t.info->>'destinatario_docid', ''), t.suscripcion_origen_id,
+				-t.monto[1], -t.monto[2]
+			FROM venta.transferenciav as t
+			WHERE t.cliente_origen_id=$1
+			UNION ALL
+			SELECT t.created_at::date, t.created_at, 'transferencia_recibida', t.id::text, COALESCE(c.docid, ''), t.suscripcion_destino_id,
+				t.monto[1], t.monto[2]
+			FROM venta.transferenciav as t
+			LEFT JOIN publico.cliente as c ON c.id=t.cliente_origen_id
+			WHERE t.cliente_destino_id=$1
+		) as union_mov`
+
+// compare the final balance of each suscripcion with venta.get_saldo, a difference means the
+// statement is missing movements that the saldo has (or the opposite), it is logged to be fixed
+func checkEstadoCuentaSaldo(db models.ConnDb, clienteId string, suscripciones map[int64]*models.EstadoCuentaSuscripcion) {
+	query := `SELECT COALESCE(suscripcion_id, 0), COALESCE(ROUND(saldo[1], 2), 0) FROM venta.get_saldo(1, $1, 'procesado')`
+	rows, err := db.ConnPgsql.Query(db.Ctx, query, clienteId)
+	if err != nil {
+		utils.Logline("error on select saldo to check estado de cuenta", clienteId, err)
+		return
+	}
+	defer rows.Close()
+
+	saldos := map[int64]float64{}
+	for rows.Next() {
+		var suscripcionId int64
+		var saldo float64
+		if err := rows.Scan(&suscripcionId, &saldo); err != nil {
+			utils.Logline("error scanning saldo to check estado de cuenta", clienteId, err)
+			return
+		}
+		saldos[suscripcionId] += saldo
+	}
+	rows.Close()
+
+	for suscripcionId := range suscripciones {
+		if _, ok := saldos[suscripcionId]; !ok {
+			saldos[suscripcionId] = 0
+		}
+	}
+	for suscripcionId, saldo := range saldos {
+		saldoFinal := 0.0
+		if suscripcion, ok := suscripciones[suscripcionId]; ok {
+			saldoFinal = suscripcion.SaldoFinal.Dolar
+		}
+		if math.Abs(saldoFinal-saldo) >= 0.01 {
+			utils.Logline("estado de cuenta does not match get_saldo", clienteId, suscripcionId, saldoFinal, saldo)
+		}
+	}
+}
+
+// movements of the cliente from desde until hasta in chronological order
+func getEstadoCuentaMovimientos(db models.ConnDb, clienteId string, desde string, hasta string) ([]estadoCuentaMovimiento, error) {
+	query := `SELECT mov.fecha::text, mov.tipo, mov.id, mov.documento, COALESCE(mov.suscripcion_id, 0), COALESCE(mov.monto_dolar, 0), COALESCE(mov.monto_bolivar, 0)
+		FROM (` + estadoCuentaMovimientosQuery + `) as mov
+		WHERE mov.fecha >= $2::date AND mov.fecha <= $3::date
+		ORDER BY mov.fecha ASC, mov.created_at ASC`
+	rows, err := db.ConnPgsql.Query(db.Ctx, query, clienteId, desde, hasta)
+	if err != nil {
+		utils.Logline("error on select movimientos for estado de cuenta", clienteId, err)
+		return nil, errors.New("errorGetData")
+	}
+	defer rows.Close()
+
+	var movimientos []estadoCuentaMovimiento
+	for rows.Next() {
+		var mov estadoCuentaMovimiento
+		err := rows.Scan(&mov.movimiento.Fecha, &mov.movimiento.Tipo, &mov.movimiento.Id, &mov.movimiento.Documento, &mov.suscripcionId,
+			&mov.monto.Dolar, &mov.monto.Bolivar)
+		if err != nil {
+			utils.Logline("error scanning movimientos for estado de cuenta", clienteId, err)
+			return nil, errors.New("errorGetData")
+		}
+
+		mov.movimiento.Ncontrol = utils.GenerateNcontrolByUuid(mov.movimiento.Id)
+		if mov.monto.Dolar < 0 {
+			mov.movimiento.Debe = models.Moneda{Dolar: utils.RoundToTwoDecimalPlaces(-mov.monto.Dolar), Bolivar: utils.RoundToTwoDecimalPlaces(-mov.monto.Bolivar)}
+		} else {
+			mov.movimiento.Haber = models.Moneda{Dolar: utils.RoundToTwoDecimalPlaces(mov.monto.Dolar), Bolivar: utils.RoundToTwoDecimalPlaces(mov.monto.Bolivar)}
+		}
+		movimientos = append(movimientos, mov)
+	}
+
+	return movimientos, nil
+}
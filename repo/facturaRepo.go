@@ -12,7 +12,8 @@ import (
 )
 
 func GetFactura(db models.ConnDb, clienteId string, facturaReq models.FacturaReqId) (*models.FacturaResponse, int, error) {
-	query := `SELECT fv.id, fv.estatus, fv.created_at, 
+	query := `SELECT fv.id, fv.estatus, fv.created_at, fv.updated_at, COALESCE(fv.ncontrol, '') as ncontrol, COALESCE(fv.nfactura, '') as nfactura,
+			fv.tipo, fv.fecha::text, COALESCE(fv.tasa_cambio, 0) as tasa_cambio,
 			fv.subtotal[1] as subtotal_dolar, fv.subtotal[2] as subtotal_bolivar, 
 			fv.desc_porc, fv.desc_monto[1] as desc_monto_dolar, fv.desc_monto[2] as desc_monto_bolivar, 
 			fv.base_imp[1] as base_imp_dolar, fv.base_imp[2] as base_imp_bolivar, 
@@ -25,7 +26,8 @@ func GetFactura(db models.ConnDb, clienteId string, facturaReq models.FacturaReq
 
 	var factura models.FacturaResponse
 
-	err := db.ConnPgsql.QueryRow(db.Ctx, query, clienteId, facturaReq.CreatedAt, facturaReq.Id).Scan(&factura.Id, &factura.Estatus, &factura.CreatedAt,
+	err := db.ConnPgsql.QueryRow(db.Ctx, query, clienteId, facturaReq.CreatedAt, facturaReq.Id).Scan(&factura.Id, &factura.Estatus, &factura.CreatedAt, &factura.UpdatedAt, &factura.NControl, &factura.NFactura,
+		&factura.TipoFactura, &factura.Fecha, &factura.TasaCambio,
 		&factura.SubTotal.Dolar, &factura.SubTotal.Bolivar,
 		&factura.DescPorc, &factura.DescMonto.Dolar, &factura.DescMonto.Bolivar,
 		&factura.BaseImponible.Dolar, &factura.BaseImponible.Bolivar,
@@ -208,3 +210,43 @@ func GetFacturaRetencion(db models.ConnDb, clienteId string, facturaReq models.F
 
 	return &factura, nil
 }
+
+// pdf of the factura, it is rendered again when the factura or any of its payments
+// or retenciones (they are printed on the pdf) is added, updated or removed
+func FacturaPdf(db models.ConnDb, clienteId string, facturaReq models.FacturaReqId) ([]byte, int, error) {
+	var version string
+	query := `SELECT fv.updated_at::text
+			|| '|' || (SELECT COUNT(*) || '|' || COALESCE(MAX(rp.updated_at)::text, '') FROM venta.recibo_pagov as rp
+				WHERE rp.cliente_id=fv.cliente_id AND rp.info->'payment_detail'->0->'factura'->>'id'=fv.id::text)
+			|| '|' || (SELECT COUNT(*) || '|' || COALESCE(MAX(r.updated_at)::text, '') FROM venta.facturav_retencion as r
+				WHERE r.facturav_id=fv.id AND r.facturav_created_at=fv.created_at)
+		FROM venta.facturav as fv
+		WHERE fv.cliente_id=$1 AND DATE(fv.created_at)=DATE($2) AND fv.id=$3`
+	if err := db.ConnPgsql.QueryRow(db.Ctx, query, clienteId, facturaReq.CreatedAt, facturaReq.Id).Scan(&version); err != nil {
+		utils.Logline("error getting venta.facturav", err, clienteId, facturaReq)
+		return nil, http.StatusBadRequest, errors.New("errorGetData")
+	}
+
+	version = utils.HashToken(version)[:16]
+	if content, ok := utils.GetFacturaPdfCache(facturaReq.Id, version); ok {
+		return content, http.StatusOK, nil
+	}
+
+	factura, errType, err := GetFactura(db, clienteId, facturaReq)
+	if err != nil {
+		return nil, errType, err
+	}
+
+	content, err := utils.GenerateFacturaPdf(*factura)
+	if err != nil {
+		utils.Logline("error generating pdf of factura", facturaReq, err)
+		return nil, http.StatusInternalServerError, errors.New("errorInternal")
+	}
+
+	// the pdf is returned even if the cache could not be saved
+	if err := utils.SaveFacturaPdfCache(facturaReq.Id, version, content); err != nil {
+		utils.Logline("error saving pdf of factura on cache", facturaReq, err)
+	}
+
+	return content, http.StatusOK, nil
+}
@@ -0,0 +1,78 @@
+package repo
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/jackc/pgx/v5"
+	"ired.com/micuenta/models"
+	"ired.com/micuenta/utils"
+)
+
+// hours that a key is kept to replay the response
+const idempotencyKeyHrs = 24
+
+// reserve the key for the request, if the key was used before the saved
+// response is returned to replay it
+func IdempotencyBegin(db models.ConnDb, clienteId any, llave string, ruta string, fingerprint string) (*models.IdempotencyRecord, int, error) {
+	// keys older than the window are taken as new
+	query := `DELETE FROM publico.idempotency_key WHERE cliente_id=$1 AND llave=$2 AND created_at < NOW() - make_interval(hours => $3)`
+	if _, err := db.ConnPgsql.Exec(db.Ctx, query, clienteId, llave, idempotencyKeyHrs); err != nil {
+		utils.Logline("error deleting old idempotency_key", clienteId, llave, err)
+		return nil, http.StatusInternalServerError, errors.New("errorInternal")
+	}
+
+	query = `INSERT INTO publico.idempotency_key (cliente_id, llave, ruta, fingerprint) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (cliente_id, llave) DO NOTHING`
+	result, err := db.ConnPgsql.Exec(db.Ctx, query, clienteId, llave, ruta, fingerprint)
+	if err != nil {
+		utils.Logline("error inserting idempotency_key", clienteId, llave, err)
+		return nil, http.StatusInternalServerError, errors.New("errorInternal")
+	}
+	if result.RowsAffected() == 1 {
+		return nil, http.StatusOK, nil
+	}
+
+	var record models.IdempotencyRecord
+	var savedRuta, savedFingerprint string
+	query = `SELECT ruta, fingerprint, estatus, COALESCE(response_status, 0), COALESCE(response_body, '')
+		FROM publico.idempotency_key WHERE cliente_id=$1 AND llave=$2`
+	err = db.ConnPgsql.QueryRow(db.Ctx, query, clienteId, llave).Scan(&savedRuta, &savedFingerprint, &record.Estatus,
+		&record.ResponseStatus, &record.ResponseBody)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			// released by the other request in the meantime
+			return nil, http.StatusConflict, errors.New("idempotencyInProgress")
+		}
+		utils.Logline("error getting idempotency_key", clienteId, llave, err)
+		return nil, http.StatusInternalServerError, errors.New("errorInternal")
+	}
+
+	if savedRuta != ruta || savedFingerprint != fingerprint {
+		return nil, http.StatusUnprocessableEntity, errors.New("idempotencyKeyMismatch")
+	}
+	if record.Estatus != "completado" {
+		return nil, http.StatusConflict, errors.New("idempotencyInProgress")
+	}
+
+	return &record, http.StatusOK, nil
+}
+
+// save the response of the request to replay it on the retries
+func IdempotencySave(db models.ConnDb, clienteId any, llave string, status int, body string) error {
+	query := `UPDATE publico.idempotency_key SET estatus='completado', response_status=$3, response_body=$4
+		WHERE cliente_id=$1 AND llave=$2`
+	if _, err := db.ConnPgsql.Exec(db.Ctx, query, clienteId, llave, status, body); err != nil {
+		utils.Logline("error updating idempotency_key", clienteId, llave, err)
+		return err
+	}
+	return nil
+}
+
+// free the key when the request failed, so the retry is processed again
+func IdempotencyRelease(db models.ConnDb, clienteId any, llave string) {
+	query := `DELETE FROM publico.idempotency_key WHERE cliente_id=$1 AND llave=$2 AND estatus='procesando'`
+	if _, err := db.ConnPgsql.Exec(db.Ctx, query, clienteId, llave); err != nil {
+		utils.Logline("error deleting idempotency_key", clienteId, llave, err)
+	}
+}
@@ -0,0 +1,140 @@
+package repo
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"ired.com/micuenta/models"
+	"ired.com/micuenta/utils"
+)
+
+const (
+	// failed logins before the account or ip is locked
+	loginMaxFailsDocid = 5
+	loginMaxFailsIp    = 20
+	loginLockMinutes   = 15
+	// after this failed logins every attempt must wait a growing delay
+	loginDelayFrom     = 3
+	loginMaxDelaySecs  = 30
+	loginFailWindowHrs = 24
+)
+
+type loginAttempt struct {
+	Tipo           string
+	Fallos         int
+	UltimoFallo    time.Time
+	BloqueadoHasta *time.Time
+}
+
+// the attempt is counted as failed before the password is compared, so the requests at the same time
+// can not pass the check all together. The row of the counter is locked by the upsert and it is only
+// updated when the counter is not locked or waiting the progressive delay (1s, 2s, 4s... since the
+// last attempt). Counters older than the window or with a lock already ended start again
+const reserveLoginAttemptQuery = `INSERT INTO publico.auth_login_attempt (tipo, llave, fallos, ultimo_fallo) VALUES ($1, $2, 1, NOW())
+	ON CONFLICT (tipo, llave) DO UPDATE SET
+		fallos=CASE WHEN auth_login_attempt.ultimo_fallo < NOW() - make_interval(hours => $5) OR auth_login_attempt.bloqueado_hasta <= NOW()
+			THEN 1 ELSE auth_login_attempt.fallos + 1 END,
+		ultimo_fallo=NOW(),
+		bloqueado_hasta=CASE WHEN auth_login_attempt.ultimo_fallo < NOW() - make_interval(hours => $5) OR auth_login_attempt.bloqueado_hasta <= NOW()
+			THEN NULL
+			WHEN auth_login_attempt.fallos + 1 >= $3 THEN NOW() + make_interval(mins => $4)
+			ELSE auth_login_attempt.bloqueado_hasta END
+	WHERE (auth_login_attempt.bloqueado_hasta IS NULL OR auth_login_attempt.bloqueado_hasta <= NOW())
+		AND (auth_login_attempt.fallos < $6 OR auth_login_attempt.ultimo_fallo < NOW() - make_interval(hours => $5)
+			OR auth_login_attempt.ultimo_fallo + make_interval(secs => LEAST(POWER(2, auth_login_attempt.fallos - $6), $7::integer)) <= NOW())
+	RETURNING fallos, bloqueado_hasta`
+
+// reserve the attempt on the ip and docid counters before validating the password, when one of them
+// is locked or must wait it returns 429 with the seconds to wait on the Retry-After header. The ip is
+// reserved first so an ip locked does not add attempts to the docid of other clientes
+func reserveLoginAttempt(c *gin.Context, db models.ConnDb, docid string) (int, error) {
+	attempts := []struct {
+		tipo     string
+		llave    string
+		maxFails int
+	}{
+		{"ip", c.ClientIP(), loginMaxFailsIp},
+		{"docid", docid, loginMaxFailsDocid},
+	}
+
+	for i, attempt := range attempts {
+		var fallos int
+		var bloqueadoHasta *time.Time
+		err := db.ConnPgsql.QueryRow(db.Ctx, reserveLoginAttemptQuery, attempt.tipo, attempt.llave, attempt.maxFails, loginLockMinutes,
+			loginFailWindowHrs, loginDelayFrom, loginMaxDelaySecs).Scan(&fallos, &bloqueadoHasta)
+		if err != nil {
+			if !errors.Is(err, pgx.ErrNoRows) {
+				utils.Logline("error updating auth_login_attempt", attempt.tipo, attempt.llave, err)
+				return http.StatusInternalServerError, errors.New("errorInternal")
+			}
+
+			// the ip was reserved but the docid is not available, the attempt did not happen
+			if i > 0 {
+				releaseLoginAttempt(db, "ip", c.ClientIP(), loginMaxFailsIp)
+			}
+			return loginAttemptWait(c, db, attempt.tipo, attempt.llave)
+		}
+
+		if fallos == attempt.maxFails && bloqueadoHasta != nil {
+			logAuthEvent(c, db, nil, "login_locked", map[string]any{"tipo": attempt.tipo, "llave": attempt.llave, "hasta": bloqueadoHasta})
+		}
+	}
+
+	return http.StatusOK, nil
+}
+
+// the time the counter must wait to be reserved again, set on the Retry-After header
+func loginAttemptWait(c *gin.Context, db models.ConnDb, tipo string, llave string) (int, error) {
+	var attempt loginAttempt
+	query := `SELECT tipo, fallos, ultimo_fallo, bloqueado_hasta FROM publico.auth_login_attempt WHERE tipo=$1 AND llave=$2`
+	err := db.ConnPgsql.QueryRow(db.Ctx, query, tipo, llave).Scan(&attempt.Tipo, &attempt.Fallos, &attempt.UltimoFallo, &attempt.BloqueadoHasta)
+	if err != nil {
+		utils.Logline("error getting auth_login_attempt", tipo, llave, err)
+		return http.StatusInternalServerError, errors.New("errorInternal")
+	}
+
+	now := time.Now()
+	locked := attempt.BloqueadoHasta != nil && now.Before(*attempt.BloqueadoHasta)
+	wait := time.Second
+	if locked {
+		wait = attempt.BloqueadoHasta.Sub(now)
+	} else if attempt.Fallos >= loginDelayFrom {
+		delay := time.Duration(math.Min(math.Pow(2, float64(attempt.Fallos-loginDelayFrom)), loginMaxDelaySecs)) * time.Second
+		wait = max(wait, attempt.UltimoFallo.Add(delay).Sub(now))
+	}
+
+	c.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+	if locked {
+		return http.StatusTooManyRequests, errors.New("loginLocked")
+	}
+	return http.StatusTooManyRequests, errors.New("loginDelay")
+}
+
+// give back an attempt reserved that was not a failed login, the lock set by it is removed too
+func releaseLoginAttempt(db models.ConnDb, tipo string, llave string, maxFails int) {
+	query := `UPDATE publico.auth_login_attempt SET fallos=GREATEST(fallos - 1, 0),
+			bloqueado_hasta=CASE WHEN fallos - 1 < $3 THEN NULL ELSE bloqueado_hasta END
+		WHERE tipo=$1 AND llave=$2`
+	if _, err := db.ConnPgsql.Exec(db.Ctx, query, tipo, llave, maxFails); err != nil {
+		utils.Logline("error releasing auth_login_attempt", tipo, llave, err)
+	}
+}
+
+// after a successful login the docid counter is cleared and the attempt of the ip given back
+func loginAttemptSucceeded(c *gin.Context, db models.ConnDb, docid string) {
+	clearLoginAttempts(db, docid)
+	releaseLoginAttempt(db, "ip", c.ClientIP(), loginMaxFailsIp)
+}
+
+// clear the counter of the docid after a successful login or password reset
+func clearLoginAttempts(db models.ConnDb, docid string) {
+	query := `DELETE FROM publico.auth_login_attempt WHERE tipo='docid' AND llave=$1`
+	if _, err := db.ConnPgsql.Exec(db.Ctx, query, docid); err != nil {
+		utils.Logline("error deleting auth_login_attempt", docid, err)
+	}
+}
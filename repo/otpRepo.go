@@ -0,0 +1,173 @@
+package repo
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"ired.com/micuenta/models"
+	"ired.com/micuenta/utils"
+)
+
+const (
+	otpDigits      = 6
+	otpMaxAttempts = 5
+)
+
+type otpInternal struct {
+	Id        string
+	ClienteId string
+	Canal     string
+	Destino   string
+	Info      map[string]any
+}
+
+// create a new code for the cliente and proposito, previous codes not used are discarded
+func createOtp(db models.ConnDb, clienteId string, proposito string, canal string, destino string, info map[string]any) (string, error) {
+	otpMaxAge, err := strconv.Atoi(os.Getenv("OTP_MAX_AGE"))
+	if err != nil {
+		utils.Logline("error parsing OTP_MAX_AGE", err)
+		return "", err
+	}
+
+	code, err := utils.GenerateNumericCode(otpDigits)
+	if err != nil {
+		utils.Logline("error generating otp code", err)
+		return "", err
+	}
+	if info == nil {
+		info = map[string]any{}
+	}
+
+	query := `DELETE FROM publico.cliente_otp WHERE cliente_id=$1 AND proposito=$2 AND used_at IS NULL`
+	if _, err := db.ConnPgsql.Exec(db.Ctx, query, clienteId, proposito); err != nil {
+		utils.Logline("error deleting previous cliente_otp", clienteId, proposito, err)
+		return "", err
+	}
+
+	query = `INSERT INTO publico.cliente_otp (empresa_id, cliente_id, proposito, canal, destino, code_hash, info, expires_at)
+		VALUES (1, $1, $2, $3, $4, $5, $6, $7)`
+	_, err = db.ConnPgsql.Exec(db.Ctx, query, clienteId, proposito, canal, destino, utils.HashToken(code), info,
+		time.Now().Add(time.Duration(otpMaxAge)*time.Second))
+	if err != nil {
+		utils.Logline("error inserting cliente_otp", clienteId, proposito, err)
+		return "", err
+	}
+
+	return code, nil
+}
+
+// validate the code of the cliente and proposito, the code is burned after
+// otpMaxAttempts failures and can be used only once
+func verifyOtp(db models.ConnDb, clienteId string, proposito string, code string) (*otpInternal, int, error) {
+	otp, errType, err := checkOtp(db, clienteId, proposito, code)
+	if err != nil {
+		return nil, errType, err
+	}
+
+	// consume the code, if other request used it first it is not valid
+	consumed, err := consumeOtp(db, db.ConnPgsql, otp.Id)
+	if err != nil {
+		return nil, http.StatusInternalServerError, errors.New("errorInternal")
+	}
+	if !consumed {
+		return nil, http.StatusUnauthorized, errors.New("otpInvalid")
+	}
+
+	return otp, http.StatusOK, nil
+}
+
+// validate the code like verifyOtp without consuming it, the caller must consume it with consumeOtp
+// (on the same transaction of what the code authorizes, so a failure does not burn the code)
+func checkOtp(db models.ConnDb, clienteId string, proposito string, code string) (*otpInternal, int, error) {
+	var otp otpInternal
+	var codeHash string
+	var intentos int
+	query := `SELECT id::text, cliente_id::text, canal, destino, code_hash, intentos, info FROM publico.cliente_otp
+		WHERE cliente_id=$1 AND proposito=$2 AND used_at IS NULL AND expires_at>NOW()
+		ORDER BY created_at DESC LIMIT 1`
+	err := db.ConnPgsql.QueryRow(db.Ctx, query, clienteId, proposito).Scan(&otp.Id, &otp.ClienteId, &otp.Canal, &otp.Destino,
+		&codeHash, &intentos, &otp.Info)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, http.StatusUnauthorized, errors.New("otpInvalid")
+		}
+		utils.Logline("error getting cliente_otp", clienteId, proposito, err)
+		return nil, http.StatusInternalServerError, errors.New("errorInternal")
+	}
+
+	if codeHash != utils.HashToken(code) {
+		query = `UPDATE publico.cliente_otp SET intentos=intentos+1, used_at=CASE WHEN intentos+1>=$2 THEN NOW() ELSE NULL END WHERE id=$1`
+		if _, err := db.ConnPgsql.Exec(db.Ctx, query, otp.Id, otpMaxAttempts); err != nil {
+			utils.Logline("error updating cliente_otp", otp.Id, err)
+		}
+		return nil, http.StatusUnauthorized, errors.New("otpInvalid")
+	}
+
+	return &otp, http.StatusOK, nil
+}
+
+// mark the code as used, false when other request used it first
+func consumeOtp(db models.ConnDb, conn pgQuerier, otpId string) (bool, error) {
+	query := `UPDATE publico.cliente_otp SET used_at=NOW() WHERE id=$1 AND used_at IS NULL`
+	result, err := conn.Exec(db.Ctx, query, otpId)
+	if err != nil {
+		utils.Logline("error updating cliente_otp", otpId, err)
+		return false, err
+	}
+
+	return result.RowsAffected() == 1, nil
+}
+
+// send the code by email using the same layout of the other emails
+func sendOtpEmail(to []string, subject string, titulo string, mensaje string, code string) error {
+	bodyEmail := `
+		<!DOCTYPE html>
+		<html lang="en">
+			<head>
+				<meta charset="UTF-8">
+				<meta name="viewport" content="width=device-width, initial-scale=1.0">
+				<title>` + titulo + `</title>
+				<style>
+					body {font-family: Arial, sans-serif; background-color: #f6f8fa; margin: 0;padding: 0; }
+					.container {width: 100%; max-width: 600px; margin: 0 auto; padding: 20px;}
+					.header {text-align: center; padding: 20px 0;}
+					.header img {width: 200px;}
+					.content {padding: 20px; border: 1px solid #e1e4e8; border-radius: 5px;}
+					.content h1 {font-size: 24px; color: #333333; text-align: center;}
+					.content p {font-size: 16px; color: #333333;}
+					.code {display: block; width: 200px; margin: 20px auto; padding: 10px 0; text-align: center; font-size: 28px; letter-spacing: 6px; font-weight: bold; color: #28a745;}
+					.footer {text-align: center; padding: 20px; font-size: 12px; color: #666666;}
+				</style>
+			</head>
+			<body>
+				<div class="container">
+					<div class="header">
+						<img src="cid:image001" alt="Besser Solutions Logo">
+						<h1>` + titulo + `</h1>
+					</div>
+					<div class="content">
+						<b>Hola, </b>
+						<p>` + mensaje + `</p>
+						<span class="code">` + code + `</span>
+						<p>Si no utilizas este codigo en ` + strconv.Itoa(otpExpiresMinutes()) + ` minutos, caducará. No lo compartas con nadie.</p>
+						<p>Gracias,<br>El equipo de Besser Solutions</p>
+					</div>
+					<div class="footer">
+						<p>Recibiste este correo electrónico porque se solicitó un codigo de verificación para tu cuenta.</p>
+						<p>Besser Solutions, C.A. • Santa Irene, Calle San Miguel, Edif. Asdrubal Jose PB • Punto Fijo, Falcon 4102</p>
+					</div>
+				</div>
+			</body>
+		</html>
+	`
+	return utils.SendEmail(to, subject, bodyEmail)
+}
+
+func otpExpiresMinutes() int {
+	otpMaxAge, _ := strconv.Atoi(os.Getenv("OTP_MAX_AGE"))
+	return otpMaxAge / 60
+}
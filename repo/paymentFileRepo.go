@@ -0,0 +1,374 @@
+package repo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/jackc/pgx/v5"
+	"ired.com/micuenta/models"
+	"ired.com/micuenta/utils"
+)
+
+// attachment of a payment saved on info.files, the first one is also on info.file_key
+type paymentFileInternal struct {
+	Key         string `json:"key"`
+	ThumbKey    string `json:"thumb_key,omitempty"`
+	ContentType string `json:"content_type"`
+	Size        int    `json:"size"`
+	Nombre      string `json:"nombre"`
+	CreatedAt   string `json:"created_at"`
+}
+
+// max number of attachments by payment, PAYMENT_MAX_FILES or 5
+func paymentMaxFiles() int {
+	maxFiles, err := strconv.Atoi(os.Getenv("PAYMENT_MAX_FILES"))
+	if err != nil || maxFiles <= 0 {
+		return 5
+	}
+	return maxFiles
+}
+
+// save the proofs of a payment on the blob store, the type is validated by the content
+// and the images are saved without metadata with a thumbnail, the keys are added to info.files
+func ImageUpload(c *gin.Context, db models.ConnDb, files []*multipart.FileHeader, paymentReq models.PaymentReqId) (int, error) {
+	userId, _ := c.Get("userId")
+	clienteId := fmt.Sprintf("%s", userId)
+
+	current, err := getPaymentFiles(db, clienteId, paymentReq)
+	if err != nil {
+		return http.StatusBadRequest, errors.New("vePaymentId")
+	}
+	if len(current)+len(files) > paymentMaxFiles() {
+		return http.StatusBadRequest, errors.New("veFileMax")
+	}
+
+	//validate all the files before saving any of them
+	processed := make([]*utils.ProcessedFile, len(files))
+	for i, file := range files {
+		if file.Size > utils.PaymentFileMaxSize() {
+			return http.StatusBadRequest, errors.New("veFileSize")
+		}
+
+		src, err := file.Open()
+		if err != nil {
+			return http.StatusBadRequest, errors.New("veFileError")
+		}
+		content, err := io.ReadAll(io.LimitReader(src, utils.PaymentFileMaxSize()+1))
+		src.Close()
+		if err != nil {
+			return http.StatusBadRequest, errors.New("veFileError")
+		}
+
+		processed[i], err = utils.ProcessPaymentFile(content)
+		if err != nil {
+			switch {
+			case errors.Is(err, utils.ErrFileSize):
+				return http.StatusBadRequest, errors.New("veFileSize")
+			case errors.Is(err, utils.ErrFileDimension):
+				return http.StatusBadRequest, errors.New("veFileDimension")
+			case errors.Is(err, utils.ErrFileType):
+				return http.StatusBadRequest, errors.New("veFileExtError")
+			case errors.Is(err, utils.ErrFileInvalid):
+				return http.StatusBadRequest, errors.New("veFileInvalid")
+			}
+			utils.Logline("error processing payment file", file.Filename, err)
+			return http.StatusBadRequest, errors.New("veFileError")
+		}
+	}
+
+	store, err := utils.GetBlobStore()
+	if err != nil {
+		utils.Logline("error getting blob store", err)
+		return http.StatusInternalServerError, errors.New("veFileError")
+	}
+
+	// on error the files already saved are removed
+	var saved []string
+	removeSaved := func() {
+		for _, key := range saved {
+			if err := store.Delete(context.Background(), key); err != nil {
+				utils.Logline("error deleting payment file from blob store", key, err)
+			}
+		}
+	}
+
+	var attachments []paymentFileInternal
+	for i, file := range processed {
+		//generate key of the file
+		name := fmt.Sprintf("%s_%s", clienteId, utils.GenerateUUID())
+		folder := "payments/" + time.Now().Format("2006/01/02")
+		attachment := paymentFileInternal{
+			Key:         folder + "/" + name + file.Ext,
+			ContentType: file.ContentType,
+			Size:        len(file.Content),
+			Nombre:      filepath.Base(files[i].Filename),
+			CreatedAt:   time.Now().Format(time.RFC3339),
+		}
+
+		// Save the file
+		if err := store.Put(db.Ctx, attachment.Key, file.Content, file.ContentType); err != nil {
+			utils.Logline("error saving payment file on blob store", attachment.Key, err)
+			removeSaved()
+			return http.StatusBadRequest, errors.New("veFileError")
+		}
+		saved = append(saved, attachment.Key)
+
+		if file.Thumbnail != nil {
+			attachment.ThumbKey = folder + "/thumb_" + name + ".jpg"
+			if err := store.Put(db.Ctx, attachment.ThumbKey, file.Thumbnail, "image/jpeg"); err != nil {
+				utils.Logline("error saving payment thumbnail on blob store", attachment.ThumbKey, err)
+				removeSaved()
+				return http.StatusBadRequest, errors.New("veFileError")
+			}
+			saved = append(saved, attachment.ThumbKey)
+		}
+
+		attachments = append(attachments, attachment)
+	}
+
+	attachmentsJson, err := json.Marshal(attachments)
+	if err != nil {
+		utils.Logline("error marshaling payment files", err)
+		removeSaved()
+		return http.StatusInternalServerError, errors.New("veFileError")
+	}
+
+	// the max of files is checked again with the lock of the row
+	var reciboPagoId string
+	query := `UPDATE venta.recibo_pagov
+		SET info = info || jsonb_build_object(
+			'files', COALESCE(info->'files', '[]'::jsonb) || $1::jsonb,
+			'file_key', CASE WHEN COALESCE(info->>'file_key', '')<>'' THEN info->>'file_key' ELSE $1::jsonb->0->>'key' END,
+			'url_file', '')
+		WHERE empresa_id = 1 AND cliente_id=$2 AND created_at=$3 AND id = $4
+			AND jsonb_array_length(COALESCE(info->'files', '[]'::jsonb)) + jsonb_array_length($1::jsonb) <= $5 RETURNING id`
+	err = db.ConnPgsql.QueryRow(db.Ctx, query, string(attachmentsJson), clienteId, paymentReq.CreatedAt, paymentReq.PaymentId, paymentMaxFiles()).Scan(&reciboPagoId)
+	if err != nil {
+		removeSaved()
+		if errors.Is(err, pgx.ErrNoRows) {
+			return http.StatusBadRequest, errors.New("veFileMax")
+		}
+		utils.Logline("error updating recibo_pagov", err)
+		return http.StatusBadRequest, errors.New("veFileError")
+	}
+
+	return http.StatusOK, nil
+}
+
+// short-lived url to download a file of the payment, signed with FILE_URL_SECRET
+func paymentFileUrl(clienteId string, paymentReq models.PaymentReqId, fileKey string) (string, time.Time, error) {
+	maxAge, err := strconv.Atoi(os.Getenv("FILE_URL_MAX_AGE"))
+	if err != nil {
+		maxAge = 300
+	}
+	expiresAt := time.Now().Add(time.Duration(maxAge) * time.Second)
+
+	claims := &models.PaymentFileClaims{
+		ClienteId: clienteId,
+		PaymentId: paymentReq.PaymentId,
+		CreatedAt: paymentReq.CreatedAt,
+		FileKey:   fileKey,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    "micuenta",
+			Audience:  jwt.ClaimStrings{"file"},
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	tokenString, err := token.SignedString([]byte(os.Getenv("FILE_URL_SECRET")))
+	if err != nil {
+		utils.Logline("error signing payment file url", err)
+		return "", time.Time{}, err
+	}
+
+	return "/payment/file?token=" + url.QueryEscape(tokenString), expiresAt, nil
+}
+
+// signed urls of all the files of the payment
+func paymentFileUrls(clienteId string, paymentReq models.PaymentReqId, files []paymentFileInternal) ([]models.PaymentFile, error) {
+	paymentFiles := []models.PaymentFile{}
+	for _, file := range files {
+		// proofs synced from mysql with the url of the old system, public there as before
+		if isLegacyFileUrl(file.Key) {
+			paymentFiles = append(paymentFiles, models.PaymentFile{Nombre: file.Nombre, ContentType: file.ContentType, Size: file.Size, Url: file.Key})
+			continue
+		}
+
+		fileUrl, expiresAt, err := paymentFileUrl(clienteId, paymentReq, file.Key)
+		if err != nil {
+			return nil, err
+		}
+		paymentFile := models.PaymentFile{
+			Nombre:      file.Nombre,
+			ContentType: file.ContentType,
+			Size:        file.Size,
+			Url:         fileUrl,
+			ExpiresAt:   expiresAt.Format(time.RFC3339),
+		}
+		if file.ThumbKey != "" {
+			if paymentFile.ThumbUrl, _, err = paymentFileUrl(clienteId, paymentReq, file.ThumbKey); err != nil {
+				return nil, err
+			}
+		}
+		paymentFiles = append(paymentFiles, paymentFile)
+	}
+
+	return paymentFiles, nil
+}
+
+func PaymentFileUrl(db models.ConnDb, clienteId string, paymentReq models.PaymentReqId) ([]models.PaymentFile, int, error) {
+	files, err := getPaymentFiles(db, clienteId, paymentReq)
+	if err != nil {
+		return nil, http.StatusNotFound, err
+	}
+	if len(files) == 0 {
+		return nil, http.StatusNotFound, errors.New("fileNotFound")
+	}
+
+	paymentFiles, err := paymentFileUrls(clienteId, paymentReq, files)
+	if err != nil {
+		return nil, http.StatusInternalServerError, errors.New("errorInternal")
+	}
+
+	return paymentFiles, http.StatusOK, nil
+}
+
+// files of the payment, the recibo must belong to the cliente. Payments uploaded
+// before the attachments only have info.file_key (or the path on info.url_file)
+func getPaymentFiles(db models.ConnDb, clienteId string, paymentReq models.PaymentReqId) ([]paymentFileInternal, error) {
+	var filesJson, fileKey string
+	query := `SELECT COALESCE(info->'files', '[]'::jsonb)::text, COALESCE(NULLIF(info->>'file_key', ''), info->>'url_file', '') FROM venta.recibo_pagov
+		WHERE empresa_id=1 AND cliente_id=$1 AND created_at=$2 AND id=$3`
+	err := db.ConnPgsql.QueryRow(db.Ctx, query, clienteId, paymentReq.CreatedAt, paymentReq.PaymentId).Scan(&filesJson, &fileKey)
+	if err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			utils.Logline("error getting files of recibo_pagov", clienteId, paymentReq, err)
+		}
+		return nil, errors.New("fileNotFound")
+	}
+
+	var files []paymentFileInternal
+	if err := json.Unmarshal([]byte(filesJson), &files); err != nil {
+		utils.Logline("error parsing files of recibo_pagov", clienteId, paymentReq, err)
+		return nil, errors.New("fileNotFound")
+	}
+	if len(files) == 0 && fileKey != "" {
+		files = append(files, paymentFileInternal{Key: fileKey, Nombre: filepath.Base(fileKey)})
+	}
+
+	return files, nil
+}
+
+// validate the signed url and return the file, the owner of the recibo is checked again
+func PaymentFile(db models.ConnDb, tokenString string) ([]byte, string, int, error) {
+	claims := &models.PaymentFileClaims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (any, error) {
+		return []byte(os.Getenv("FILE_URL_SECRET")), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithAudience("file"), jwt.WithIssuer("micuenta"))
+	if err != nil || !token.Valid {
+		return nil, "", http.StatusUnauthorized, errors.New("fileUrlInvalid")
+	}
+
+	// the key of the url must still be one of the files of the payment
+	paymentReq := models.PaymentReqId{PaymentId: claims.PaymentId, CreatedAt: claims.CreatedAt}
+	files, err := getPaymentFiles(db, claims.ClienteId, paymentReq)
+	if err != nil {
+		return nil, "", http.StatusNotFound, err
+	}
+	fileKey := ""
+	for _, file := range files {
+		if claims.FileKey != "" && (file.Key == claims.FileKey || file.ThumbKey == claims.FileKey) {
+			fileKey = claims.FileKey
+			break
+		}
+	}
+	if fileKey == "" {
+		return nil, "", http.StatusNotFound, errors.New("fileNotFound")
+	}
+
+	// proofs uploaded before the blob store have the path on disk
+	if !strings.HasPrefix(fileKey, "payments/") {
+		content, err := readLegacyPaymentFile(fileKey)
+		if err != nil {
+			utils.Logline("error reading legacy payment file", fileKey, err)
+			return nil, "", http.StatusNotFound, errors.New("fileNotFound")
+		}
+		return content, paymentFileContentType(http.DetectContentType(content)), http.StatusOK, nil
+	}
+
+	store, err := utils.GetBlobStore()
+	if err != nil {
+		utils.Logline("error getting blob store", err)
+		return nil, "", http.StatusInternalServerError, errors.New("errorInternal")
+	}
+	content, contentType, err := store.Get(db.Ctx, fileKey)
+	if err != nil {
+		if errors.Is(err, utils.ErrBlobNotFound) {
+			return nil, "", http.StatusNotFound, errors.New("fileNotFound")
+		}
+		utils.Logline("error getting payment file from blob store", fileKey, err)
+		return nil, "", http.StatusInternalServerError, errors.New("errorInternal")
+	}
+
+	return content, paymentFileContentType(contentType), http.StatusOK, nil
+}
+
+// only the images and pdf are served as they are, any other file (an html uploaded before the
+// validation of the content or a wrong Content-Type on the store) is served as a download
+func paymentFileContentType(contentType string) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return "application/octet-stream"
+	}
+	switch mediaType {
+	case "image/png", "image/jpeg", "application/pdf":
+		return mediaType
+	}
+	return "application/octet-stream"
+}
+
+func isLegacyFileUrl(fileKey string) bool {
+	return strings.HasPrefix(fileKey, "http://") || strings.HasPrefix(fileKey, "https://")
+}
+
+// only the files inside PAYMENT_UPLOAD_FOLDER or PAYMENT_LEGACY_FOLDER can be read, the paths
+// of the files served before on /public (/public/uploads/...) are taken from ./public
+func readLegacyPaymentFile(path string) ([]byte, error) {
+	if strings.HasPrefix(path, "/public/") {
+		path = "." + path
+	}
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, name := range []string{"PAYMENT_UPLOAD_FOLDER", "PAYMENT_LEGACY_FOLDER"} {
+		if os.Getenv(name) == "" {
+			continue
+		}
+		folder, err := filepath.Abs(os.Getenv(name))
+		if err != nil {
+			return nil, err
+		}
+		if strings.HasPrefix(path, folder+string(os.PathSeparator)) {
+			return os.ReadFile(path)
+		}
+	}
+
+	return nil, fmt.Errorf("file %s is outside of PAYMENT_UPLOAD_FOLDER and PAYMENT_LEGACY_FOLDER", path)
+}
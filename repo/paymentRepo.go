@@ -5,10 +5,10 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"mime/multipart"
+	"math"
 	"net/http"
 	"os"
-	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
@@ -17,8 +17,25 @@ import (
 	"ired.com/micuenta/utils"
 )
 
-func SendPayment(db models.ConnDb, userId any, paymentReq models.PaymentReq) (*models.PaymentResponse, int, error) {
+type paymentInternal struct {
+	MontoTotal []float64
+	TasaCambio float64
+	Info       map[string]any
+}
 
+type transferInternal struct {
+	ClienteId         string
+	ClienteDestinoId  string
+	DestinatarioDocId string
+	Descripcion       string
+	Monto             float64
+	TasaCambio        float64
+	PreparadaId       string
+	OtpId             string
+}
+
+// validate the payment form with the same rules for new and amended payments
+func validatePayment(db models.ConnDb, userId any, paymentReq models.PaymentReq) (*paymentInternal, int, error) {
 	if userId != paymentReq.ProfileId {
 		utils.Logline("userId from JWT and recieve on json are not equal", userId, paymentReq)
 		return nil, http.StatusBadRequest, errors.New("errorInternal")
@@ -58,6 +75,17 @@ func SendPayment(db models.ConnDb, userId any, paymentReq models.PaymentReq) (*m
 		}
 	}
 
+	// the rate used is the one of the server for the date of the payment, the
+	// rate sent by the client is only accepted inside the tolerance
+	tasaCambio, err := getTasaCambioFecha(db, paymentReq.Fecha)
+	if err != nil {
+		return nil, http.StatusBadRequest, errors.New("veTasaCambio")
+	}
+	if !tasaCambioInTolerance(paymentReq.TasaCambio, tasaCambio) {
+		utils.Logline(fmt.Sprintf("tasa_cambio (%v) out of tolerance, server tasa_cambio is (%v)", paymentReq.TasaCambio, tasaCambio), userId, paymentReq)
+		return nil, http.StatusBadRequest, errors.New("veTasaCambio")
+	}
+
 	//validar detalles de pago
 	var paymentDetails []models.PaymentResponseDetail
 	var montoTotal = []float64{0, 0}
@@ -67,10 +95,10 @@ func SendPayment(db models.ConnDb, userId any, paymentReq models.PaymentReq) (*m
 		//fill paymentDetail struct
 		if cuentaBanco.Moneda == "dolar" {
 			paymentDetail.Monto.Dolar = detallePago.Monto
-			paymentDetail.Monto.Bolivar = utils.RoundToFourDecimals(detallePago.Monto * paymentReq.TasaCambio)
+			paymentDetail.Monto.Bolivar = utils.RoundToFourDecimals(detallePago.Monto * tasaCambio)
 		} else if cuentaBanco.Moneda == "bolivar" {
 			paymentDetail.Monto.Bolivar = detallePago.Monto
-			paymentDetail.Monto.Dolar = utils.RoundToFourDecimals(detallePago.Monto / paymentReq.TasaCambio)
+			paymentDetail.Monto.Dolar = utils.RoundToFourDecimals(detallePago.Monto / tasaCambio)
 		}
 
 		//validar monto en relacion a moneda, maximo permitido en dolar es 3000
@@ -112,12 +140,25 @@ func SendPayment(db models.ConnDb, userId any, paymentReq models.PaymentReq) (*m
 		"telefono":       paymentReq.Telefono,
 		"payment_detail": paymentDetails,
 		"url_file":       "",
+		"tasa_cambio": map[string]any{
+			"cliente":  paymentReq.TasaCambio,
+			"servidor": tasaCambio,
+		},
+	}
+
+	return &paymentInternal{MontoTotal: montoTotal, TasaCambio: tasaCambio, Info: infoStruct}, http.StatusOK, nil
+}
+
+func SendPayment(db models.ConnDb, userId any, paymentReq models.PaymentReq) (*models.PaymentResponse, int, error) {
+	payment, errType, err := validatePayment(db, userId, paymentReq)
+	if err != nil {
+		return nil, errType, err
 	}
 
 	var paymentId, paymentCreatedat string
-	query = `SELECT id, (created_at)::text FROM venta.insert_recibo_pagov($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`
+	query := `SELECT id, (created_at)::text FROM venta.insert_recibo_pagov($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`
 	err = db.ConnPgsql.QueryRow(db.Ctx, query, 1, paymentReq.ProfileId, paymentReq.CuentaBancoId, paymentReq.BancoClienteId, paymentReq.Fecha, strings.ToLower(paymentReq.Referencia),
-		montoTotal, paymentReq.TasaCambio, "pendiente", 1, 1, infoStruct).Scan(&paymentId, &paymentCreatedat)
+		payment.MontoTotal, payment.TasaCambio, "pendiente", 1, 1, payment.Info).Scan(&paymentId, &paymentCreatedat)
 	if err != nil {
 		fmt.Println(err)
 		if strings.Contains(err.Error(), "ya existe") {
@@ -140,47 +181,162 @@ func SendPayment(db models.ConnDb, userId any, paymentReq models.PaymentReq) (*m
 	return paymentResponse, http.StatusOK, nil
 }
 
-func ImageUpload(c *gin.Context, db models.ConnDb, file *multipart.FileHeader, paymentReq models.PaymentReqId) (int, error) {
-	// Create uploads directory if it doesn't exist
-	uploadDir := os.Getenv("PAYMENT_UPLOAD_FOLDER") + time.Now().Format("/2006/01/02")
-	if _, err := os.Stat(uploadDir); os.IsNotExist(err) {
-		os.Mkdir(uploadDir, 0755)
+// a payment can be changed by the cliente while it is pendiente, the back office did not link it
+// to a recibo or edit it, it was not conciliated with the bank and it is not an autopago
+const paymentEditableCond = `estatus='pendiente' AND COALESCE(info->>'recibo_pago_id', '')='' AND updated_by=created_by AND info->'conciliacion' IS NULL
+	AND COALESCE((info->>'autopago')::boolean, false)=false`
+
+// get the current data of a payment that the cliente can change, used as the
+// previous value on the historial
+func getPaymentEditable(db models.ConnDb, userId any, paymentId string, createdAt string) (map[string]any, int, error) {
+	var fecha, referencia string
+	var monto []float64
+	var tasaCambio float64
+	var metodoPagoId, cuentaClienteId sql.NullInt64
+	var paymentDetail any
+	var editable bool
+	query := `SELECT fecha::text, COALESCE(referencia, ''), monto, tasa_cambio, metodo_pago_id, cuenta_cliente_id, info->'payment_detail',
+			(` + paymentEditableCond + `) as editable
+		FROM venta.recibo_pagov
+		WHERE empresa_id=1 AND cliente_id=$1 AND created_at=$2 AND id=$3`
+	err := db.ConnPgsql.QueryRow(db.Ctx, query, userId, createdAt, paymentId).Scan(&fecha, &referencia, &monto, &tasaCambio,
+		&metodoPagoId, &cuentaClienteId, &paymentDetail, &editable)
+	if err != nil {
+		utils.Logline("error getting recibo_pagov to change", err, userId, paymentId)
+		return nil, http.StatusBadRequest, errors.New("errorGetData")
+	}
+	if !editable {
+		return nil, http.StatusConflict, errors.New("paymentNotEditable")
 	}
 
-	// Generate unique filename
-	ext := filepath.Ext(file.Filename)
+	return map[string]any{
+		"fecha":             fecha,
+		"referencia":        referencia,
+		"monto":             monto,
+		"tasa_cambio":       tasaCambio,
+		"metodo_pago_id":    metodoPagoId.Int64,
+		"cuenta_cliente_id": cuentaClienteId.Int64,
+		"payment_detail":    paymentDetail,
+	}, http.StatusOK, nil
+}
 
-	//validate extensions allowed
-	if ext != ".png" && ext != ".jpg" && ext != ".jpeg" {
-		return http.StatusBadRequest, errors.New("veFileExtError")
+func CancelPayment(db models.ConnDb, userId any, cancelReq models.PaymentCancelReq) (int, error) {
+	anterior, errType, err := getPaymentEditable(db, userId, cancelReq.PaymentId, cancelReq.CreatedAt)
+	if err != nil {
+		return errType, err
 	}
 
-	//generate newFileName
-	userId, _ := c.Get("userId")
-	newFilename := fmt.Sprintf("%s_%s%s", userId, utils.GenerateUUID(), ext)
-	filePath := filepath.Join(uploadDir, newFilename)
-
-	// Save the file
-	if err := c.SaveUploadedFile(file, filePath); err != nil {
-		return http.StatusBadRequest, errors.New("veFileError")
+	historial := map[string]any{
+		"accion":   "cancel",
+		"fecha":    time.Now().Format(time.RFC3339),
+		"motivo":   cancelReq.Motivo,
+		"anterior": map[string]any{"estatus": "pendiente", "referencia": anterior["referencia"]},
 	}
 
-	var reciboPagoId string
-	query := `UPDATE venta.recibo_pagov
-		SET info = jsonb_set(info, '{url_file}', to_jsonb($1::text))
-		WHERE empresa_id = 1 AND cliente_id=$2 AND created_at=$3 AND id = $4 RETURNING id`
-	if err := db.ConnPgsql.QueryRow(db.Ctx, query, filePath, userId, paymentReq.CreatedAt, paymentReq.PaymentId).Scan(&reciboPagoId); err != nil {
-		os.Remove(filePath)
-		utils.Logline("error updating recibo_pagov", err)
-		return http.StatusBadRequest, errors.New("veFileError")
+	// the condition is checked again in case the back office took it meanwhile
+	query := `UPDATE venta.recibo_pagov SET estatus='anulado', updated_at=NOW(),
+			info=jsonb_set(info, '{historial}', COALESCE(info->'historial', '[]'::jsonb) || jsonb_build_array($4::jsonb))
+		WHERE empresa_id=1 AND cliente_id=$1 AND created_at=$2 AND id=$3 AND ` + paymentEditableCond
+	result, err := db.ConnPgsql.Exec(db.Ctx, query, userId, cancelReq.CreatedAt, cancelReq.PaymentId, historial)
+	if err != nil {
+		utils.Logline("error canceling recibo_pagov", err, userId, cancelReq)
+		return http.StatusBadRequest, errors.New("errorUpdateRecord")
+	}
+	if result.RowsAffected() != 1 {
+		return http.StatusConflict, errors.New("paymentNotEditable")
 	}
 
+	utils.Logline("recibo_pagov canceled by the cliente", userId, cancelReq.PaymentId)
 	return http.StatusOK, nil
 }
 
+func AmendPayment(db models.ConnDb, userId any, amendReq models.PaymentAmendReq) (*models.PaymentResponse, int, error) {
+	anterior, errType, err := getPaymentEditable(db, userId, amendReq.PaymentId, amendReq.CreatedAt)
+	if err != nil {
+		return nil, errType, err
+	}
+
+	payment, errType, err := validatePayment(db, userId, amendReq.PaymentReq)
+	if err != nil {
+		return nil, errType, err
+	}
+
+	// the same referencia can not be reported twice for the cuenta
+	var referenciaExists bool
+	query := `SELECT EXISTS (SELECT 1 FROM venta.recibo_pagov
+		WHERE empresa_id=1 AND metodo_pago_id=$1 AND LOWER(referencia)=$2 AND estatus<>'anulado' AND NOT (id=$3 AND created_at=$4))`
+	err = db.ConnPgsql.QueryRow(db.Ctx, query, amendReq.CuentaBancoId, strings.ToLower(amendReq.Referencia), amendReq.PaymentId, amendReq.CreatedAt).Scan(&referenciaExists)
+	if err != nil {
+		utils.Logline("error checking referencia of recibo_pagov", err, amendReq)
+		return nil, http.StatusBadRequest, errors.New("errorUpdateRecord")
+	}
+	if referenciaExists {
+		return nil, http.StatusBadRequest, errors.New("veReferencia")
+	}
+
+	// the uploaded file is kept
+	delete(payment.Info, "url_file")
+	historial := map[string]any{
+		"accion":   "amend",
+		"fecha":    time.Now().Format(time.RFC3339),
+		"anterior": anterior,
+	}
+
+	query = `UPDATE venta.recibo_pagov SET fecha=$4, referencia=$5, metodo_pago_id=$6, cuenta_cliente_id=$7, monto=$8, tasa_cambio=$9, updated_at=NOW(),
+			info=jsonb_set(info || $10::jsonb, '{historial}', COALESCE(info->'historial', '[]'::jsonb) || jsonb_build_array($11::jsonb))
+		WHERE empresa_id=1 AND cliente_id=$1 AND created_at=$2 AND id=$3 AND ` + paymentEditableCond
+	result, err := db.ConnPgsql.Exec(db.Ctx, query, userId, amendReq.CreatedAt, amendReq.PaymentId, amendReq.Fecha, strings.ToLower(amendReq.Referencia),
+		amendReq.CuentaBancoId, amendReq.BancoClienteId, payment.MontoTotal, payment.TasaCambio, payment.Info, historial)
+	if err != nil {
+		utils.Logline("error amending recibo_pagov", err, userId, amendReq)
+		return nil, http.StatusBadRequest, errors.New("errorUpdateRecord")
+	}
+	if result.RowsAffected() != 1 {
+		return nil, http.StatusConflict, errors.New("paymentNotEditable")
+	}
+
+	paymentReqId := models.PaymentReqId{
+		PaymentId: amendReq.PaymentId,
+		CreatedAt: amendReq.CreatedAt,
+	}
+
+	paymentResponse, errType, err := GetPayment(db, amendReq.ProfileId, paymentReqId)
+	if err != nil {
+		return nil, errType, err
+	}
+
+	return paymentResponse, http.StatusOK, nil
+}
+
+// last rate of the bolivar registered until the end of the date
+func getTasaCambioFecha(db models.ConnDb, fecha string) (float64, error) {
+	var tasaCambio float64
+	query := `SELECT monto FROM publico.tasa_cambio
+		WHERE empresa_id=1 AND moneda='bolivar' AND created_at < ($1::date + 1)
+		ORDER BY created_at DESC LIMIT 1`
+	if err := db.ConnPgsql.QueryRow(db.Ctx, query, fecha).Scan(&tasaCambio); err != nil {
+		utils.Logline("tasa cambio no encontrada para la fecha", fecha, err)
+		return 0, err
+	}
+	if tasaCambio <= 0 {
+		return 0, fmt.Errorf("tasa cambio (%v) invalida para la fecha %s", tasaCambio, fecha)
+	}
+
+	return tasaCambio, nil
+}
+
+// PAYMENT_TASA_TOLERANCE is the max difference allowed in percent
+func tasaCambioInTolerance(tasaCliente float64, tasaServidor float64) bool {
+	tolerance, err := strconv.ParseFloat(os.Getenv("PAYMENT_TASA_TOLERANCE"), 64)
+	if err != nil {
+		tolerance = 1
+	}
+	return math.Abs(tasaCliente-tasaServidor)/tasaServidor*100 <= tolerance
+}
+
 func GetPayment(db models.ConnDb, clienteId string, paymentReq models.PaymentReqId) (*models.PaymentResponse, int, error) {
 	query := `SELECT rp.id, rp.cliente_id, rp.fecha::text, rp.tasa_cambio, ROUND(rp.monto[1],2) as tot_dolar, ROUND(rp.monto[2],2) as tot_bolivar, COALESCE(rp.referencia, '') as referencia, rp.estatus,
-			COALESCE(rp.info->>'email', '') as email, COALESCE(rp.info->>'telefono', '') as telefono, rp.info->>'url_file' as url_file, rp.info->>'payment_detail' as pdetail,
+			COALESCE(rp.info->>'email', '') as email, COALESCE(rp.info->>'telefono', '') as telefono, rp.info->>'payment_detail' as pdetail,
 			rp.created_at, rp.updated_at,
 			mpago.id as mpago_id, mpago.banco as mpago_banco, mpago.metodo_pago as mpago_metodo, mpago.moneda as mpago_moneda, mpago.info->>'web_nombre' as mpago_nombre, mpago.info->>'web_detail' as mpago_detalle,
 			COALESCE(mcliente.id, 0) as mcliente_id, COALESCE(mcliente.banco, '') as mcliente_banco, 
@@ -195,7 +351,7 @@ func GetPayment(db models.ConnDb, clienteId string, paymentReq models.PaymentReq
 	var metodoPagoInfo sql.NullString
 
 	err := db.ConnPgsql.QueryRow(db.Ctx, query, clienteId, paymentReq.CreatedAt, paymentReq.PaymentId).Scan(&payment.PaymentId, &payment.ProfileId, &payment.Fecha, &payment.TasaCambio, &payment.MontoTotal.Dolar, &payment.MontoTotal.Bolivar,
-		&payment.Referencia, &payment.Estatus, &payment.Email, &payment.Telefono, &payment.UrlFile, &paymentDetailJson,
+		&payment.Referencia, &payment.Estatus, &payment.Email, &payment.Telefono, &paymentDetailJson,
 		&payment.CreatedAt, &payment.UpdatedAt,
 		&payment.MetodoPago.Id, &payment.MetodoPago.Banco, &payment.MetodoPago.MetodoPago, &payment.MetodoPago.Moneda, &payment.MetodoPago.Nombre, &metodoPagoInfo,
 		&payment.BancoCliente.Id, &payment.BancoCliente.Banco, &payment.BancoCliente.Moneda, &payment.BancoCliente.Nombre)
@@ -205,6 +361,17 @@ func GetPayment(db models.ConnDb, clienteId string, paymentReq models.PaymentReq
 	}
 
 	payment.Ncontrol = utils.GenerateNcontrolByUuid(payment.PaymentId)
+	files, err := getPaymentFiles(db, clienteId, paymentReq)
+	if err != nil {
+		return nil, http.StatusBadRequest, errors.New("errorGetData")
+	}
+	payment.Files, err = paymentFileUrls(clienteId, paymentReq, files)
+	if err != nil {
+		return nil, http.StatusInternalServerError, errors.New("errorInternal")
+	}
+	if len(payment.Files) > 0 {
+		payment.UrlFile = payment.Files[0].Url
+	}
 	if metodoPagoInfo.Valid {
 		payment.MetodoPago.Detalle = getFormaPagoDetail(metodoPagoInfo.String)
 	}
@@ -316,6 +483,12 @@ func BalanceAvailable(c *gin.Context, db models.ConnDb) (*models.BalanceAvailabl
 		return nil, http.StatusBadRequest, errors.New("errorGetData")
 	}
 
+	allowance, errType, err := TransferAllowance(db, fmt.Sprintf("%s", userId), balanceAvailable.Monto.Dolar)
+	if err != nil {
+		return nil, errType, err
+	}
+	balanceAvailable.Limites = *allowance
+
 	return &balanceAvailable, http.StatusOK, nil
 }
 
@@ -325,6 +498,12 @@ func SendTransfer(db models.ConnDb, userId any, transferReq models.TransferReq)
 		return nil, http.StatusBadRequest, errors.New("errorInternal")
 	}
 
+	// the transfers must be confirmed with a code from /payment/transfer/prepare,
+	// only TRANSFER_REQUIRE_CONFIRM=false allows the transfers without code
+	if os.Getenv("TRANSFER_REQUIRE_CONFIRM") != "false" {
+		return nil, http.StatusForbidden, errors.New("transferConfirmRequired")
+	}
+
 	//validar si destinatarioDocId existe
 	docIdReq := strings.ToLower(transferReq.DestinatarioDocId)
 	var clienteDestinoId sql.NullString
@@ -333,11 +512,8 @@ func SendTransfer(db models.ConnDb, userId any, transferReq models.TransferReq)
 		utils.Logline(fmt.Sprintf("docId (%s) no encontrado al momento de transferir", docIdReq), transferReq)
 		return nil, http.StatusBadRequest, errors.New("errorInternal")
 	}
-
-	// handle json info column
-	infoStruct := map[string]any{
-		"destinatario_docid": transferReq.DestinatarioDocId,
-		"descripcion":        transferReq.Descripcion,
+	if clienteDestinoId.String == transferReq.ProfileId {
+		return nil, http.StatusBadRequest, errors.New("transferRecipientSelf")
 	}
 
 	var tasaCambio float64
@@ -347,13 +523,55 @@ func SendTransfer(db models.ConnDb, userId any, transferReq models.TransferReq)
 		return nil, http.StatusBadRequest, errors.New("errorInternal")
 	}
 
+	return saveTransfer(db, transferInternal{
+		ClienteId:         transferReq.ProfileId,
+		ClienteDestinoId:  clienteDestinoId.String,
+		DestinatarioDocId: transferReq.DestinatarioDocId,
+		Descripcion:       transferReq.Descripcion,
+		Monto:             transferReq.Monto,
+		TasaCambio:        tasaCambio,
+	})
+}
+
+// save the transfer checking the limits of the cliente, when it comes from a prepared
+// transfer, the prepared one is marked as confirmed on the same transaction
+func saveTransfer(db models.ConnDb, transfer transferInternal) (*models.TransferResponse, int, error) {
+	// handle json info column
+	infoStruct := map[string]any{
+		"destinatario_docid": transfer.DestinatarioDocId,
+		"descripcion":        transfer.Descripcion,
+	}
+
 	//arrayMonto
-	montoArray := []float64{transferReq.Monto, transferReq.Monto * tasaCambio}
+	montoArray := []float64{transfer.Monto, transfer.Monto * transfer.TasaCambio}
+
+	// the limits are checked and the transfer saved while holding a lock of the cliente,
+	// so two transfers at the same time can not exceed the limits together
+	tx, err := db.ConnPgsql.Begin(db.Ctx)
+	if err != nil {
+		utils.Logline("error starting transaction of transferenciav", err)
+		return nil, http.StatusBadRequest, errors.New("errorInternal")
+	}
+	defer tx.Rollback(db.Ctx)
+
+	if _, err := tx.Exec(db.Ctx, `SELECT pg_advisory_xact_lock(hashtext('transferenciav:' || $1))`, transfer.ClienteId); err != nil {
+		utils.Logline("error locking transferenciav of cliente", err, transfer.ClienteId)
+		return nil, http.StatusBadRequest, errors.New("errorInternal")
+	}
+
+	limite, err := getTransferLimite(db.Ctx, tx, transfer.ClienteId, transfer.ClienteDestinoId)
+	if err != nil {
+		return nil, http.StatusBadRequest, errors.New("errorInternal")
+	}
+	if err := checkTransferLimite(limite, transfer.Monto); err != nil {
+		utils.Logline("transfer exceeds the limits of the cliente", err, transfer.ClienteId, transfer.Monto)
+		return nil, http.StatusUnprocessableEntity, err
+	}
 
 	var transferId, transferCreatedat string
-	query = `SELECT id, (created_at)::text FROM venta.insert_transferenciav($1, $2, $3, $4, $5)`
-	if err := db.ConnPgsql.QueryRow(db.Ctx, query, 1, transferReq.ProfileId, clienteDestinoId, montoArray, infoStruct).Scan(&transferId, &transferCreatedat); err != nil {
-		utils.Logline("error saving transferenciav", err, transferReq)
+	query := `SELECT id, (created_at)::text FROM venta.insert_transferenciav($1, $2, $3, $4, $5)`
+	if err := tx.QueryRow(db.Ctx, query, 1, transfer.ClienteId, transfer.ClienteDestinoId, montoArray, infoStruct).Scan(&transferId, &transferCreatedat); err != nil {
+		utils.Logline("error saving transferenciav", err, transfer)
 
 		if strings.Contains(err.Error(), "cliente destino no posee una suscripcion_id") {
 			return nil, http.StatusBadRequest, errors.New("errorInternal")
@@ -366,12 +584,41 @@ func SendTransfer(db models.ConnDb, userId any, transferReq models.TransferReq)
 		return nil, http.StatusBadRequest, errors.New("errorInsertRecord")
 	}
 
+	// the code is consumed only when the transfer is saved, a limit or saldo error does not burn it
+	if transfer.OtpId != "" {
+		consumed, err := consumeOtp(db, tx, transfer.OtpId)
+		if err != nil {
+			return nil, http.StatusBadRequest, errors.New("errorInsertRecord")
+		}
+		if !consumed {
+			return nil, http.StatusUnauthorized, errors.New("otpInvalid")
+		}
+	}
+
+	if transfer.PreparadaId != "" {
+		query = `UPDATE publico.transfer_preparada SET confirmed_at=NOW(), transferencia_id=$2
+			WHERE id=$1 AND confirmed_at IS NULL`
+		result, err := tx.Exec(db.Ctx, query, transfer.PreparadaId, transferId)
+		if err != nil {
+			utils.Logline("error updating transfer_preparada", err, transfer.PreparadaId)
+			return nil, http.StatusBadRequest, errors.New("errorInsertRecord")
+		}
+		if result.RowsAffected() != 1 {
+			return nil, http.StatusGone, errors.New("transferPrepareExpired")
+		}
+	}
+
+	if err := tx.Commit(db.Ctx); err != nil {
+		utils.Logline("error commiting transferenciav", err, transfer)
+		return nil, http.StatusBadRequest, errors.New("errorInsertRecord")
+	}
+
 	TransferReqId := models.TransferReqId{
 		TransferId: transferId,
 		CreatedAt:  transferCreatedat,
 	}
 
-	transferResponse, errType, err := GetTransfer(db, transferReq.ProfileId, TransferReqId)
+	transferResponse, errType, err := GetTransfer(db, transfer.ClienteId, TransferReqId)
 	if err != nil {
 		return nil, errType, err
 	}
@@ -383,7 +630,7 @@ func GetTransfer(db models.ConnDb, clienteId string, transferReq models.Transfer
 	query := `SELECT id, info->>'destinatario_docid' as destinatario_docid, ROUND(monto[1],2) as tot_dolar, ROUND(monto[2],2) as tot_bolivar, 
 			info->>'descripcion' as descr, created_at
 		FROM venta.transferenciav
-		WHERE cliente_origen_id=$1 AND created_at=$2 AND id=$3`
+		WHERE (cliente_origen_id=$1 OR cliente_destino_id=$1) AND created_at=$2 AND id=$3`
 
 	var transfer models.TransferResponse
 	if err := db.ConnPgsql.QueryRow(db.Ctx, query, clienteId, transferReq.CreatedAt, transferReq.TransferId).Scan(&transfer.TransferId, &transfer.DestinatarioDocId,
@@ -397,14 +644,23 @@ func GetTransfer(db models.ConnDb, clienteId string, transferReq models.Transfer
 	return &transfer, http.StatusOK, nil
 }
 
-func TransferList(db models.ConnDb, userId any, pageQuery models.PaginatorQuery) (*[]models.TransferList, *models.PaginatorData, error) {
+// transfers of the cliente, direccion is enviadas, recibidas or todas
+func TransferList(db models.ConnDb, userId any, pageQuery models.PaginatorQuery, direccion string) (*[]models.TransferList, *models.PaginatorData, error) {
 	currentPage := pageQuery.Page
 	limit := pageQuery.Limit
 	offset := (currentPage - 1) * limit
 
+	where := "t.cliente_origen_id=$1"
+	switch direccion {
+	case "recibidas":
+		where = "t.cliente_destino_id=$1"
+	case "todas":
+		where = "(t.cliente_origen_id=$1 OR t.cliente_destino_id=$1)"
+	}
+
 	//get meta of paginator
 	var totalCount int
-	if err := db.ConnPgsql.QueryRow(db.Ctx, "SELECT COUNT(*) FROM venta.transferenciav WHERE cliente_origen_id=$1", userId).Scan(&totalCount); err != nil {
+	if err := db.ConnPgsql.QueryRow(db.Ctx, "SELECT COUNT(*) FROM venta.transferenciav as t WHERE "+where, userId).Scan(&totalCount); err != nil {
 		utils.Logline("error on query count", err)
 		return nil, nil, errors.New("errorGetData")
 	}
@@ -415,11 +671,13 @@ func TransferList(db models.ConnDb, userId any, pageQuery models.PaginatorQuery)
 		return nil, nil, errors.New("errorPage")
 	}
 
-	query := `SELECT id, info->>'destinatario_docid' as destinatario_docid, ROUND(monto[1],2) as tot_dolar, ROUND(monto[2],2) as tot_bolivar, 
-			info->>'descripcion' as descr, created_at
-		FROM venta.transferenciav
-		WHERE cliente_origen_id=$1
-		ORDER BY created_at DESC 
+	query := `SELECT t.id, COALESCE(t.info->>'destinatario_docid', '') as destinatario_docid, COALESCE(c.docid, '') as remitente_docid,
+			ROUND(t.monto[1],2) as tot_dolar, ROUND(t.monto[2],2) as tot_bolivar, t.info->>'descripcion' as descr, t.created_at,
+			CASE WHEN t.cliente_origen_id=$1 THEN 'enviada' ELSE 'recibida' END as direccion
+		FROM venta.transferenciav as t
+		LEFT JOIN publico.cliente as c ON c.id=t.cliente_origen_id
+		WHERE ` + where + `
+		ORDER BY t.created_at DESC 
 		LIMIT $2 
 		OFFSET $3`
 	rows, err := db.ConnPgsql.Query(db.Ctx, query, userId, limit, offset)
@@ -432,7 +690,8 @@ func TransferList(db models.ConnDb, userId any, pageQuery models.PaginatorQuery)
 	var transferList []models.TransferList
 	for rows.Next() {
 		var transfer models.TransferList
-		err = rows.Scan(&transfer.TransferId, &transfer.DestinatarioDocId, &transfer.Monto.Dolar, &transfer.Monto.Bolivar, &transfer.Descripcion, &transfer.CreatedAt)
+		err = rows.Scan(&transfer.TransferId, &transfer.DestinatarioDocId, &transfer.RemitenteDocId, &transfer.Monto.Dolar, &transfer.Monto.Bolivar,
+			&transfer.Descripcion, &transfer.CreatedAt, &transfer.Direccion)
 		if err != nil {
 			utils.Logline("error scanning venta.transferenciav", err)
 			return nil, nil, errors.New("errorGetData")
@@ -446,6 +705,66 @@ func TransferList(db models.ConnDb, userId any, pageQuery models.PaginatorQuery)
 	return &transferList, &paginatorData, err
 }
 
+// recipient of a transfer with the name masked and its suscripciones, it is rate limited
+// to not allow to search the docids of the clientes
+func TransferRecipient(c *gin.Context, db models.ConnDb, clienteId string, recipientReq models.TransferRecipientReq) (*models.TransferRecipient, int, error) {
+	errType, err := checkRateLimit(c, db, "transfer_recipient", rateLimit{Llave: "cliente:" + clienteId, Max: 20, Ventana: time.Hour})
+	if err != nil {
+		return nil, errType, err
+	}
+
+	recipient, _, errType, err := getTransferRecipient(db, clienteId, recipientReq.DestinatarioDocId)
+	return recipient, errType, err
+}
+
+// recipient of the transfer and its cliente id, it must be other cliente active with suscripciones
+func getTransferRecipient(db models.ConnDb, clienteId string, docId string) (*models.TransferRecipient, string, int, error) {
+	user, err := getUser(db, "docid", strings.ToLower(docId))
+	if err != nil {
+		if err.Error() == "recordDontExist" {
+			return nil, "", http.StatusNotFound, errors.New("transferRecipientNotFound")
+		}
+		return nil, "", http.StatusBadRequest, err
+	}
+	if user.Profile.Id == clienteId {
+		return nil, "", http.StatusBadRequest, errors.New("transferRecipientSelf")
+	}
+
+	query := `SELECT s.id, TRIM(TO_CHAR((s.info->>'oldid')::integer, '000000')) as ncontrol, SPLIT_PART(st.nombre,'/',3) as tipo_servicio
+		FROM administracion.suscripcion as s
+		LEFT JOIN administracion.servicio as sv ON sv.id=s.servicio_id
+		LEFT JOIN administracion.servicio_tipo as st ON st.id=sv.servicio_tipo_id
+		WHERE s.cliente_id=$1
+		ORDER BY s.id ASC`
+	rows, err := db.ConnPgsql.Query(db.Ctx, query, user.Profile.Id)
+	if err != nil {
+		utils.Logline("error on select suscripciones of recipient", err)
+		return nil, "", http.StatusBadRequest, errors.New("errorGetData")
+	}
+	defer rows.Close()
+
+	recipient := models.TransferRecipient{
+		DocId:         user.Profile.Username,
+		Nombre:        utils.MaskName(user.Profile.Nombre),
+		Suscripciones: []models.TransferRecipientSuscripcion{},
+	}
+	for rows.Next() {
+		var suscripcion models.TransferRecipientSuscripcion
+		if err := rows.Scan(&suscripcion.Id, &suscripcion.Ncontrol, &suscripcion.TipoServicio); err != nil {
+			utils.Logline("error scanning suscripciones of recipient", err)
+			return nil, "", http.StatusBadRequest, errors.New("errorGetData")
+		}
+		recipient.Suscripciones = append(recipient.Suscripciones, suscripcion)
+	}
+
+	// the transfer is credited to a suscripcion of the recipient
+	if len(recipient.Suscripciones) == 0 {
+		return nil, "", http.StatusBadRequest, errors.New("transferRecipientNoSuscripcion")
+	}
+
+	return &recipient, user.Profile.Id, http.StatusOK, nil
+}
+
 func GetPaymentFactura(db models.ConnDb, clienteId string, facturaReq models.FacturaReqId) (*[]models.PaymentList, error) {
 	query := `SELECT rp.id as payment_id, rp.estatus, rp.fecha::text as fecha, 
 			COALESCE(rp.referencia, '') as referencia, rp.monto[1] as tot_dolar, rp.monto[2] as tot_bolivar, rp.created_at,
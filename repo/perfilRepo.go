@@ -0,0 +1,368 @@
+package repo
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	ginI18n "github.com/gin-contrib/i18n"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+	"ired.com/micuenta/models"
+	"ired.com/micuenta/utils"
+)
+
+// max correos or telefonos of a cliente, the same of the request
+const perfilMaxContacts = 3
+
+// save a change of the profile on the audit trail
+func logPerfilAudit(c *gin.Context, db models.ConnDb, clienteId string, campo string, anterior any, nuevo any, estatus string) {
+	query := `INSERT INTO publico.cliente_perfil_audit (empresa_id, cliente_id, campo, valor_anterior, valor_nuevo, estatus, ip, user_agent)
+		VALUES (1, $1, $2, $3, $4, $5, $6, $7)`
+	_, err := db.ConnPgsql.Exec(db.Ctx, query, clienteId, campo, anterior, nuevo, estatus, c.ClientIP(), c.Request.UserAgent())
+	if err != nil {
+		utils.Logline("error saving cliente_perfil_audit", clienteId, campo, err)
+	}
+}
+
+func GetPerfil(c *gin.Context, db models.ConnDb) (*models.UserProfile, int, error) {
+	userId, _ := c.Get("userId")
+
+	user, err := getUser(db, "id", userId.(string))
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+
+	return &user.Profile, http.StatusOK, nil
+}
+
+// the direccion is saved directly, the new correo or telefono is saved only
+// after the cliente validates the code sent to it on /perfil/verificar
+func UpdatePerfil(c *gin.Context, db models.ConnDb, perfilReq models.PerfilUpdateReq) (*models.PerfilResponse, int, error) {
+	userId, _ := c.Get("userId")
+	clienteId := userId.(string)
+
+	user, err := getUser(db, "id", clienteId)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+
+	correo := normalizeContacts(perfilReq.Correo)
+	telefono := normalizeContacts(perfilReq.Telefono)
+	// the account can not be left without correo or telefono
+	if (correo != nil && len(correo) == 0) || (telefono != nil && len(telefono) == 0) {
+		return nil, http.StatusBadRequest, errors.New("perfilContactRequired")
+	}
+	correoChanged := correo != nil && !slices.Equal(correo, user.Profile.Correo)
+	telefonoChanged := telefono != nil && !slices.Equal(telefono, user.Profile.Telefono)
+
+	// the contacts receive the codes to recover the account, an access token is not enough to change them
+	if correoChanged || telefonoChanged {
+		if errType, err := checkPerfilIdentity(c, db, user, perfilReq); err != nil {
+			return nil, errType, err
+		}
+	}
+
+	// every new contact must receive its own code, only one per field is allowed
+	var correoNuevo, telefonoNuevo string
+	if correoChanged {
+		added := addedContacts(correo, user.Profile.Correo)
+		if len(added) > 1 {
+			return nil, http.StatusBadRequest, errors.New("perfilOneNewContact")
+		}
+		if len(added) == 1 {
+			correoNuevo = added[0]
+		}
+	}
+	if telefonoChanged {
+		added := addedContacts(telefono, user.Profile.Telefono)
+		if len(added) > 1 {
+			return nil, http.StatusBadRequest, errors.New("perfilOneNewContact")
+		}
+		if len(added) == 1 {
+			if utils.FirstCelular(added) == "" {
+				return nil, http.StatusBadRequest, errors.New("perfilPhoneNotMobile")
+			}
+			telefonoNuevo = added[0]
+		}
+	}
+
+	if correoNuevo != "" || telefonoNuevo != "" {
+		errType, err := checkRateLimit(c, db, "perfil_contacto", rateLimit{Llave: "cliente:" + clienteId, Max: 5, Ventana: time.Hour})
+		if err != nil {
+			return nil, errType, err
+		}
+	}
+
+	direccion := strings.TrimSpace(perfilReq.Direccion)
+	if direccion != "" && direccion != user.Profile.Direccion {
+		query := `UPDATE publico.cliente SET direccion=$1 WHERE empresa_id=1 AND id=$2`
+		if _, err := db.ConnPgsql.Exec(db.Ctx, query, direccion, clienteId); err != nil {
+			utils.Logline("error updating direccion of cliente", clienteId, err)
+			return nil, http.StatusInternalServerError, errors.New("errorInternal")
+		}
+		logPerfilAudit(c, db, clienteId, "direccion", user.Profile.Direccion, direccion, "aplicado")
+		user.Profile.Direccion = direccion
+	}
+
+	var response models.PerfilResponse
+
+	// removing or sorting the contacts does not need a code, the removed ones are notified. When
+	// there is a new contact only it and the ones it replaces are kept until the code is validated
+	if correoChanged {
+		removed := addedContacts(user.Profile.Correo, correo)
+		if correoNuevo == "" {
+			if err := updateContacts(db, "correo", correo, clienteId); err != nil {
+				return nil, http.StatusInternalServerError, err
+			}
+			logPerfilAudit(c, db, clienteId, "correo", user.Profile.Correo, correo, "aplicado")
+			notifyContactsRemoved(c, "correo", removed, correo)
+			user.Profile.Correo = correo
+		} else {
+			code, err := createOtp(db, clienteId, "perfil_correo", "email", correoNuevo, map[string]any{"contacto": correoNuevo, "reemplaza": removed})
+			if err != nil {
+				return nil, http.StatusInternalServerError, errors.New("errorInternal")
+			}
+			err = sendOtpEmail([]string{correoNuevo}, ginI18n.MustGetMessage(c, "titleVerifyEmail"), "Verifica tu correo",
+				"Para agregar este correo a tu cuenta de MiCuenta utiliza el siguiente codigo:", code)
+			if err != nil {
+				utils.Logline("error sending the email", err)
+				return nil, http.StatusInternalServerError, errors.New("errorEmail")
+			}
+			logPerfilAudit(c, db, clienteId, "correo", user.Profile.Correo, correo, "pendiente")
+			response.Pendientes = append(response.Pendientes, models.PerfilPendiente{Campo: "correo", Destino: utils.MaskEmail(correoNuevo)})
+		}
+	}
+
+	if telefonoChanged {
+		removed := addedContacts(user.Profile.Telefono, telefono)
+		if telefonoNuevo == "" {
+			if err := updateContacts(db, "telefono", telefono, clienteId); err != nil {
+				return nil, http.StatusInternalServerError, err
+			}
+			logPerfilAudit(c, db, clienteId, "telefono", user.Profile.Telefono, telefono, "aplicado")
+			notifyContactsRemoved(c, "telefono", removed, telefono)
+			user.Profile.Telefono = telefono
+		} else {
+			code, err := createOtp(db, clienteId, "perfil_telefono", "sms", telefonoNuevo, map[string]any{"contacto": telefonoNuevo, "reemplaza": removed})
+			if err != nil {
+				return nil, http.StatusInternalServerError, errors.New("errorInternal")
+			}
+			if err := utils.SendSms(telefonoNuevo, "Besser Solutions: tu codigo para verificar este telefono es "+code+", no lo compartas."); err != nil {
+				utils.Logline("error sending the sms", clienteId, err)
+				return nil, http.StatusInternalServerError, errors.New("errorSms")
+			}
+			logPerfilAudit(c, db, clienteId, "telefono", user.Profile.Telefono, telefono, "pendiente")
+			response.Pendientes = append(response.Pendientes, models.PerfilPendiente{Campo: "telefono", Destino: utils.MaskPhone(telefonoNuevo)})
+		}
+	}
+
+	response.Profile = user.Profile
+	return &response, http.StatusOK, nil
+}
+
+// validate the code sent to the new correo or telefono and save the change
+func VerifyPerfil(c *gin.Context, db models.ConnDb, verifyReq models.PerfilVerifyReq) (*models.UserProfile, int, error) {
+	userId, _ := c.Get("userId")
+	clienteId := userId.(string)
+
+	user, err := getUser(db, "id", clienteId)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+
+	otp, errType, err := verifyOtp(db, clienteId, "perfil_"+verifyReq.Campo, verifyReq.Code)
+	if err != nil {
+		return nil, errType, err
+	}
+
+	// the change is applied over the current contacts, they could change after the code was sent
+	contacto, _ := otp.Info["contacto"].(string)
+	if contacto == "" {
+		utils.Logline("cliente_otp without the contact to save", otp.Id)
+		return nil, http.StatusInternalServerError, errors.New("errorInternal")
+	}
+	valores, _ := otp.Info["reemplaza"].([]any)
+	reemplaza := make([]string, 0, len(valores))
+	for _, valor := range valores {
+		if s, ok := valor.(string); ok {
+			reemplaza = append(reemplaza, s)
+		}
+	}
+
+	current := user.Profile.Correo
+	if verifyReq.Campo == "telefono" {
+		current = user.Profile.Telefono
+	}
+	contacts := replaceContacts(current, reemplaza, contacto)
+	if len(contacts) > perfilMaxContacts {
+		return nil, http.StatusBadRequest, errors.New("perfilMaxContacts")
+	}
+
+	if err := updateContacts(db, verifyReq.Campo, contacts, clienteId); err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	logPerfilAudit(c, db, clienteId, verifyReq.Campo, current, contacts, "verificado")
+	notifyContactsRemoved(c, verifyReq.Campo, addedContacts(current, contacts), contacts)
+
+	if verifyReq.Campo == "correo" {
+		user.Profile.Correo = contacts
+	} else {
+		user.Profile.Telefono = contacts
+	}
+
+	return &user.Profile, http.StatusOK, nil
+}
+
+// the current password and the second factor (when enabled) of the cliente
+func checkPerfilIdentity(c *gin.Context, db models.ConnDb, user *models.UserResponse, perfilReq models.PerfilUpdateReq) (int, error) {
+	if perfilReq.Password == "" || !user.Credentials.Passwd.Valid ||
+		bcrypt.CompareHashAndPassword([]byte(user.Credentials.Passwd.String), []byte(perfilReq.Password)) != nil {
+		logAuthEvent(c, db, user.Profile.Id, "perfil_password_failed", nil)
+		return http.StatusUnauthorized, errors.New("perfilPasswordInvalid")
+	}
+
+	twoFactor, err := getTwoFactor(db, user.Profile.Id)
+	if err != nil {
+		return http.StatusInternalServerError, errors.New("errorInternal")
+	}
+	if twoFactor == nil || !twoFactor.Enabled {
+		return http.StatusOK, nil
+	}
+	if perfilReq.Code == "" {
+		return http.StatusUnauthorized, errors.New("twoFactorRequired")
+	}
+	return checkSecondFactor(c, db, twoFactor, perfilReq.Code)
+}
+
+func updateContacts(db models.ConnDb, campo string, contacts []string, clienteId string) error {
+	query := `UPDATE publico.cliente SET ` + campo + `=$1 WHERE empresa_id=1 AND id=$2`
+	if _, err := db.ConnPgsql.Exec(db.Ctx, query, contacts, clienteId); err != nil {
+		utils.Logline("error updating contacts of cliente", campo, clienteId, err)
+		return errors.New("errorInternal")
+	}
+	return nil
+}
+
+// trim the values and drop the empty or repeated ones, nil if the field was not sent
+func normalizeContacts(contacts []string) []string {
+	if contacts == nil {
+		return nil
+	}
+
+	result := []string{}
+	for _, contact := range contacts {
+		contact = strings.TrimSpace(contact)
+		if contact != "" && !slices.Contains(result, contact) {
+			result = append(result, contact)
+		}
+	}
+	return result
+}
+
+// contacts that are not in current
+func addedContacts(contacts []string, current []string) []string {
+	added := []string{}
+	for _, contact := range contacts {
+		if !slices.Contains(current, contact) {
+			added = append(added, contact)
+		}
+	}
+	return added
+}
+
+// the new contact takes the place of the first replaced one, or goes at the end
+func replaceContacts(current []string, reemplaza []string, contacto string) []string {
+	result := []string{}
+	placed := slices.Contains(current, contacto) && !slices.Contains(reemplaza, contacto)
+	for _, contact := range current {
+		if !slices.Contains(reemplaza, contact) {
+			result = append(result, contact)
+			continue
+		}
+		if !placed {
+			result = append(result, contacto)
+			placed = true
+		}
+	}
+	if !placed {
+		result = append(result, contacto)
+	}
+	return result
+}
+
+// tell the removed correos or telefonos that they are not on the account anymore, so the
+// cliente knows if it was done by other person. The errors are only logged
+func notifyContactsRemoved(c *gin.Context, campo string, removed []string, contacts []string) {
+	if len(removed) == 0 {
+		return
+	}
+
+	if campo == "telefono" {
+		message := "Besser Solutions: este telefono fue retirado de tu cuenta de MiCuenta. Si no fuiste tu, comunicate con nosotros."
+		for _, telefono := range removed {
+			if utils.FirstCelular([]string{telefono}) == "" {
+				continue
+			}
+			if err := utils.SendSms(telefono, message); err != nil {
+				utils.Logline("error sending the sms of contact removed", telefono, err)
+			}
+		}
+		return
+	}
+
+	bodyEmail := `
+		<!DOCTYPE html>
+		<html lang="en">
+			<head>
+				<meta charset="UTF-8">
+				<meta name="viewport" content="width=device-width, initial-scale=1.0">
+				<title>Correo retirado de tu cuenta</title>
+				<style>
+					body {font-family: Arial, sans-serif; background-color: #f6f8fa; margin: 0;padding: 0; }
+					.container {width: 100%; max-width: 600px; margin: 0 auto; padding: 20px;}
+					.header {text-align: center; padding: 20px 0;}
+					.header img {width: 200px;}
+					.content {padding: 20px; border: 1px solid #e1e4e8; border-radius: 5px;}
+					.content p {font-size: 16px; color: #333333;}
+					.footer {text-align: center; padding: 20px; font-size: 12px; color: #666666;}
+				</style>
+			</head>
+			<body>
+				<div class="container">
+					<div class="header">
+						<img src="cid:image001" alt="Besser Solutions Logo">
+						<h1>Correo retirado de tu cuenta</h1>
+					</div>
+					<div class="content">
+						<b>Hola, </b>
+						<p>Este correo fue retirado de tu cuenta de MiCuenta, ya no recibiras en el las notificaciones ni los codigos de verificacion.</p>
+						<p>Los correos de la cuenta ahora son: ` + maskContacts(contacts) + `</p>
+						<p>Si no fuiste tu, comunicate con nosotros de inmediato.</p>
+						<p>Gracias,<br>El equipo de Besser Solutions</p>
+					</div>
+					<div class="footer">
+						<p>Recibiste este correo electrónico porque fue retirado de una cuenta de MiCuenta.</p>
+						<p>Besser Solutions, C.A. • Santa Irene, Calle San Miguel, Edif. Asdrubal Jose PB • Punto Fijo, Falcon 4102</p>
+					</div>
+				</div>
+			</body>
+		</html>
+	`
+	if err := utils.SendEmail(removed, ginI18n.MustGetMessage(c, "titleContactRemoved"), bodyEmail); err != nil {
+		utils.Logline("error sending the email of contact removed", removed, err)
+	}
+}
+
+func maskContacts(correos []string) string {
+	masked := make([]string, 0, len(correos))
+	for _, correo := range correos {
+		masked = append(masked, utils.MaskEmail(correo))
+	}
+	if len(masked) == 0 {
+		return "ninguno"
+	}
+	return strings.Join(masked, ", ")
+}
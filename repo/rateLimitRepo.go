@@ -0,0 +1,51 @@
+package repo
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"ired.com/micuenta/models"
+	"ired.com/micuenta/utils"
+)
+
+type rateLimit struct {
+	Llave   string
+	Max     int
+	Ventana time.Duration
+}
+
+// count a request of the accion for every llave, if any of them is over its max
+// the request is rejected with 429 and the Retry-After header
+func checkRateLimit(c *gin.Context, db models.ConnDb, accion string, limits ...rateLimit) (int, error) {
+	query := `INSERT INTO publico.auth_rate_limit (accion, llave, ventana_inicio, contador) VALUES ($1, $2, NOW(), 1)
+		ON CONFLICT (accion, llave) DO UPDATE SET
+			contador=CASE WHEN auth_rate_limit.ventana_inicio < NOW() - make_interval(secs => $3) THEN 1 ELSE auth_rate_limit.contador + 1 END,
+			ventana_inicio=CASE WHEN auth_rate_limit.ventana_inicio < NOW() - make_interval(secs => $3) THEN NOW() ELSE auth_rate_limit.ventana_inicio END
+		RETURNING contador, ventana_inicio`
+
+	var wait time.Duration
+	for _, limit := range limits {
+		var contador int
+		var ventanaInicio time.Time
+		err := db.ConnPgsql.QueryRow(db.Ctx, query, accion, limit.Llave, limit.Ventana.Seconds()).Scan(&contador, &ventanaInicio)
+		if err != nil {
+			utils.Logline("error updating auth_rate_limit", accion, limit.Llave, err)
+			return http.StatusInternalServerError, errors.New("errorInternal")
+		}
+
+		if contador > limit.Max {
+			wait = max(wait, time.Until(ventanaInicio.Add(limit.Ventana)))
+		}
+	}
+
+	if wait > 0 {
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+		return http.StatusTooManyRequests, errors.New("tooManyRequests")
+	}
+
+	return http.StatusOK, nil
+}
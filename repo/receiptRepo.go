@@ -0,0 +1,159 @@
+package repo
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/jackc/pgx/v5"
+	"ired.com/micuenta/models"
+	"ired.com/micuenta/utils"
+)
+
+// url of the QR of a comprobante, signed with RECEIPT_VERIFY_SECRET, the token does
+// not expire because the comprobante can be checked at any time
+func receiptVerifyUrl(tipo string, id string, createdAt string) (string, error) {
+	if os.Getenv("RECEIPT_VERIFY_URL") == "" {
+		return "", errors.New("RECEIPT_VERIFY_URL is not defined")
+	}
+
+	claims := &models.ReceiptClaims{
+		Tipo:      tipo,
+		Id:        id,
+		CreatedAt: createdAt,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:   "micuenta",
+			Audience: jwt.ClaimStrings{"receipt"},
+			IssuedAt: jwt.NewNumericDate(time.Now()),
+		},
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	tokenString, err := token.SignedString([]byte(os.Getenv("RECEIPT_VERIFY_SECRET")))
+	if err != nil {
+		return "", err
+	}
+
+	return os.Getenv("RECEIPT_VERIFY_URL") + url.QueryEscape(tokenString), nil
+}
+
+func PaymentPdf(db models.ConnDb, clienteId string, paymentReq models.PaymentReqId) ([]byte, int, error) {
+	payment, errType, err := GetPayment(db, clienteId, paymentReq)
+	if err != nil {
+		return nil, errType, err
+	}
+
+	receipt := models.ReceiptPdf{
+		Titulo:   "Comprobante de Pago",
+		Ncontrol: payment.Ncontrol,
+		Fecha:    payment.Fecha,
+		Lineas: []models.ReceiptLinea{
+			{Etiqueta: "Estatus", Valor: payment.Estatus},
+			{Etiqueta: "Metodo de pago", Valor: payment.MetodoPago.Nombre},
+			{Etiqueta: "Banco origen", Valor: payment.BancoCliente.Nombre},
+			{Etiqueta: "Referencia", Valor: payment.Referencia},
+			{Etiqueta: "Tasa de cambio", Valor: utils.FormatTasa(payment.TasaCambio)},
+			{Etiqueta: "Registrado", Valor: payment.CreatedAt.Format("2006-01-02 15:04")},
+		},
+		Total:   payment.MontoTotal,
+		Empresa: payment.DatosBesser,
+	}
+	for _, suscripcion := range payment.Suscripciones {
+		linea := models.ReceiptLinea{Etiqueta: "Suscripcion " + suscripcion.Oldid + " " + suscripcion.TipoServicio}
+		if suscripcion.DetalleRecibo != nil {
+			linea.Valor = utils.FormatMoneda(*suscripcion.DetalleRecibo)
+		}
+		receipt.Detalle = append(receipt.Detalle, linea)
+	}
+	for _, factura := range payment.Facturas {
+		linea := models.ReceiptLinea{Etiqueta: "Factura " + factura.NumReferencia}
+		if factura.DetalleRecibo != nil {
+			linea.Valor = utils.FormatMoneda(*factura.DetalleRecibo)
+		}
+		receipt.Detalle = append(receipt.Detalle, linea)
+	}
+
+	return generateReceipt(receipt, "payment", payment.PaymentId, paymentReq.CreatedAt)
+}
+
+func TransferPdf(db models.ConnDb, clienteId string, transferReq models.TransferReqId) ([]byte, int, error) {
+	transfer, errType, err := GetTransfer(db, clienteId, transferReq)
+	if err != nil {
+		return nil, errType, err
+	}
+
+	receipt := models.ReceiptPdf{
+		Titulo:   "Comprobante de Transferencia",
+		Ncontrol: transfer.Ncontrol,
+		Fecha:    transfer.CreatedAt.Format("2006-01-02"),
+		Lineas: []models.ReceiptLinea{
+			{Etiqueta: "Destinatario", Valor: transfer.DestinatarioDocId},
+			{Etiqueta: "Descripcion", Valor: transfer.Descripcion},
+			{Etiqueta: "Registrado", Valor: transfer.CreatedAt.Format("2006-01-02 15:04")},
+		},
+		Total:   transfer.Monto,
+		Empresa: models.GetDatosBesser(),
+	}
+
+	return generateReceipt(receipt, "transfer", transfer.TransferId, transferReq.CreatedAt)
+}
+
+func generateReceipt(receipt models.ReceiptPdf, tipo string, id string, createdAt string) ([]byte, int, error) {
+	verifyUrl, err := receiptVerifyUrl(tipo, id, createdAt)
+	if err != nil {
+		utils.Logline("error signing url of receipt", tipo, id, err)
+		return nil, http.StatusInternalServerError, errors.New("errorInternal")
+	}
+	receipt.VerifyUrl = verifyUrl
+
+	content, err := utils.GenerateReceiptPdf(receipt)
+	if err != nil {
+		utils.Logline("error generating pdf of receipt", tipo, id, err)
+		return nil, http.StatusInternalServerError, errors.New("errorInternal")
+	}
+
+	return content, http.StatusOK, nil
+}
+
+// public check of the QR of a comprobante, only the data printed on it is returned
+func VerifyReceipt(c *gin.Context, db models.ConnDb, tokenString string) (*models.ReceiptVerify, int, error) {
+	errType, err := checkRateLimit(c, db, "receipt_verify", rateLimit{Llave: "ip:" + c.ClientIP(), Max: 30, Ventana: time.Minute})
+	if err != nil {
+		return nil, errType, err
+	}
+
+	claims := &models.ReceiptClaims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (any, error) {
+		return []byte(os.Getenv("RECEIPT_VERIFY_SECRET")), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithAudience("receipt"), jwt.WithIssuer("micuenta"))
+	if err != nil || !token.Valid {
+		return nil, http.StatusUnauthorized, errors.New("receiptInvalid")
+	}
+
+	var query string
+	switch claims.Tipo {
+	case "payment":
+		query = `SELECT fecha::text, estatus::text, ROUND(monto[1],2), ROUND(monto[2],2) FROM venta.recibo_pagov WHERE id=$1 AND created_at=$2`
+	case "transfer":
+		query = `SELECT created_at::date::text, 'procesado', ROUND(monto[1],2), ROUND(monto[2],2) FROM venta.transferenciav WHERE id=$1 AND created_at=$2`
+	default:
+		return nil, http.StatusUnauthorized, errors.New("receiptInvalid")
+	}
+
+	receipt := models.ReceiptVerify{Tipo: claims.Tipo, Ncontrol: utils.GenerateNcontrolByUuid(claims.Id)}
+	err = db.ConnPgsql.QueryRow(db.Ctx, query, claims.Id, claims.CreatedAt).Scan(&receipt.Fecha, &receipt.Estatus, &receipt.Monto.Dolar, &receipt.Monto.Bolivar)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, http.StatusNotFound, errors.New("receiptInvalid")
+		}
+		utils.Logline("error getting receipt to verify", claims.Tipo, claims.Id, err)
+		return nil, http.StatusInternalServerError, errors.New("errorInternal")
+	}
+	receipt.Estatus = strings.ToLower(receipt.Estatus)
+
+	return &receipt, http.StatusOK, nil
+}
@@ -0,0 +1,78 @@
+package repo
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"ired.com/micuenta/models"
+	"ired.com/micuenta/utils"
+)
+
+// send a code by sms to the first mobile number of the cliente
+func sendOtpSms(db models.ConnDb, user *models.UserResponse, proposito string, mensaje string) error {
+	celular := utils.FirstCelular(user.Profile.Telefono)
+	if celular == "" {
+		utils.Logline("cliente without mobile phone for sms", user.Profile.Id, proposito)
+		return nil
+	}
+
+	code, err := createOtp(db, user.Profile.Id, proposito, "sms", celular, nil)
+	if err != nil {
+		return err
+	}
+
+	message := "Besser Solutions: " + mensaje + " " + code + ". Vence en " + strconv.Itoa(otpExpiresMinutes()) + " minutos, no lo compartas."
+	if err := utils.SendSms(celular, message); err != nil {
+		utils.Logline("error sending the sms", user.Profile.Id, err)
+		return err
+	}
+
+	return nil
+}
+
+// send the recovery code by sms, the response is the same if the docid does
+// not exist or has no mobile phone to not expose the accounts
+func ForgotPasswordSmsReq(c *gin.Context, db models.ConnDb, userReq models.ForgotPasswordRequest) (int, error) {
+	errType, err := checkRateLimit(c, db, "forgot_password_sms",
+		rateLimit{Llave: "docid:" + userReq.Username, Max: 3, Ventana: time.Hour},
+		rateLimit{Llave: "ip:" + c.ClientIP(), Max: 10, Ventana: time.Hour},
+	)
+	if err != nil {
+		return errType, err
+	}
+
+	user, err := getUser(db, "docid", userReq.Username)
+	if err != nil {
+		if err.Error() == "recordDontExist" {
+			return http.StatusOK, nil
+		}
+		return http.StatusInternalServerError, errors.New("errorInternal")
+	}
+
+	// a failure is only logged (on sendOtpSms), an error would tell that the account exists
+	sendOtpSms(db, user, "password_reset", "tu codigo para reestablecer la contraseña es")
+
+	return http.StatusOK, nil
+}
+
+// verify the sms code and give a reset token for /auth/forgot-password-send
+func ForgotPasswordSmsVerify(c *gin.Context, db models.ConnDb, userReq models.ForgotPasswordSmsVerify) (*models.ResetTokenResponse, int, error) {
+	user, err := getUser(db, "docid", userReq.Username)
+	if err != nil {
+		return nil, http.StatusUnauthorized, errors.New("otpInvalid")
+	}
+
+	if _, errType, err := verifyOtp(db, user.Profile.Id, "password_reset", userReq.Code); err != nil {
+		return nil, errType, err
+	}
+
+	token, err := generateResetToken(c, db, user, "sms")
+	if err != nil {
+		return nil, http.StatusInternalServerError, errors.New("errorInternal")
+	}
+
+	return &models.ResetTokenResponse{Token: token}, http.StatusOK, nil
+}
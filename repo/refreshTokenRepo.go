@@ -0,0 +1,122 @@
+package repo
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"ired.com/micuenta/models"
+	"ired.com/micuenta/utils"
+)
+
+// a token retired less than this time ago is from a refresh made at the same time with the same token
+// (two tabs), it is rejected but it is not taken as a replay
+const refreshReuseGrace = 10 * time.Second
+
+// save a new active refresh token on the family of the session
+func insertRefreshToken(db models.ConnDb, conn pgQuerier, clienteId string, familyId string, sessionKey string, refreshToken string, expiresAt string) error {
+	expiresOn, err := time.Parse(time.RFC3339, expiresAt)
+	if err != nil {
+		utils.Logline("error parsing expiration of refresh token", err)
+		return err
+	}
+
+	query := `INSERT INTO publico.cliente_refresh_token (empresa_id, cliente_id, family_id, session_key, token_hash, estatus, expires_at)
+		VALUES (1, $1, $2, $3, $4, 'activo', $5)`
+	if _, err := conn.Exec(db.Ctx, query, clienteId, familyId, sessionKey, utils.HashToken(refreshToken), expiresOn); err != nil {
+		utils.Logline("error inserting cliente_refresh_token", clienteId, err)
+		return err
+	}
+
+	return nil
+}
+
+// lock the token presented on a refresh until the end of the transaction of the rotation, so a
+// refresh at the same time waits for it. Returns the estatus of the token, pgx.ErrNoRows when it does not exist
+func lockRefreshToken(db models.ConnDb, tx pgx.Tx, familyId string, refreshToken string) (string, *time.Time, error) {
+	var estatus string
+	var retiredAt *time.Time
+	query := `SELECT estatus, retired_at FROM publico.cliente_refresh_token WHERE token_hash=$1 AND family_id=$2 FOR UPDATE`
+	if err := tx.QueryRow(db.Ctx, query, utils.HashToken(refreshToken), familyId).Scan(&estatus, &retiredAt); err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			utils.Logline("error locking cliente_refresh_token", familyId, err)
+		}
+		return "", nil, err
+	}
+
+	return estatus, retiredAt, nil
+}
+
+// retire the token presented on a refresh, it must be locked first with lockRefreshToken
+func retireRefreshToken(db models.ConnDb, tx pgx.Tx, familyId string, refreshToken string) error {
+	query := `UPDATE publico.cliente_refresh_token SET estatus='retirado', retired_at=NOW()
+		WHERE token_hash=$1 AND family_id=$2 AND estatus='activo'`
+	if _, err := tx.Exec(db.Ctx, query, utils.HashToken(refreshToken), familyId); err != nil {
+		utils.Logline("error retiring cliente_refresh_token", familyId, err)
+		return err
+	}
+
+	return nil
+}
+
+// look up the family of a token that is not longer active, used to detect a replay
+func getRetiredRefreshToken(db models.ConnDb, refreshToken string) (string, string, error) {
+	var familyId, clienteId string
+	query := `SELECT family_id::text, cliente_id::text FROM publico.cliente_refresh_token WHERE token_hash=$1 AND estatus='retirado' LIMIT 1`
+	if err := db.ConnPgsql.QueryRow(db.Ctx, query, utils.HashToken(refreshToken)).Scan(&familyId, &clienteId); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", "", nil
+		}
+		utils.Logline("error getting cliente_refresh_token", err)
+		return "", "", err
+	}
+
+	return familyId, clienteId, nil
+}
+
+// revoke every token of the family and delete all the sessions that belong to it
+func revokeRefreshFamily(c *gin.Context, db models.ConnDb, familyId string, clienteId string, motivo string) error {
+	query := `WITH revocados AS (
+			UPDATE publico.cliente_refresh_token SET estatus='revocado' WHERE family_id=$1 AND estatus<>'revocado' RETURNING session_key
+		)
+		DELETE FROM publico.cliente_session_store WHERE key IN (SELECT DISTINCT session_key FROM revocados)`
+	if _, err := db.ConnPgsql.Exec(db.Ctx, query, familyId); err != nil {
+		utils.Logline("error revoking refresh token family", familyId, err)
+		return err
+	}
+
+	logAuthEvent(c, db, clienteId, motivo, map[string]any{"family_id": familyId})
+
+	return nil
+}
+
+// revoke the active tokens of a session that was closed
+func revokeSessionRefreshTokens(db models.ConnDb, sessionKeys []string) error {
+	query := `UPDATE publico.cliente_refresh_token SET estatus='revocado' WHERE session_key=ANY($1) AND estatus='activo'`
+	if _, err := db.ConnPgsql.Exec(db.Ctx, query, sessionKeys); err != nil {
+		utils.Logline("error revoking refresh tokens of sessions", err)
+		return err
+	}
+
+	return nil
+}
+
+// when a retired token is presented again someone is replaying it, so the
+// whole family is revoked and true is returned
+func detectRefreshTokenReuse(c *gin.Context, db models.ConnDb, refreshToken string) (bool, int, error) {
+	familyId, clienteId, err := getRetiredRefreshToken(db, refreshToken)
+	if err != nil {
+		return false, http.StatusInternalServerError, errors.New("errorInternal")
+	}
+	if familyId == "" {
+		return false, http.StatusOK, nil
+	}
+
+	if err := revokeRefreshFamily(c, db, familyId, clienteId, "refresh_token_reuse"); err != nil {
+		return true, http.StatusInternalServerError, errors.New("errorInternal")
+	}
+
+	return true, http.StatusOK, nil
+}
@@ -0,0 +1,79 @@
+package repo
+
+import (
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"ired.com/micuenta/models"
+	"ired.com/micuenta/utils"
+)
+
+// signed token for /auth/forgot-password-send, the subject is the docid and
+// only its hash is saved so it can be consumed once
+func generateResetToken(c *gin.Context, db models.ConnDb, user *models.UserResponse, canal string) (string, error) {
+	// get MaxAge for the authLink to work
+	linkMaxAge, err := strconv.Atoi(os.Getenv("LINK_MAX_AGE"))
+	if err != nil {
+		utils.Logline("error parsing LINK_MAX_AGE", err)
+		return "", err
+	}
+	linkExpirationTime := time.Now().Add(time.Duration(linkMaxAge) * time.Second)
+
+	// create claims for reset token
+	claims := &models.Claims{
+		UserId: user.Profile.Username,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    "micuenta",
+			Subject:   user.Profile.Username,
+			ID:        utils.GenerateUUID(),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(linkExpirationTime),
+		},
+	}
+	// Sign and get the complete encoded token as a string using the secret
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	tokenString, err := token.SignedString([]byte(os.Getenv("LINK_SECRET")))
+	if err != nil {
+		utils.Logline("error signing reset token", err)
+		return "", err
+	}
+
+	if err := insertResetToken(db, user.Profile.Id, tokenString, canal, c.ClientIP(), linkExpirationTime); err != nil {
+		return "", err
+	}
+
+	return tokenString, nil
+}
+
+// save the hash of a new reset token, the previous tokens not used are discarded
+func insertResetToken(db models.ConnDb, clienteId string, token string, canal string, ip string, expiresAt time.Time) error {
+	query := `DELETE FROM publico.cliente_reset_token WHERE cliente_id=$1 AND used_at IS NULL`
+	if _, err := db.ConnPgsql.Exec(db.Ctx, query, clienteId); err != nil {
+		utils.Logline("error deleting previous cliente_reset_token", clienteId, err)
+		return err
+	}
+
+	query = `INSERT INTO publico.cliente_reset_token (empresa_id, cliente_id, token_hash, canal, ip, expires_at) VALUES (1, $1, $2, $3, $4, $5)`
+	if _, err := db.ConnPgsql.Exec(db.Ctx, query, clienteId, utils.HashToken(token), canal, ip, expiresAt); err != nil {
+		utils.Logline("error inserting cliente_reset_token", clienteId, err)
+		return err
+	}
+
+	return nil
+}
+
+// mark the token as used, false if it does not exist, expired or was already used
+func consumeResetToken(db models.ConnDb, conn pgQuerier, clienteId string, token string) (bool, error) {
+	query := `UPDATE publico.cliente_reset_token SET used_at=NOW() 
+		WHERE cliente_id=$1 AND token_hash=$2 AND used_at IS NULL AND expires_at>NOW()`
+	result, err := conn.Exec(db.Ctx, query, clienteId, utils.HashToken(token))
+	if err != nil {
+		utils.Logline("error updating cliente_reset_token", clienteId, err)
+		return false, err
+	}
+
+	return result.RowsAffected() == 1, nil
+}
@@ -0,0 +1,109 @@
+package repo
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"ired.com/micuenta/models"
+	"ired.com/micuenta/utils"
+)
+
+func SessionList(c *gin.Context, db models.ConnDb) (*[]models.SessionList, int, error) {
+	userId, _ := c.Get("userId")
+	currentKey := sessions.Default(c).ID()
+
+	query := `SELECT id::text, key, COALESCE(device_info::text, '') as device_info, created_at, modified_at, expires_at
+		FROM publico.cliente_session_store
+		WHERE empresa_id=1 AND cliente_id=$1 AND expires_at>NOW()
+		ORDER BY modified_at DESC`
+	rows, err := db.ConnPgsql.Query(db.Ctx, query, userId)
+	if err != nil {
+		utils.Logline("error on select cliente_session_store", err)
+		return nil, http.StatusBadRequest, errors.New("errorGetData")
+	}
+	defer rows.Close()
+
+	sessionList := []models.SessionList{}
+	for rows.Next() {
+		var session models.SessionList
+		var key, deviceInfo string
+		err = rows.Scan(&session.Id, &key, &deviceInfo, &session.CreatedAt, &session.LastUsedAt, &session.ExpiresAt)
+		if err != nil {
+			utils.Logline("error scanning cliente_session_store", userId, err)
+			return nil, http.StatusBadRequest, errors.New("errorGetData")
+		}
+
+		// device info is saved as json on login, old sessions could have it empty
+		if deviceInfo != "" {
+			if err := json.Unmarshal([]byte(deviceInfo), &session.DeviceInfo); err != nil {
+				utils.Logline("error parsing device_info of session", session.Id, err)
+			}
+		}
+
+		session.Current = key == currentKey
+		sessionList = append(sessionList, session)
+	}
+	rows.Close()
+
+	return &sessionList, http.StatusOK, nil
+}
+
+func RevokeSession(c *gin.Context, db models.ConnDb, sessionReq models.SessionReqId) (int, error) {
+	userId, _ := c.Get("userId")
+
+	var key, data string
+	query := `DELETE FROM publico.cliente_session_store WHERE empresa_id=1 AND cliente_id=$1 AND id=$2 RETURNING key, data`
+	if err := db.ConnPgsql.QueryRow(db.Ctx, query, userId, sessionReq.Id).Scan(&key, &data); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return http.StatusBadRequest, errors.New("recordDontExist")
+		}
+		utils.Logline("error deleting cliente_session_store", userId, sessionReq, err)
+		return http.StatusInternalServerError, errors.New("errorDeleteRecord")
+	}
+
+	revokeSessionRefreshTokens(db, []string{key})
+	revokeSessionAuthTokens(db, userId.(string), []string{data}, "session_revoked")
+
+	// if the user revoke the session in use also clear the cookie
+	if key == sessions.Default(c).ID() {
+		Logout(c, db)
+	}
+
+	return http.StatusOK, nil
+}
+
+func RevokeOtherSessions(c *gin.Context, db models.ConnDb) (int, error) {
+	userId, _ := c.Get("userId")
+	currentKey := sessions.Default(c).ID()
+
+	query := `DELETE FROM publico.cliente_session_store WHERE empresa_id=1 AND cliente_id=$1 AND key<>$2 RETURNING key, data`
+	rows, err := db.ConnPgsql.Query(db.Ctx, query, userId, currentKey)
+	if err != nil {
+		utils.Logline("error deleting other sessions of cliente", userId, err)
+		return http.StatusInternalServerError, errors.New("errorDeleteRecord")
+	}
+	var keys, sessionsData []string
+	for rows.Next() {
+		var key, data string
+		if err := rows.Scan(&key, &data); err != nil {
+			utils.Logline("error deleting other sessions of cliente", userId, err)
+			return http.StatusInternalServerError, errors.New("errorDeleteRecord")
+		}
+		keys = append(keys, key)
+		sessionsData = append(sessionsData, data)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		utils.Logline("error deleting other sessions of cliente", userId, err)
+		return http.StatusInternalServerError, errors.New("errorDeleteRecord")
+	}
+
+	revokeSessionRefreshTokens(db, keys)
+	revokeSessionAuthTokens(db, userId.(string), sessionsData, "session_revoked")
+
+	return http.StatusOK, nil
+}
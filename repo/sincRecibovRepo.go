@@ -27,12 +27,12 @@ func SincReciboVenta(db models.ConnMysqlPgsql, caller string, tipo string) error
 		montoPendienteMysql = "AND (rp.pendiente_monto2+0)<=0"
 	}
 
-	//get fecha of last record on postgres
+	//get fecha of last record on postgres, only the ones that came from mysql (payments canceled on the app are not)
 	var lastRecordDate, endRecordDate string
 	query := `SELECT TO_CHAR(created_at, 'YYYY-MM-DD HH24:MI:SS') as fecha,
 			TO_CHAR(created_at + INTERVAL '8 MONTH', 'YYYY-MM-DD HH24:MI:SS') as fecha_end
 		FROM venta.recibo_pagov
-		WHERE estatus=$1
+		WHERE estatus=$1 AND COALESCE(info->>'recibo_pago_id', '')<>''
 		ORDER BY created_at DESC 
 		LIMIT 1`
 	err := db.ConnPgsql.QueryRow(db.Ctx, query, estatusPgsql).Scan(&lastRecordDate, &endRecordDate)
@@ -1,14 +1,21 @@
 package repo
 
 import (
+	"database/sql"
 	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
 	"ired.com/micuenta/models"
 	"ired.com/micuenta/utils"
 )
 
+const (
+	suscripcionProyeccionMeses = 6
+	suscripcionDetalleLimite   = 24
+)
+
 func SuscripcionList(c *gin.Context, db models.ConnDb) (*[]models.Suscripcion, int, error) {
 	userId, _ := c.Get("userId")
 
@@ -16,7 +23,8 @@ func SuscripcionList(c *gin.Context, db models.ConnDb) (*[]models.Suscripcion, i
 			regexp_replace(sv.nombre, '[^0-9]', '', 'g')::integer as speed_value, 'Mbps' as speed_unit,
 			SPLIT_PART(st.nombre,'/',1) as zona, SPLIT_PART(st.nombre,'/',2) as tipo_conexion, SPLIT_PART(st.nombre,'/',3) as tipo_servicio,
 			e.info->>'gps' as gps, TO_CHAR((s.info->>'fecha_install')::date, 'DD') as dia_renovacion,
-			s.precio[1] as costo, COALESCE((SELECT * FROM publico.latest_tasa_cambio(1)),1) as tasa_cambio, (s.info->>'convenio')::boolean as convenio, COALESCE(ROUND(saldo.saldo_dolar,2),0) as saldo_dolar
+			s.precio[1] as costo, COALESCE((SELECT * FROM publico.latest_tasa_cambio(1)),1) as tasa_cambio, (s.info->>'convenio')::boolean as convenio, COALESCE(ROUND(saldo.saldo_dolar,2),0) as saldo_dolar,
+			COALESCE(ap.activo, false) as autopago
 		FROM administracion.suscripcion as s
 		LEFT JOIN (
 			SELECT suscripcion_id, ROUND(saldo[1],2) as saldo_dolar FROM venta.get_saldo(1, $1, 'total') WHERE suscripcion_id IS NOT NULL
@@ -24,6 +32,7 @@ func SuscripcionList(c *gin.Context, db models.ConnDb) (*[]models.Suscripcion, i
 		LEFT JOIN administracion.servicio as sv ON sv.id=s.servicio_id
 		LEFT JOIN administracion.servicio_tipo as st ON st.id=sv.servicio_tipo_id
 		LEFT JOIN network.estacion as e ON e.suscripcion_id=s.id
+		LEFT JOIN publico.suscripcion_autopago as ap ON ap.suscripcion_id=s.id
 		WHERE s.cliente_id=$1`
 	rows, err := db.ConnPgsql.Query(db.Ctx, query, userId)
 	if err != nil {
@@ -38,7 +47,8 @@ func SuscripcionList(c *gin.Context, db models.ConnDb) (*[]models.Suscripcion, i
 		var costoUsd, tasa, saldoDolar float64
 		var tipo_servicio, gps, renewalDay string
 		err = rows.Scan(&suscripcion.Id, &suscripcion.Ncontrol, &suscripcion.Estatus, &suscripcion.SpeedValue, &suscripcion.SpeedUnit,
-			&suscripcion.Zona, &suscripcion.TipoConexion, &tipo_servicio, &gps, &renewalDay, &costoUsd, &tasa, &suscripcion.ExcluirPago, &saldoDolar)
+			&suscripcion.Zona, &suscripcion.TipoConexion, &tipo_servicio, &gps, &renewalDay, &costoUsd, &tasa, &suscripcion.ExcluirPago, &saldoDolar,
+			&suscripcion.Autopago)
 		if err != nil {
 			utils.Logline("error scanning suscripciones", userId, err)
 			return nil, http.StatusBadRequest, errors.New("errorGetData")
@@ -89,3 +99,183 @@ func GetSuscripcion(db models.ConnDb, clienteId string, suscId string) (*models.
 
 	return &suscripcion, http.StatusOK, nil
 }
+
+// facturas of the suscripcion, fv is the facturav and $2 the suscripcion id
+const facturaSuscripcionCond = `EXISTS (
+		SELECT 1 FROM venta.facturav_det as det
+		WHERE det.facturav_id=fv.id AND det.created_at=fv.created_at AND det.info->'suscripcion'->>'id'=$2::text
+	)`
+
+// full view of the suscripcion of the cliente: plan, billing calendar (the projection is of
+// suscripcionProyeccionMeses), saldo and its latest facturas, payments and retenciones. The changes
+// of estatus are not registered by the back office, only the current one and the install date are shown
+func SuscripcionShow(db models.ConnDb, clienteId string, suscripcionReq models.SuscripcionReqId) (*models.SuscripcionDetalle, int, error) {
+	suscripcion, errType, err := GetSuscripcion(db, clienteId, suscripcionReq.Id)
+	if err != nil {
+		return nil, errType, err
+	}
+	detalle := models.SuscripcionDetalle{Suscripcion: *suscripcion, ClienteId: clienteId}
+	detalle.TipoServicioAcronimo = utils.TipoServicioAcronimo(suscripcion.TipoServicio)
+	detalle.TipoServicio = utils.TipoServicioNombre(suscripcion.TipoServicio)
+	detalle.Gps = utils.ValidateGPS(suscripcion.Gps)
+
+	var fechaInstall sql.NullTime
+	var tasaCambio float64
+	query := `SELECT COALESCE(sv.nombre, '') as plan, (s.info->>'fecha_install')::date as fecha_install, COALESCE((SELECT * FROM publico.latest_tasa_cambio(1)),1) as tasa_cambio,
+			COALESCE(ap.activo, false) as autopago, COALESCE((s.info->>'convenio')::boolean, false) as convenio
+		FROM administracion.suscripcion as s
+		LEFT JOIN administracion.servicio as sv ON sv.id=s.servicio_id
+		LEFT JOIN publico.suscripcion_autopago as ap ON ap.suscripcion_id=s.id
+		WHERE s.cliente_id=$1 AND s.id=$2`
+	err = db.ConnPgsql.QueryRow(db.Ctx, query, clienteId, suscripcionReq.Id).Scan(&detalle.Plan, &fechaInstall, &tasaCambio,
+		&detalle.Autopago, &detalle.ExcluirPago)
+	if err != nil {
+		utils.Logline("error on select detail of suscripcion", clienteId, suscripcionReq, err)
+		return nil, http.StatusBadRequest, errors.New("errorGetData")
+	}
+
+	// the renewal dates are the ones of Caracas, the same of the crons
+	if fechaInstall.Valid {
+		calendario := utils.SuscripcionCalendario(fechaInstall.Time, utils.NowCaracas(), suscripcion.Costo.Dolar, tasaCambio, suscripcionProyeccionMeses)
+		detalle.Calendario = &calendario
+		detalle.FechaInstalacion = calendario.FechaInstalacion
+	}
+	if err := getSuscripcionSaldo(db, &detalle, clienteId, suscripcionReq.Id); err != nil {
+		return nil, http.StatusBadRequest, errors.New("errorGetData")
+	}
+	if err := getSuscripcionDocumentos(db, &detalle, clienteId, suscripcionReq.Id); err != nil {
+		return nil, http.StatusBadRequest, errors.New("errorGetData")
+	}
+
+	return &detalle, http.StatusOK, nil
+}
+
+// same view of SuscripcionShow for the support staff, the cliente is the one of the suscripcion
+func AdminSuscripcionShow(db models.ConnDb, suscripcionReq models.SuscripcionReqId) (*models.SuscripcionDetalle, int, error) {
+	var clienteId string
+	query := `SELECT cliente_id::text FROM administracion.suscripcion WHERE id=$1`
+	if err := db.ConnPgsql.QueryRow(db.Ctx, query, suscripcionReq.Id).Scan(&clienteId); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, http.StatusNotFound, errors.New("recordDontExist")
+		}
+		utils.Logline("error on select cliente of suscripcion", suscripcionReq, err)
+		return nil, http.StatusBadRequest, errors.New("errorGetData")
+	}
+
+	return SuscripcionShow(db, clienteId, suscripcionReq)
+}
+
+// saldo of the suscripcion with only the payments processed and with all of them
+func getSuscripcionSaldo(db models.ConnDb, detalle *models.SuscripcionDetalle, clienteId string, suscripcionId string) error {
+	query := `SELECT COALESCE(ROUND(p.saldo[1],2),0), COALESCE(ROUND(p.saldo[2],2),0), COALESCE(ROUND(t.saldo[1],2),0), COALESCE(ROUND(t.saldo[2],2),0)
+		FROM (SELECT 1) as q0
+		LEFT JOIN venta.get_saldo(1, $1, 'procesado') as p ON p.suscripcion_id=$2
+		LEFT JOIN venta.get_saldo(1, $1, 'total') as t ON t.suscripcion_id=$2`
+	saldo := &detalle.SaldoDetalle
+	err := db.ConnPgsql.QueryRow(db.Ctx, query, clienteId, suscripcionId).Scan(&saldo.Procesado.Dolar, &saldo.Procesado.Bolivar,
+		&saldo.Total.Dolar, &saldo.Total.Bolivar)
+	if err != nil {
+		utils.Logline("error on select saldo of suscripcion", suscripcionId, err)
+		return err
+	}
+
+	saldo.PorConfirmar.Dolar = utils.RoundToTwoDecimalPlaces(saldo.Total.Dolar - saldo.Procesado.Dolar)
+	saldo.PorConfirmar.Bolivar = utils.RoundToTwoDecimalPlaces(saldo.Total.Bolivar - saldo.Procesado.Bolivar)
+
+	return nil
+}
+
+// latest facturas, payments and retenciones of the suscripcion, suscripcionDetalleLimite of each one
+func getSuscripcionDocumentos(db models.ConnDb, detalle *models.SuscripcionDetalle, clienteId string, suscripcionId string) error {
+	detalle.Facturas = []models.FacturaList{}
+	detalle.Pagos = []models.PaymentList{}
+	detalle.Retenciones = []models.RetencionList{}
+
+	query := `SELECT fv.id, fv.estatus, fv.total[1] as tot_dolar, fv.total[2] as tot_bolivar, fv.created_at
+		FROM venta.facturav as fv
+		WHERE fv.cliente_id=$1 AND ` + facturaSuscripcionCond + `
+		ORDER BY fv.created_at DESC
+		LIMIT $3`
+	rows, err := db.ConnPgsql.Query(db.Ctx, query, clienteId, suscripcionId, suscripcionDetalleLimite)
+	if err != nil {
+		utils.Logline("error on select facturav of suscripcion", suscripcionId, err)
+		return err
+	}
+	for rows.Next() {
+		var factura models.FacturaList
+		if err := rows.Scan(&factura.Id, &factura.Estatus, &factura.Total.Dolar, &factura.Total.Bolivar, &factura.CreatedAt); err != nil {
+			rows.Close()
+			utils.Logline("error scanning facturav of suscripcion", suscripcionId, err)
+			return err
+		}
+		factura.NumReferencia = utils.GenerateNcontrolByUuid(factura.Id)
+		detalle.Facturas = append(detalle.Facturas, factura)
+	}
+	rows.Close()
+
+	// payments of the suscripcion or of its facturas
+	query = `SELECT rp.id as payment_id, rp.estatus, rp.fecha::text as fecha,
+			COALESCE(rp.referencia, '') as referencia, rp.monto[1] as tot_dolar, rp.monto[2] as tot_bolivar, rp.created_at,
+			mpago.id as mpago_id, mpago.banco as mpago_banco, mpago.metodo_pago as mpago_metodo, mpago.moneda as mpago_moneda, mpago.info->>'web_nombre' as mpago_nombre, mpago.info->>'web_detail' as mpago_detalle
+		FROM venta.recibo_pagov as rp
+		LEFT JOIN publico.cuenta_banco as mpago ON mpago.id=rp.metodo_pago_id
+		WHERE rp.cliente_id=$1 AND EXISTS (
+			SELECT 1 FROM jsonb_array_elements(COALESCE(rp.info->'payment_detail', '[]'::jsonb)) AS payment
+			WHERE payment->'suscripcion'->>'id'=$2::text OR payment->'factura'->>'id' IN (
+				SELECT fv.id::text FROM venta.facturav as fv WHERE fv.cliente_id=$1 AND ` + facturaSuscripcionCond + `
+			)
+		)
+		ORDER BY rp.created_at DESC
+		LIMIT $3`
+	rows, err = db.ConnPgsql.Query(db.Ctx, query, clienteId, suscripcionId, suscripcionDetalleLimite)
+	if err != nil {
+		utils.Logline("error on select recibo_pagov of suscripcion", suscripcionId, err)
+		return err
+	}
+	for rows.Next() {
+		var payment models.PaymentList
+		var metodoPagoInfo sql.NullString
+		err = rows.Scan(&payment.PaymentId, &payment.Estatus, &payment.Fecha, &payment.Referencia, &payment.MontoTotal.Dolar, &payment.MontoTotal.Bolivar, &payment.CreatedAt,
+			&payment.MetodoPago.Id, &payment.MetodoPago.Banco, &payment.MetodoPago.MetodoPago, &payment.MetodoPago.Moneda, &payment.MetodoPago.Nombre, &metodoPagoInfo)
+		if err != nil {
+			rows.Close()
+			utils.Logline("error scanning recibo_pagov of suscripcion", suscripcionId, err)
+			return err
+		}
+
+		if metodoPagoInfo.Valid {
+			payment.MetodoPago.Detalle = getFormaPagoDetail(metodoPagoInfo.String)
+		}
+		payment.Ncontrol = utils.GenerateNcontrolByUuid(payment.PaymentId)
+		detalle.Pagos = append(detalle.Pagos, payment)
+	}
+	rows.Close()
+
+	query = `SELECT r.id, COALESCE(fv.nfactura, ''), r.num_comprobante, r.tipo_retencion, r.estatus, r.fecha_retencion::text, r.created_at,
+			r.monto_retenido[1] as monto_retenido_dolar, r.monto_retenido[2] as monto_retenido_bolivar
+		FROM venta.facturav_retencion as r
+		JOIN venta.facturav as fv ON fv.id=r.facturav_id AND fv.created_at=r.facturav_created_at
+		WHERE fv.cliente_id=$1 AND ` + facturaSuscripcionCond + `
+		ORDER BY r.created_at DESC
+		LIMIT $3`
+	rows, err = db.ConnPgsql.Query(db.Ctx, query, clienteId, suscripcionId, suscripcionDetalleLimite)
+	if err != nil {
+		utils.Logline("error on select facturav_retencion of suscripcion", suscripcionId, err)
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var retencion models.RetencionList
+		err = rows.Scan(&retencion.Id, &retencion.NFactura, &retencion.NComprobante, &retencion.TipoRetencion, &retencion.Estatus, &retencion.FechaRetencion, &retencion.CreatedAt,
+			&retencion.MontoRetenido.Dolar, &retencion.MontoRetenido.Bolivar)
+		if err != nil {
+			utils.Logline("error scanning facturav_retencion of suscripcion", suscripcionId, err)
+			return err
+		}
+
+		retencion.NControl = utils.GenerateNcontrolByUuid(retencion.Id)
+		detalle.Retenciones = append(detalle.Retenciones, retencion)
+	}
+
+	return rows.Err()
+}
@@ -0,0 +1,205 @@
+package repo
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"ired.com/micuenta/models"
+	"ired.com/micuenta/utils"
+)
+
+// tokens not revoked are cached this time, it is the max delay for an instance
+// to see a revocation made on other instance
+const revocationNegativeTtl = 30 * time.Second
+
+type revocationCacheEntry struct {
+	ClienteId string
+	Revoked   bool
+	Until     time.Time
+}
+
+// in-process cache of the revocation checks by jti
+var revocationCache = struct {
+	sync.Mutex
+	entries map[string]revocationCacheEntry
+}{entries: map[string]revocationCacheEntry{}}
+
+func revocationCacheGet(jti string) (bool, bool) {
+	revocationCache.Lock()
+	defer revocationCache.Unlock()
+
+	entry, ok := revocationCache.entries[jti]
+	if !ok || time.Now().After(entry.Until) {
+		delete(revocationCache.entries, jti)
+		return false, false
+	}
+	return entry.Revoked, true
+}
+
+func revocationCacheSet(jti string, entry revocationCacheEntry) {
+	revocationCache.Lock()
+	defer revocationCache.Unlock()
+
+	// clear expired entries once in a while to keep the map small
+	if len(revocationCache.entries) > 10000 {
+		now := time.Now()
+		for key, value := range revocationCache.entries {
+			if now.After(value.Until) {
+				delete(revocationCache.entries, key)
+			}
+		}
+	}
+	revocationCache.entries[jti] = entry
+}
+
+// remove the cached checks of the cliente so a revocation of all its tokens applies at once
+func revocationCacheClearCliente(clienteId string) {
+	revocationCache.Lock()
+	defer revocationCache.Unlock()
+
+	for key, value := range revocationCache.entries {
+		if value.ClienteId == clienteId && !value.Revoked {
+			delete(revocationCache.entries, key)
+		}
+	}
+}
+
+// max time an access token is valid, used as expiration of the revocations
+func authTokenMaxAge() time.Duration {
+	authMaxAge, err := strconv.Atoi(os.Getenv("AUTH_MAX_AGE"))
+	if err != nil {
+		utils.Logline("error parsing AUTH_MAX_AGE", err)
+		authMaxAge = 3600
+	}
+	return time.Duration(authMaxAge) * time.Second
+}
+
+// check if the access token was revoked by its jti or by a revocation of all the tokens of the cliente
+func IsTokenRevoked(db models.ConnDb, claims *models.Claims) (bool, error) {
+	if claims.ID != "" {
+		if revoked, ok := revocationCacheGet(claims.ID); ok {
+			return revoked, nil
+		}
+	}
+
+	var issuedAt time.Time
+	if claims.IssuedAt != nil {
+		issuedAt = claims.IssuedAt.Time
+	}
+
+	var revoked bool
+	query := `SELECT EXISTS(
+			SELECT 1 FROM publico.cliente_token_revocado WHERE jti=$1 AND expires_at>NOW()
+		) OR EXISTS(
+			SELECT 1 FROM publico.cliente_token_revocado WHERE cliente_id=$2 AND jti IS NULL AND issued_before>$3 AND expires_at>NOW()
+		)`
+	err := db.ConnPgsql.QueryRow(db.Ctx, query, claims.ID, claims.UserId, issuedAt).Scan(&revoked)
+	if err != nil {
+		utils.Logline("error checking cliente_token_revocado", claims.UserId, err)
+		return false, err
+	}
+
+	if claims.ID != "" {
+		until := time.Now().Add(revocationNegativeTtl)
+		if revoked && claims.ExpiresAt != nil {
+			until = claims.ExpiresAt.Time
+		}
+		revocationCacheSet(claims.ID, revocationCacheEntry{ClienteId: claims.UserId, Revoked: revoked, Until: until})
+	}
+
+	return revoked, nil
+}
+
+// revoke one access token by its jti
+func revokeToken(db models.ConnDb, clienteId string, jti string, motivo string) error {
+	expiresAt := time.Now().Add(authTokenMaxAge())
+	query := `INSERT INTO publico.cliente_token_revocado (empresa_id, cliente_id, jti, motivo, expires_at) VALUES (1, $1, $2, $3, $4)
+		ON CONFLICT (jti) WHERE jti IS NOT NULL DO NOTHING`
+	if _, err := db.ConnPgsql.Exec(db.Ctx, query, clienteId, jti, motivo, expiresAt); err != nil {
+		utils.Logline("error inserting cliente_token_revocado", clienteId, jti, err)
+		return err
+	}
+
+	revocationCacheSet(jti, revocationCacheEntry{ClienteId: clienteId, Revoked: true, Until: expiresAt})
+	return nil
+}
+
+// revoke all the access tokens of the cliente issued until now, issued_before keeps the full
+// precision so the tokens issued on the same second (iat has seconds precision) are revoked too
+func revokeClienteTokens(db models.ConnDb, clienteId string, motivo string) error {
+	issuedBefore := time.Now()
+	query := `INSERT INTO publico.cliente_token_revocado (empresa_id, cliente_id, issued_before, motivo, expires_at) VALUES (1, $1, $2, $3, $4)`
+	if _, err := db.ConnPgsql.Exec(db.Ctx, query, clienteId, issuedBefore, motivo, time.Now().Add(authTokenMaxAge())); err != nil {
+		utils.Logline("error inserting cliente_token_revocado", clienteId, err)
+		return err
+	}
+
+	revocationCacheClearCliente(clienteId)
+	return nil
+}
+
+// delete the sessions of the cliente and revoke all its refresh token families, so a refresh
+// can not give new access tokens after a revocation. exceptKey is the session kept (the current
+// one on a password change), empty to close all of them. Returns the number of sessions closed
+func revokeClienteSessions(db models.ConnDb, clienteId string, exceptKey string) (int, error) {
+	query := `UPDATE publico.cliente_refresh_token SET estatus='revocado'
+		WHERE cliente_id=$1 AND session_key<>$2 AND estatus<>'revocado'`
+	if _, err := db.ConnPgsql.Exec(db.Ctx, query, clienteId, exceptKey); err != nil {
+		utils.Logline("error revoking refresh tokens of cliente", clienteId, err)
+		return 0, err
+	}
+
+	query = `DELETE FROM publico.cliente_session_store WHERE empresa_id=1 AND cliente_id=$1 AND key<>$2`
+	result, err := db.ConnPgsql.Exec(db.Ctx, query, clienteId, exceptKey)
+	if err != nil {
+		utils.Logline("error deleting sessions of cliente", clienteId, err)
+		return 0, err
+	}
+
+	return int(result.RowsAffected()), nil
+}
+
+// revoke the access token in use by each session deleted, sessionsData is the data column of
+// cliente_session_store that keeps the jti of the last access token given to the session
+func revokeSessionAuthTokens(db models.ConnDb, clienteId string, sessionsData []string, motivo string) {
+	for _, data := range sessionsData {
+		values, err := utils.DecodeSessionValues(data)
+		if err != nil {
+			utils.Logline("error decoding data of session", clienteId, err)
+			continue
+		}
+		if authJti, _ := values["auth_jti"].(string); authJti != "" {
+			revokeToken(db, clienteId, authJti, motivo)
+		}
+	}
+}
+
+// revoke from back office a token or all the tokens and sessions of a cliente
+func AdminRevokeTokens(c *gin.Context, db models.ConnDb, adminReq models.AdminRevokeReq) (int, error) {
+	if adminReq.Jti != "" {
+		if err := revokeToken(db, adminReq.ClienteId, adminReq.Jti, "admin"); err != nil {
+			return http.StatusInternalServerError, errors.New("errorInsertRecord")
+		}
+		logAuthEvent(c, db, adminReq.ClienteId, "token_revoked", map[string]any{"jti": adminReq.Jti, "motivo": "admin"})
+		return http.StatusOK, nil
+	}
+
+	if err := revokeClienteTokens(db, adminReq.ClienteId, "admin"); err != nil {
+		return http.StatusInternalServerError, errors.New("errorInsertRecord")
+	}
+
+	// the sessions are closed too, if not a refresh would give new tokens
+	sesiones, err := revokeClienteSessions(db, adminReq.ClienteId, "")
+	if err != nil {
+		return http.StatusInternalServerError, errors.New("errorDeleteRecord")
+	}
+
+	logAuthEvent(c, db, adminReq.ClienteId, "tokens_revoked", map[string]any{"motivo": "admin", "sessions": sesiones})
+
+	return http.StatusOK, nil
+}
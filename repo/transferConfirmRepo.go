@@ -0,0 +1,160 @@
+package repo
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	ginI18n "github.com/gin-contrib/i18n"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"ired.com/micuenta/models"
+	"ired.com/micuenta/utils"
+)
+
+// seconds the cliente has to confirm a prepared transfer, TRANSFER_PREPARE_MAX_AGE default 5 minutes
+func transferPrepareMaxAge() time.Duration {
+	maxAge, err := strconv.Atoi(os.Getenv("TRANSFER_PREPARE_MAX_AGE"))
+	if err != nil || maxAge <= 0 {
+		maxAge = 300
+	}
+
+	return time.Duration(maxAge) * time.Second
+}
+
+// validate the recipient, the saldo and the limits of the transfer, save the quote with the
+// latest tasa_cambio and send the code to confirm it, a new prepared transfer discards the code of the previous one
+func PrepareTransfer(c *gin.Context, db models.ConnDb, clienteId string, prepareReq models.TransferPrepareReq) (*models.TransferPrepared, int, error) {
+	errType, err := checkRateLimit(c, db, "transfer_prepare", rateLimit{Llave: "cliente:" + clienteId, Max: 10, Ventana: time.Hour})
+	if err != nil {
+		return nil, errType, err
+	}
+
+	recipient, clienteDestinoId, errType, err := getTransferRecipient(db, clienteId, prepareReq.DestinatarioDocId)
+	if err != nil {
+		return nil, errType, err
+	}
+
+	var saldo float64
+	query := `SELECT COALESCE(ROUND(SUM(saldo[1]),2),0) FROM venta.get_saldo(1, $1, 'procesado') WHERE suscripcion_id IS NOT NULL`
+	if err := db.ConnPgsql.QueryRow(db.Ctx, query, clienteId).Scan(&saldo); err != nil {
+		utils.Logline("error on select saldo", clienteId, err)
+		return nil, http.StatusBadRequest, errors.New("errorGetData")
+	}
+	if prepareReq.Monto > saldo {
+		return nil, http.StatusBadRequest, errors.New("veAmmountInsufficient")
+	}
+
+	limite, err := getTransferLimite(db.Ctx, db.ConnPgsql, clienteId, clienteDestinoId)
+	if err != nil {
+		return nil, http.StatusBadRequest, errors.New("errorGetData")
+	}
+	if err := checkTransferLimite(limite, prepareReq.Monto); err != nil {
+		return nil, http.StatusUnprocessableEntity, err
+	}
+
+	var tasaCambio float64
+	query = `SELECT publico.latest_tasa_cambio($1) as tasa_cambio`
+	if err := db.ConnPgsql.QueryRow(db.Ctx, query, 1).Scan(&tasaCambio); err != nil {
+		utils.Logline("tasa cambio no encontrada", err)
+		return nil, http.StatusBadRequest, errors.New("errorInternal")
+	}
+
+	user, err := getUser(db, "id", clienteId)
+	if err != nil {
+		return nil, http.StatusBadRequest, errors.New("errorInternal")
+	}
+
+	// where the code is sent
+	canal := prepareReq.Canal
+	if canal == "" {
+		canal = "email"
+	}
+	destino := ""
+	if canal == "sms" {
+		destino = utils.FirstCelular(user.Profile.Telefono)
+	} else if len(user.Profile.Correo) > 0 {
+		destino = user.Profile.Correo[0]
+	}
+	if destino == "" {
+		return nil, http.StatusBadRequest, errors.New("transferCanalInvalid")
+	}
+
+	prepared := models.TransferPrepared{
+		Destinatario: *recipient,
+		Descripcion:  prepareReq.Descripcion,
+		Monto:        models.Moneda{Dolar: prepareReq.Monto, Bolivar: utils.RoundToTwoDecimalPlaces(prepareReq.Monto * tasaCambio)},
+		TasaCambio:   tasaCambio,
+		Canal:        canal,
+		ExpiresAt:    time.Now().Add(transferPrepareMaxAge()),
+	}
+
+	query = `INSERT INTO publico.transfer_preparada (empresa_id, cliente_id, cliente_destino_id, destinatario_docid, descripcion, monto, tasa_cambio, canal, expires_at)
+		VALUES (1, $1, $2, $3, $4, $5, $6, $7, $8) RETURNING id::text`
+	err = db.ConnPgsql.QueryRow(db.Ctx, query, clienteId, clienteDestinoId, recipient.DocId, prepareReq.Descripcion,
+		[]float64{prepared.Monto.Dolar, prepared.Monto.Bolivar}, tasaCambio, canal, prepared.ExpiresAt).Scan(&prepared.PreparadaId)
+	if err != nil {
+		utils.Logline("error inserting transfer_preparada", clienteId, err)
+		return nil, http.StatusBadRequest, errors.New("errorInsertRecord")
+	}
+
+	// the code is tied to the prepared transfer, it can not confirm other one
+	code, err := createOtp(db, clienteId, "transfer", canal, destino, map[string]any{"preparada_id": prepared.PreparadaId})
+	if err != nil {
+		return nil, http.StatusInternalServerError, errors.New("errorInternal")
+	}
+
+	mensaje := "Para confirmar la transferencia de " + utils.FormatMoneda(prepared.Monto) + " a " + recipient.Nombre +
+		" (" + recipient.DocId + ") utiliza el siguiente codigo:"
+	if canal == "sms" {
+		message := "Besser Solutions: tu codigo para confirmar la transferencia de $ " + strconv.FormatFloat(prepared.Monto.Dolar, 'f', 2, 64) +
+			" a " + recipient.DocId + " es " + code + ". Vence en " + strconv.Itoa(int(transferPrepareMaxAge().Minutes())) + " minutos, no lo compartas."
+		if err := utils.SendSms(destino, message); err != nil {
+			utils.Logline("error sending the sms", clienteId, err)
+			return nil, http.StatusInternalServerError, errors.New("errorSms")
+		}
+		prepared.Destino = utils.MaskPhone(destino)
+	} else {
+		err = sendOtpEmail(user.Profile.Correo, ginI18n.MustGetMessage(c, "titleTransfer"), "Confirma tu transferencia", mensaje, code)
+		if err != nil {
+			utils.Logline("error sending the email", err)
+			return nil, http.StatusInternalServerError, errors.New("errorEmail")
+		}
+		prepared.Destino = utils.MaskEmail(destino)
+	}
+
+	return &prepared, http.StatusOK, nil
+}
+
+// verify the code and save the prepared transfer with the quoted tasa_cambio, the limits are checked
+// again because other transfers could be saved meanwhile. The code is consumed on the transaction of the
+// transfer, so it can be used again when the transfer fails by the limits or the saldo
+func ConfirmTransfer(db models.ConnDb, clienteId string, confirmReq models.TransferConfirmReq) (*models.TransferResponse, int, error) {
+	transfer := transferInternal{ClienteId: clienteId, PreparadaId: confirmReq.PreparadaId}
+	query := `SELECT cliente_destino_id::text, destinatario_docid, descripcion, monto[1], tasa_cambio
+		FROM publico.transfer_preparada
+		WHERE id=$1 AND cliente_id=$2 AND confirmed_at IS NULL AND expires_at>NOW()`
+	err := db.ConnPgsql.QueryRow(db.Ctx, query, confirmReq.PreparadaId, clienteId).Scan(&transfer.ClienteDestinoId,
+		&transfer.DestinatarioDocId, &transfer.Descripcion, &transfer.Monto, &transfer.TasaCambio)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, http.StatusGone, errors.New("transferPrepareExpired")
+		}
+		utils.Logline("error getting transfer_preparada", confirmReq.PreparadaId, err)
+		return nil, http.StatusBadRequest, errors.New("errorGetData")
+	}
+
+	otp, errType, err := checkOtp(db, clienteId, "transfer", confirmReq.Code)
+	if err != nil {
+		return nil, errType, err
+	}
+	if preparadaId, _ := otp.Info["preparada_id"].(string); !strings.EqualFold(preparadaId, confirmReq.PreparadaId) {
+		return nil, http.StatusUnauthorized, errors.New("otpInvalid")
+	}
+	transfer.OtpId = otp.Id
+
+	return saveTransfer(db, transfer)
+}
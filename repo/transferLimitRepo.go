@@ -0,0 +1,98 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"ired.com/micuenta/models"
+	"ired.com/micuenta/utils"
+)
+
+// pool or transaction, the limits are checked and the code consumed inside the transaction of the transfer
+type pgQuerier interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+}
+
+// limits of the segment of the cliente and what was already transferred today and this month,
+// destinoId is optional and tells if the cliente already transferred to that recipient today
+func getTransferLimite(ctx context.Context, conn pgQuerier, clienteId string, destinoId string) (*models.TransferLimite, error) {
+	query := `SELECT l.segmento, l.max_transferencia, l.max_diario, l.max_mensual, l.max_destinatarios_dia
+		FROM publico.transfer_limite l
+		LEFT JOIN publico.cliente c ON c.id=$1
+		WHERE l.segmento=COALESCE(c.info->>'segmento', 'default') OR l.segmento='default'
+		ORDER BY (l.segmento='default') LIMIT 1`
+
+	var limite models.TransferLimite
+	if err := conn.QueryRow(ctx, query, clienteId).Scan(&limite.Segmento, &limite.MaxTransferencia, &limite.MaxDiario,
+		&limite.MaxMensual, &limite.MaxDestinatarios); err != nil {
+		utils.Logline("error getting publico.transfer_limite", clienteId, err)
+		return nil, err
+	}
+
+	// the day and the month are the ones of Caracas, not the timezone of the db
+	now := utils.NowCaracas()
+	inicioDia := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	inicioMes := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+
+	query = `SELECT COALESCE(SUM(monto[1]) FILTER (WHERE created_at >= $3), 0),
+			COALESCE(SUM(monto[1]), 0),
+			COUNT(DISTINCT cliente_destino_id) FILTER (WHERE created_at >= $3),
+			COALESCE(bool_or(cliente_destino_id::text=$2) FILTER (WHERE created_at >= $3), false)
+		FROM venta.transferenciav
+		WHERE cliente_origen_id=$1 AND created_at >= $4`
+	if err := conn.QueryRow(ctx, query, clienteId, destinoId, inicioDia, inicioMes).Scan(&limite.UsadoDiario, &limite.UsadoMensual,
+		&limite.DestinatariosHoy, &limite.DestinatarioUsado); err != nil {
+		utils.Logline("error getting usage of transferenciav", clienteId, err)
+		return nil, err
+	}
+
+	return &limite, nil
+}
+
+// returns the i18n error of the first limit exceeded by the transfer
+func checkTransferLimite(limite *models.TransferLimite, monto float64) error {
+	if monto > limite.MaxTransferencia {
+		return errors.New("transferLimitAmount")
+	}
+	if limite.UsadoDiario+monto > limite.MaxDiario {
+		return errors.New("transferLimitDaily")
+	}
+	if limite.UsadoMensual+monto > limite.MaxMensual {
+		return errors.New("transferLimitMonthly")
+	}
+	if !limite.DestinatarioUsado && limite.DestinatariosHoy >= limite.MaxDestinatarios {
+		return errors.New("transferLimitRecipients")
+	}
+
+	return nil
+}
+
+func transferAllowance(limite *models.TransferLimite, saldo float64) models.TransferAllowance {
+	allowance := models.TransferAllowance{
+		Segmento:                 limite.Segmento,
+		MaxTransferencia:         limite.MaxTransferencia,
+		DisponibleDiario:         math.Max(0, math.Round((limite.MaxDiario-limite.UsadoDiario)*100)/100),
+		DisponibleMensual:        math.Max(0, math.Round((limite.MaxMensual-limite.UsadoMensual)*100)/100),
+		DestinatariosDisponibles: max(0, limite.MaxDestinatarios-limite.DestinatariosHoy),
+	}
+	allowance.MaxProximaTransferencia = math.Max(0, min(saldo, allowance.MaxTransferencia, allowance.DisponibleDiario, allowance.DisponibleMensual))
+
+	return allowance
+}
+
+// remaining allowance of the transfers of the cliente
+func TransferAllowance(db models.ConnDb, clienteId string, saldo float64) (*models.TransferAllowance, int, error) {
+	limite, err := getTransferLimite(db.Ctx, db.ConnPgsql, clienteId, "")
+	if err != nil {
+		return nil, http.StatusBadRequest, errors.New("errorGetData")
+	}
+
+	allowance := transferAllowance(limite, saldo)
+	return &allowance, http.StatusOK, nil
+}
@@ -0,0 +1,284 @@
+package repo
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/jackc/pgx/v5"
+	"golang.org/x/crypto/bcrypt"
+	"ired.com/micuenta/models"
+	"ired.com/micuenta/utils"
+)
+
+const (
+	twoFactorMaxFails     = 5
+	twoFactorLockMinutes  = 15
+	twoFactorRecoveryCant = 8
+)
+
+type twoFactorInternal struct {
+	ClienteId      string
+	Secret         string
+	Enabled        bool
+	LastUsedStep   int64
+	BloqueadoHasta *time.Time
+}
+
+// get the second factor of the cliente, nil if the cliente never enrolled
+func getTwoFactor(db models.ConnDb, clienteId string) (*twoFactorInternal, error) {
+	var twoFactor twoFactorInternal
+	query := `SELECT cliente_id::text, secret, enabled, last_used_step, bloqueado_hasta FROM publico.cliente_2fa WHERE empresa_id=1 AND cliente_id=$1`
+	err := db.ConnPgsql.QueryRow(db.Ctx, query, clienteId).Scan(&twoFactor.ClienteId, &twoFactor.Secret, &twoFactor.Enabled,
+		&twoFactor.LastUsedStep, &twoFactor.BloqueadoHasta)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		utils.Logline("error getting cliente_2fa", clienteId, err)
+		return nil, err
+	}
+
+	secret, err := utils.DecryptTotpSecret(twoFactor.Secret)
+	if err != nil {
+		utils.Logline("error decrypting totp secret", clienteId, err)
+		return nil, err
+	}
+	twoFactor.Secret = secret
+
+	return &twoFactor, nil
+}
+
+// validate a totp or recovery code, every failed code counts to lock the second factor
+func checkSecondFactor(c *gin.Context, db models.ConnDb, twoFactor *twoFactorInternal, code string) (int, error) {
+	if twoFactor.BloqueadoHasta != nil && time.Now().Before(*twoFactor.BloqueadoHasta) {
+		return http.StatusTooManyRequests, errors.New("twoFactorLocked")
+	}
+
+	var query string
+	var value any
+	if strings.Contains(code, "-") {
+		// recovery codes can be used only once
+		query = `UPDATE publico.cliente_2fa SET recovery_codes=recovery_codes - $2::text, fallos=0 WHERE cliente_id=$1 AND recovery_codes ? $2::text`
+		value = utils.HashToken(strings.ToLower(code))
+	} else {
+		// a totp code is rejected if its step was already used
+		step, ok := utils.ValidateTotp(twoFactor.Secret, code, time.Now())
+		if !ok {
+			step = -1
+		}
+		query = `UPDATE publico.cliente_2fa SET last_used_step=$2, fallos=0 WHERE cliente_id=$1 AND last_used_step<$2`
+		value = step
+	}
+
+	result, err := db.ConnPgsql.Exec(db.Ctx, query, twoFactor.ClienteId, value)
+	if err != nil {
+		utils.Logline("error updating cliente_2fa", twoFactor.ClienteId, err)
+		return http.StatusInternalServerError, errors.New("errorInternal")
+	}
+	if result.RowsAffected() == 1 {
+		return http.StatusOK, nil
+	}
+
+	query = `UPDATE publico.cliente_2fa SET 
+			fallos=CASE WHEN fallos+1>=$2 THEN 0 ELSE fallos+1 END,
+			bloqueado_hasta=CASE WHEN fallos+1>=$2 THEN NOW() + make_interval(mins => $3) ELSE bloqueado_hasta END
+		WHERE cliente_id=$1`
+	if _, err := db.ConnPgsql.Exec(db.Ctx, query, twoFactor.ClienteId, twoFactorMaxFails, twoFactorLockMinutes); err != nil {
+		utils.Logline("error updating fails of cliente_2fa", twoFactor.ClienteId, err)
+	}
+	logAuthEvent(c, db, twoFactor.ClienteId, "2fa_failed", nil)
+
+	return http.StatusUnauthorized, errors.New("twoFactorInvalid")
+}
+
+// short-lived token that only allows to finish the login on /auth/2fa/verify
+func generatePreAuthToken(userId string, remember bool, deviceInfo models.UserDeviceInfo) (*models.TwoFactorChallenge, error) {
+	preAuthMaxAge, err := strconv.Atoi(os.Getenv("PREAUTH_MAX_AGE"))
+	if err != nil {
+		utils.Logline("error parsing PREAUTH_MAX_AGE", err)
+		return nil, err
+	}
+	expirationTime := time.Now().Add(time.Duration(preAuthMaxAge) * time.Second)
+
+	claims := &models.PreAuthClaims{
+		UserId:     userId,
+		Remember:   remember,
+		DeviceInfo: deviceInfo,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    "micuenta",
+			Subject:   userId,
+			Audience:  jwt.ClaimStrings{"2fa"},
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(expirationTime),
+		},
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	tokenString, err := token.SignedString([]byte(os.Getenv("PREAUTH_SECRET")))
+	if err != nil {
+		utils.Logline("error signing pre-auth token", err)
+		return nil, err
+	}
+
+	return &models.TwoFactorChallenge{
+		PreAuthToken: tokenString,
+		ExpiresAt:    expirationTime.Format(time.RFC3339),
+		Methods:      []string{"totp", "recovery_code"},
+	}, nil
+}
+
+func TwoFactorEnroll(c *gin.Context, db models.ConnDb) (*models.TwoFactorEnrollResponse, int, error) {
+	userId, _ := c.Get("userId")
+
+	twoFactor, err := getTwoFactor(db, userId.(string))
+	if err != nil {
+		return nil, http.StatusInternalServerError, errors.New("errorInternal")
+	}
+	if twoFactor != nil && twoFactor.Enabled {
+		return nil, http.StatusBadRequest, errors.New("twoFactorEnabled")
+	}
+
+	user, err := getUser(db, "id", userId.(string))
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+
+	secret, err := utils.GenerateTotpSecret()
+	if err != nil {
+		utils.Logline("error generating totp secret", err)
+		return nil, http.StatusInternalServerError, errors.New("errorInternal")
+	}
+	secretEncrypted, err := utils.EncryptTotpSecret(secret)
+	if err != nil {
+		utils.Logline("error encrypting totp secret", err)
+		return nil, http.StatusInternalServerError, errors.New("errorInternal")
+	}
+
+	recoveryCodes, err := utils.GenerateRecoveryCodes(twoFactorRecoveryCant)
+	if err != nil {
+		utils.Logline("error generating recovery codes", err)
+		return nil, http.StatusInternalServerError, errors.New("errorInternal")
+	}
+	recoveryHashes := make([]string, len(recoveryCodes))
+	for i, code := range recoveryCodes {
+		recoveryHashes[i] = utils.HashToken(code)
+	}
+
+	// the enrollment is pending until is activated with a valid code
+	query := `INSERT INTO publico.cliente_2fa (cliente_id, empresa_id, secret, enabled, recovery_codes)
+		VALUES ($1, 1, $2, false, $3)
+		ON CONFLICT (cliente_id) DO UPDATE SET secret=EXCLUDED.secret, enabled=false, recovery_codes=EXCLUDED.recovery_codes,
+			last_used_step=0, fallos=0, bloqueado_hasta=NULL, created_at=NOW(), enabled_at=NULL`
+	if _, err := db.ConnPgsql.Exec(db.Ctx, query, userId, secretEncrypted, recoveryHashes); err != nil {
+		utils.Logline("error inserting cliente_2fa", userId, err)
+		return nil, http.StatusInternalServerError, errors.New("errorInsertRecord")
+	}
+
+	issuer := os.Getenv("TOTP_ISSUER")
+	return &models.TwoFactorEnrollResponse{
+		Secret:        secret,
+		OtpauthUri:    utils.TotpUri(issuer, user.Profile.Username, secret),
+		RecoveryCodes: recoveryCodes,
+	}, http.StatusOK, nil
+}
+
+func TwoFactorActivate(c *gin.Context, db models.ConnDb, userReq models.TwoFactorCodeReq) (int, error) {
+	userId, _ := c.Get("userId")
+
+	twoFactor, err := getTwoFactor(db, userId.(string))
+	if err != nil {
+		return http.StatusInternalServerError, errors.New("errorInternal")
+	}
+	if twoFactor == nil {
+		return http.StatusBadRequest, errors.New("twoFactorNotEnrolled")
+	}
+	if twoFactor.Enabled {
+		return http.StatusBadRequest, errors.New("twoFactorEnabled")
+	}
+
+	if errType, err := checkSecondFactor(c, db, twoFactor, userReq.Code); err != nil {
+		return errType, err
+	}
+
+	query := `UPDATE publico.cliente_2fa SET enabled=true, enabled_at=NOW() WHERE cliente_id=$1`
+	if _, err := db.ConnPgsql.Exec(db.Ctx, query, userId); err != nil {
+		utils.Logline("error enabling cliente_2fa", userId, err)
+		return http.StatusInternalServerError, errors.New("errorUpdateRecord")
+	}
+	logAuthEvent(c, db, userId, "2fa_enabled", nil)
+
+	return http.StatusOK, nil
+}
+
+func TwoFactorDisable(c *gin.Context, db models.ConnDb, userReq models.TwoFactorDisableReq) (int, error) {
+	userId, _ := c.Get("userId")
+
+	user, err := getUser(db, "id", userId.(string))
+	if err != nil {
+		return http.StatusBadRequest, err
+	}
+	if !user.Credentials.Passwd.Valid ||
+		bcrypt.CompareHashAndPassword([]byte(user.Credentials.Passwd.String), []byte(userReq.Password)) != nil {
+		return http.StatusUnauthorized, errors.New("invalidLogin")
+	}
+
+	twoFactor, err := getTwoFactor(db, userId.(string))
+	if err != nil {
+		return http.StatusInternalServerError, errors.New("errorInternal")
+	}
+	if twoFactor == nil || !twoFactor.Enabled {
+		return http.StatusBadRequest, errors.New("twoFactorNotEnrolled")
+	}
+
+	if errType, err := checkSecondFactor(c, db, twoFactor, userReq.Code); err != nil {
+		return errType, err
+	}
+
+	query := `DELETE FROM publico.cliente_2fa WHERE cliente_id=$1`
+	if _, err := db.ConnPgsql.Exec(db.Ctx, query, userId); err != nil {
+		utils.Logline("error deleting cliente_2fa", userId, err)
+		return http.StatusInternalServerError, errors.New("errorDeleteRecord")
+	}
+	logAuthEvent(c, db, userId, "2fa_disabled", nil)
+
+	return http.StatusOK, nil
+}
+
+// second step of the login, validate the pre-auth token and the code and then start the session
+func TwoFactorVerify(c *gin.Context, db models.ConnDb, userReq models.TwoFactorVerifyReq) (*models.UserResponse, int, error) {
+	claims := &models.PreAuthClaims{}
+	token, err := jwt.ParseWithClaims(userReq.PreAuthToken, claims, func(token *jwt.Token) (interface{}, error) {
+		return []byte(os.Getenv("PREAUTH_SECRET")), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithAudience("2fa"), jwt.WithIssuer("micuenta"))
+	if err != nil || !token.Valid || claims.Subject != claims.UserId {
+		return nil, http.StatusUnauthorized, errors.New("tokenInvalid")
+	}
+
+	user, err := getUser(db, "id", claims.UserId)
+	if err != nil {
+		return nil, http.StatusUnauthorized, errors.New("tokenInvalid")
+	}
+
+	twoFactor, err := getTwoFactor(db, user.Profile.Id)
+	if err != nil {
+		return nil, http.StatusInternalServerError, errors.New("errorInternal")
+	}
+	if twoFactor == nil || !twoFactor.Enabled {
+		return nil, http.StatusUnauthorized, errors.New("tokenInvalid")
+	}
+
+	if errType, err := checkSecondFactor(c, db, twoFactor, userReq.Code); err != nil {
+		return nil, errType, err
+	}
+
+	if err := startSession(c, db, user, claims.Remember, &claims.DeviceInfo); err != nil {
+		return nil, http.StatusInternalServerError, errors.New("errorInternal")
+	}
+
+	return user, http.StatusOK, nil
+}
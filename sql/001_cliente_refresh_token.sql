@@ -0,0 +1,29 @@
+-- refresh token rotation families, every login creates a family and every
+-- refresh retires the token presented and adds a new one to the same family
+CREATE TABLE IF NOT EXISTS publico.cliente_refresh_token (
+	id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+	empresa_id INTEGER NOT NULL DEFAULT 1,
+	cliente_id BIGINT NOT NULL,
+	family_id UUID NOT NULL,
+	session_key VARCHAR(100) NOT NULL,
+	token_hash VARCHAR(64) NOT NULL UNIQUE,
+	estatus VARCHAR(20) NOT NULL DEFAULT 'activo', -- activo | retirado | revocado
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	retired_at TIMESTAMPTZ,
+	expires_at TIMESTAMPTZ NOT NULL
+);
+CREATE INDEX IF NOT EXISTS cliente_refresh_token_family_idx ON publico.cliente_refresh_token (family_id);
+CREATE INDEX IF NOT EXISTS cliente_refresh_token_session_idx ON publico.cliente_refresh_token (session_key);
+
+-- security related events of the clientes (token reuse, lockouts, etc)
+CREATE TABLE IF NOT EXISTS publico.cliente_auth_event (
+	id BIGSERIAL PRIMARY KEY,
+	empresa_id INTEGER NOT NULL DEFAULT 1,
+	cliente_id BIGINT,
+	evento VARCHAR(50) NOT NULL,
+	ip VARCHAR(60),
+	user_agent TEXT,
+	info JSONB NOT NULL DEFAULT '{}',
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS cliente_auth_event_cliente_idx ON publico.cliente_auth_event (cliente_id, created_at);
@@ -0,0 +1,14 @@
+-- second factor (TOTP) of the clientes, secret is encrypted with TOTP_SECRET_KEY
+-- and recovery codes are saved as sha256 hashes
+CREATE TABLE IF NOT EXISTS publico.cliente_2fa (
+	cliente_id BIGINT PRIMARY KEY,
+	empresa_id INTEGER NOT NULL DEFAULT 1,
+	secret TEXT NOT NULL,
+	enabled BOOLEAN NOT NULL DEFAULT false,
+	recovery_codes JSONB NOT NULL DEFAULT '[]',
+	last_used_step BIGINT NOT NULL DEFAULT 0,
+	fallos INTEGER NOT NULL DEFAULT 0,
+	bloqueado_hasta TIMESTAMPTZ,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	enabled_at TIMESTAMPTZ
+);
@@ -0,0 +1,10 @@
+-- failed login counters shared by all the instances of the app, tipo is
+-- docid or ip and llave is the value of it
+CREATE TABLE IF NOT EXISTS publico.auth_login_attempt (
+	tipo VARCHAR(10) NOT NULL,
+	llave VARCHAR(100) NOT NULL,
+	fallos INTEGER NOT NULL DEFAULT 0,
+	ultimo_fallo TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	bloqueado_hasta TIMESTAMPTZ,
+	PRIMARY KEY (tipo, llave)
+);
@@ -0,0 +1,17 @@
+-- one-time codes sent to the clientes (activation, recovery, contact verification, etc)
+-- only the sha256 of the code is saved
+CREATE TABLE IF NOT EXISTS publico.cliente_otp (
+	id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+	empresa_id INTEGER NOT NULL DEFAULT 1,
+	cliente_id BIGINT NOT NULL,
+	proposito VARCHAR(30) NOT NULL,
+	canal VARCHAR(10) NOT NULL, -- email | sms
+	destino VARCHAR(150) NOT NULL,
+	code_hash VARCHAR(64) NOT NULL,
+	intentos INTEGER NOT NULL DEFAULT 0,
+	info JSONB NOT NULL DEFAULT '{}',
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	expires_at TIMESTAMPTZ NOT NULL,
+	used_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS cliente_otp_cliente_idx ON publico.cliente_otp (cliente_id, proposito);
@@ -0,0 +1,14 @@
+-- access tokens revoked before their expiration, a row with jti revokes one token
+-- and a row without jti revokes all the tokens of the cliente issued before issued_before
+CREATE TABLE IF NOT EXISTS publico.cliente_token_revocado (
+	id BIGSERIAL PRIMARY KEY,
+	empresa_id INTEGER NOT NULL DEFAULT 1,
+	cliente_id BIGINT NOT NULL,
+	jti VARCHAR(64),
+	issued_before TIMESTAMPTZ,
+	motivo VARCHAR(30) NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	expires_at TIMESTAMPTZ NOT NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS cliente_token_revocado_jti_idx ON publico.cliente_token_revocado (jti) WHERE jti IS NOT NULL;
+CREATE INDEX IF NOT EXISTS cliente_token_revocado_cliente_idx ON publico.cliente_token_revocado (cliente_id) WHERE jti IS NULL;
@@ -0,0 +1,25 @@
+-- password reset tokens, only the sha256 of the token is saved and it can be used once
+CREATE TABLE IF NOT EXISTS publico.cliente_reset_token (
+	id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+	empresa_id INTEGER NOT NULL DEFAULT 1,
+	cliente_id BIGINT NOT NULL,
+	token_hash VARCHAR(64) NOT NULL UNIQUE,
+	canal VARCHAR(10) NOT NULL DEFAULT 'email', -- email | sms
+	ip VARCHAR(60),
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	expires_at TIMESTAMPTZ NOT NULL,
+	used_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS cliente_reset_token_cliente_idx ON publico.cliente_reset_token (cliente_id);
+
+-- generic counters to throttle requests, accion is the endpoint and llave the account or ip
+CREATE TABLE IF NOT EXISTS publico.auth_rate_limit (
+	accion VARCHAR(30) NOT NULL,
+	llave VARCHAR(100) NOT NULL,
+	ventana_inicio TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	contador INTEGER NOT NULL DEFAULT 0,
+	PRIMARY KEY (accion, llave)
+);
+
+-- tokens saved in plain text by the previous version
+UPDATE publico.cliente SET info=info - 'link_email_token' WHERE info ? 'link_email_token';
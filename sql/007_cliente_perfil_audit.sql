@@ -0,0 +1,16 @@
+-- changes made by the clientes to their profile, estatus is aplicado for the
+-- changes saved directly, pendiente while the contact change waits for the code
+-- and verificado when the code was validated and the change saved
+CREATE TABLE IF NOT EXISTS publico.cliente_perfil_audit (
+	id BIGSERIAL PRIMARY KEY,
+	empresa_id INTEGER NOT NULL DEFAULT 1,
+	cliente_id BIGINT NOT NULL,
+	campo VARCHAR(20) NOT NULL, -- direccion | correo | telefono
+	valor_anterior JSONB,
+	valor_nuevo JSONB,
+	estatus VARCHAR(15) NOT NULL,
+	ip VARCHAR(45),
+	user_agent TEXT,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS cliente_perfil_audit_cliente_idx ON publico.cliente_perfil_audit (cliente_id, created_at);
@@ -0,0 +1,14 @@
+-- idempotency keys sent by the clientes on the payment and transfer requests,
+-- the response is saved to replay it on the retries with the same key
+CREATE TABLE IF NOT EXISTS publico.idempotency_key (
+	cliente_id BIGINT NOT NULL,
+	llave VARCHAR(100) NOT NULL,
+	ruta VARCHAR(100) NOT NULL,
+	fingerprint VARCHAR(64) NOT NULL,
+	estatus VARCHAR(15) NOT NULL DEFAULT 'procesando', -- procesando | completado
+	response_status INTEGER,
+	response_body TEXT,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	PRIMARY KEY (cliente_id, llave)
+);
+CREATE INDEX IF NOT EXISTS idempotency_key_created_idx ON publico.idempotency_key (created_at);
@@ -0,0 +1,39 @@
+-- bank statements imported to reconcile the payments reported by the clientes,
+-- hash is the sha256 of the file to not import the same file twice
+CREATE TABLE IF NOT EXISTS publico.banco_extracto (
+	id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+	empresa_id INTEGER NOT NULL DEFAULT 1,
+	cuenta_banco_id BIGINT NOT NULL,
+	layout VARCHAR(50) NOT NULL,
+	archivo VARCHAR(255) NOT NULL,
+	hash VARCHAR(64) NOT NULL UNIQUE,
+	origen VARCHAR(20) NOT NULL, -- cronJob | restApi
+	resumen JSONB NOT NULL DEFAULT '{}',
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- credit lines of the statements by number of line (two equal movements are two lines), the lines of overlapping statements
+-- are imported once. estatus is conciliado when it matched a recibo_pagov (the recibo stays pendiente for the back office
+-- with info->'conciliacion'), revision when back office must check the candidatos
+-- and sin_match when no pending payment looks like it
+CREATE TABLE IF NOT EXISTS publico.banco_movimiento (
+	id BIGSERIAL PRIMARY KEY,
+	empresa_id INTEGER NOT NULL DEFAULT 1,
+	extracto_id UUID NOT NULL REFERENCES publico.banco_extracto (id),
+	cuenta_banco_id BIGINT NOT NULL,
+	linea INTEGER NOT NULL,
+	fecha DATE NOT NULL,
+	referencia VARCHAR(100) NOT NULL,
+	descripcion TEXT NOT NULL DEFAULT '',
+	monto NUMERIC(20,4) NOT NULL,
+	estatus VARCHAR(15) NOT NULL,
+	recibo_pagov_id UUID,
+	recibo_pagov_created_at TIMESTAMPTZ,
+	candidatos JSONB NOT NULL DEFAULT '[]',
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+-- the first version of the table merged the equal movements of the cuenta
+ALTER TABLE publico.banco_movimiento DROP CONSTRAINT IF EXISTS banco_movimiento_cuenta_banco_id_fecha_referencia_monto_key;
+CREATE UNIQUE INDEX IF NOT EXISTS banco_movimiento_linea_idx ON publico.banco_movimiento (extracto_id, linea);
+CREATE INDEX IF NOT EXISTS banco_movimiento_estatus_idx ON publico.banco_movimiento (estatus, cuenta_banco_id);
+CREATE INDEX IF NOT EXISTS banco_movimiento_cuenta_fecha_idx ON publico.banco_movimiento (cuenta_banco_id, fecha, referencia);
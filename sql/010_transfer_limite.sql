@@ -0,0 +1,17 @@
+-- limits of the transfers of saldo by segment of cliente, the amounts are in dolares.
+-- The segment of the cliente is publico.cliente.info->>'segmento', without it (or
+-- with a segment that does not exist) the limits of the default segment are used
+CREATE TABLE IF NOT EXISTS publico.transfer_limite (
+	segmento VARCHAR(30) PRIMARY KEY,
+	max_transferencia NUMERIC(20,2) NOT NULL,
+	max_diario NUMERIC(20,2) NOT NULL,
+	max_mensual NUMERIC(20,2) NOT NULL,
+	max_destinatarios_dia INTEGER NOT NULL,
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO publico.transfer_limite (segmento, max_transferencia, max_diario, max_mensual, max_destinatarios_dia)
+VALUES ('default', 100, 300, 1000, 3), ('empresa', 1000, 3000, 20000, 10)
+ON CONFLICT (segmento) DO NOTHING;
+
+CREATE INDEX IF NOT EXISTS transferenciav_origen_created_idx ON venta.transferenciav (cliente_origen_id, created_at);
@@ -0,0 +1,18 @@
+-- transfers prepared by the clientes waiting for the confirmation with the code sent by email/sms,
+-- monto and tasa_cambio are the quote shown to the cliente and used when the transfer is confirmed
+CREATE TABLE IF NOT EXISTS publico.transfer_preparada (
+	id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+	empresa_id INTEGER NOT NULL DEFAULT 1,
+	cliente_id BIGINT NOT NULL,
+	cliente_destino_id BIGINT NOT NULL,
+	destinatario_docid VARCHAR(20) NOT NULL,
+	descripcion VARCHAR(200) NOT NULL,
+	monto NUMERIC[] NOT NULL, -- [dolar, bolivar]
+	tasa_cambio NUMERIC NOT NULL,
+	canal VARCHAR(10) NOT NULL, -- email | sms
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	expires_at TIMESTAMPTZ NOT NULL,
+	confirmed_at TIMESTAMPTZ,
+	transferencia_id UUID
+);
+CREATE INDEX IF NOT EXISTS transfer_preparada_cliente_idx ON publico.transfer_preparada (cliente_id, created_at);
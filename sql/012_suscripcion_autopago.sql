@@ -0,0 +1,13 @@
+-- suscripciones with auto-pay, on the renewal day the saldo available of the cliente is used to pay the open
+-- factura of the suscripcion (task autopay_suscripciones), ultimo_periodo (YYYY-MM) is the last month fully paid
+CREATE TABLE IF NOT EXISTS publico.suscripcion_autopago (
+	suscripcion_id BIGINT PRIMARY KEY,
+	empresa_id INTEGER NOT NULL DEFAULT 1,
+	cliente_id BIGINT NOT NULL,
+	activo BOOLEAN NOT NULL DEFAULT true,
+	ultimo_periodo VARCHAR(7),
+	ultimo_pago_at TIMESTAMPTZ,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS suscripcion_autopago_cliente_idx ON publico.suscripcion_autopago (cliente_id);
@@ -0,0 +1,157 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"ired.com/micuenta/models"
+)
+
+// read the layouts of the bank statements from BANK_LAYOUTS_FILE, checkout bank_layouts_example.json
+func LoadBankLayouts() ([]models.BancoLayout, error) {
+	fileName := os.Getenv("BANK_LAYOUTS_FILE")
+	if fileName == "" {
+		fileName = ".bank_layouts"
+	}
+
+	content, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	var layouts []models.BancoLayout
+	if err := json.Unmarshal(content, &layouts); err != nil {
+		return nil, err
+	}
+
+	return layouts, nil
+}
+
+func FindBankLayout(nombre string) (*models.BancoLayout, error) {
+	layouts, err := LoadBankLayouts()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, layout := range layouts {
+		if strings.EqualFold(layout.Nombre, nombre) {
+			return &layout, nil
+		}
+	}
+
+	return nil, fmt.Errorf("bank layout %s not found", nombre)
+}
+
+// read the credit lines of a csv statement, the debits (monto <= 0) are discarded
+func ParseBankStatement(content []byte, layout models.BancoLayout) ([]models.BancoMovimiento, error) {
+	reader := csv.NewReader(bytes.NewReader(content))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	if layout.Delimiter != "" {
+		reader.Comma = []rune(layout.Delimiter)[0]
+	}
+
+	linea := 0
+	readRow := func() ([]string, error) {
+		linea++
+		return reader.Read()
+	}
+
+	for range layout.SkipRows {
+		if _, err := readRow(); err != nil {
+			return nil, err
+		}
+	}
+
+	// the generic layouts find the index of the fields on the header row
+	columns := layout.Columns
+	if len(layout.Headers) > 0 {
+		header, err := readRow()
+		if err != nil {
+			return nil, err
+		}
+		columns = map[string]int{}
+		for field, name := range layout.Headers {
+			for i, column := range header {
+				if strings.EqualFold(strings.TrimSpace(column), name) {
+					columns[field] = i
+				}
+			}
+		}
+	}
+	for _, field := range []string{"fecha", "referencia", "monto"} {
+		if _, ok := columns[field]; !ok {
+			return nil, fmt.Errorf("column %s is not defined on the bank layout %s", field, layout.Nombre)
+		}
+	}
+
+	var movimientos []models.BancoMovimiento
+	for {
+		row, err := readRow()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		column := func(field string) string {
+			i, ok := columns[field]
+			if !ok || i >= len(row) {
+				return ""
+			}
+			return strings.TrimSpace(row[i])
+		}
+
+		if column("fecha") == "" && column("monto") == "" {
+			continue
+		}
+
+		fecha, err := time.Parse(layout.DateFormat, column("fecha"))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid fecha %s", linea, column("fecha"))
+		}
+		monto, err := parseBankAmount(column("monto"), layout.DecimalSeparator)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid monto %s", linea, column("monto"))
+		}
+		if monto <= 0 {
+			continue
+		}
+
+		movimientos = append(movimientos, models.BancoMovimiento{
+			Linea:       linea,
+			Fecha:       fecha.Format("2006-01-02"),
+			Referencia:  column("referencia"),
+			Descripcion: column("descripcion"),
+			Monto:       RoundToTwoDecimalPlaces(monto),
+		})
+	}
+
+	return movimientos, nil
+}
+
+// amounts like 1.234,56 or 1,234.56 depending of the decimal separator of the bank
+func parseBankAmount(value string, decimalSeparator string) (float64, error) {
+	value = strings.NewReplacer(" ", "", "$", "", "Bs", "").Replace(value)
+	if decimalSeparator == "," {
+		value = strings.ReplaceAll(value, ".", "")
+		value = strings.ReplaceAll(value, ",", ".")
+	} else {
+		value = strings.ReplaceAll(value, ",", "")
+	}
+	return strconv.ParseFloat(value, 64)
+}
+
+// digits of a referencia, the banks add zeros or prefixes to the number reported by the cliente
+func NormalizeReferencia(referencia string) string {
+	return strings.TrimLeft(ExtractNumbers(referencia), "0")
+}
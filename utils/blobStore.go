@@ -0,0 +1,228 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+var ErrBlobNotFound = errors.New("blob not found")
+
+// storage of the files uploaded by the clientes, selected with BLOB_STORE
+type BlobStore interface {
+	Put(ctx context.Context, key string, content []byte, contentType string) error
+	Get(ctx context.Context, key string) ([]byte, string, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// files on local disk under BLOB_LOCAL_FOLDER, the content type is taken from the extension
+type localBlobStore struct {
+	root string
+}
+
+func (s *localBlobStore) path(key string) (string, error) {
+	path := filepath.Join(s.root, filepath.FromSlash(key))
+	if !strings.HasPrefix(path, filepath.Clean(s.root)+string(os.PathSeparator)) {
+		return "", fmt.Errorf("invalid blob key %s", key)
+	}
+	return path, nil
+}
+
+func (s *localBlobStore) Put(ctx context.Context, key string, content []byte, contentType string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, content, 0644)
+}
+
+func (s *localBlobStore) Get(ctx context.Context, key string) ([]byte, string, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, "", err
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, "", ErrBlobNotFound
+		}
+		return nil, "", err
+	}
+	return content, http.DetectContentType(content), nil
+}
+
+func (s *localBlobStore) Delete(ctx context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// s3 compatible object store (aws, minio, etc), the requests are signed with aws signature v4
+// and the bucket is addressed by path (endpoint/bucket/key)
+type s3BlobStore struct {
+	endpoint  string
+	bucket    string
+	region    string
+	accessKey string
+	secretKey string
+	client    *http.Client
+}
+
+func (s *s3BlobStore) do(ctx context.Context, method string, key string, content []byte, contentType string) (*http.Response, error) {
+	objectUrl, err := url.Parse(strings.TrimRight(s.endpoint, "/") + "/" + s.bucket + "/" + key)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, objectUrl.String(), bytes.NewReader(content))
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	s.sign(req, content, time.Now().UTC())
+
+	return s.client.Do(req)
+}
+
+func (s *s3BlobStore) sign(req *http.Request, content []byte, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	payloadHash := sha256Hex(content)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	signedHeaders := "host;x-amz-content-sha256;x-amz-date"
+	canonicalHeaders := "host:" + req.URL.Host + "\n" + "x-amz-content-sha256:" + payloadHash + "\n" + "x-amz-date:" + amzDate + "\n"
+	canonicalRequest := strings.Join([]string{req.Method, req.URL.EscapedPath(), req.URL.RawQuery, canonicalHeaders, signedHeaders, payloadHash}, "\n")
+
+	scope := date + "/" + s.region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+	signingKey := hmacSha256([]byte("AWS4"+s.secretKey), date)
+	signingKey = hmacSha256(signingKey, s.region)
+	signingKey = hmacSha256(signingKey, "s3")
+	signingKey = hmacSha256(signingKey, "aws4_request")
+	signature := hex.EncodeToString(hmacSha256(signingKey, stringToSign))
+
+	req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+s.accessKey+"/"+scope+", SignedHeaders="+signedHeaders+", Signature="+signature)
+}
+
+func (s *s3BlobStore) Put(ctx context.Context, key string, content []byte, contentType string) error {
+	resp, err := s.do(ctx, http.MethodPut, key, content, contentType)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("object store responded with status %d on put", resp.StatusCode)
+	}
+	return nil
+}
+
+func (s *s3BlobStore) Get(ctx context.Context, key string) ([]byte, string, error) {
+	resp, err := s.do(ctx, http.MethodGet, key, nil, "")
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, "", ErrBlobNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("object store responded with status %d on get", resp.StatusCode)
+	}
+
+	content, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", err
+	}
+	return content, resp.Header.Get("Content-Type"), nil
+}
+
+func (s *s3BlobStore) Delete(ctx context.Context, key string) error {
+	resp, err := s.do(ctx, http.MethodDelete, key, nil, "")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
+		return fmt.Errorf("object store responded with status %d on delete", resp.StatusCode)
+	}
+	return nil
+}
+
+func sha256Hex(content []byte) string {
+	hash := sha256.Sum256(content)
+	return hex.EncodeToString(hash[:])
+}
+
+func hmacSha256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+var (
+	blobStore     BlobStore
+	blobStoreOnce sync.Once
+	blobStoreErr  error
+)
+
+func GetBlobStore() (BlobStore, error) {
+	blobStoreOnce.Do(func() {
+		switch os.Getenv("BLOB_STORE") {
+		case "s3":
+			if os.Getenv("S3_ENDPOINT") == "" || os.Getenv("S3_BUCKET") == "" {
+				blobStoreErr = errors.New("S3_ENDPOINT and S3_BUCKET are required for the s3 blob store")
+				return
+			}
+			region := os.Getenv("S3_REGION")
+			if region == "" {
+				region = "us-east-1"
+			}
+			blobStore = &s3BlobStore{
+				endpoint:  os.Getenv("S3_ENDPOINT"),
+				bucket:    os.Getenv("S3_BUCKET"),
+				region:    region,
+				accessKey: os.Getenv("S3_ACCESS_KEY"),
+				secretKey: os.Getenv("S3_SECRET_KEY"),
+				client:    &http.Client{Timeout: 30 * time.Second},
+			}
+		case "", "local":
+			root := os.Getenv("BLOB_LOCAL_FOLDER")
+			if root == "" {
+				root = "./storage"
+			}
+			blobStore = &localBlobStore{root: filepath.Clean(root)}
+		default:
+			blobStoreErr = fmt.Errorf("BLOB_STORE %s not supported", os.Getenv("BLOB_STORE"))
+		}
+	})
+	return blobStore, blobStoreErr
+}
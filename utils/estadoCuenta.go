@@ -0,0 +1,111 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+
+	"ired.com/micuenta/models"
+)
+
+var estadoCuentaTipos = map[string]string{
+	"factura":                "Factura",
+	"pago":                   "Pago",
+	"pago_sin_asignar":       "Pago sin asignar",
+	"retencion":              "Retencion",
+	"transferencia_enviada":  "Transf. enviada",
+	"transferencia_recibida": "Transf. recibida",
+}
+
+func estadoCuentaSuscripcion(suscripcion models.EstadoCuentaSuscripcion) string {
+	if suscripcion.SuscripcionId == 0 {
+		return "Sin suscripcion"
+	}
+	if suscripcion.Ncontrol == "" {
+		return fmt.Sprintf("Suscripcion %d", suscripcion.SuscripcionId)
+	}
+	return "Suscripcion " + suscripcion.Ncontrol
+}
+
+// one row by movement, the opening balance is the first row of each suscripcion
+func EstadoCuentaCsv(estado models.EstadoCuenta) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	writer.Write([]string{"suscripcion", "fecha", "tipo", "ncontrol", "documento",
+		"debe_dolar", "haber_dolar", "saldo_dolar", "debe_bolivar", "haber_bolivar", "saldo_bolivar"})
+
+	amount := func(value float64) string {
+		return fmt.Sprintf("%.2f", value)
+	}
+	for _, suscripcion := range estado.Suscripciones {
+		writer.Write([]string{suscripcion.Ncontrol, estado.Desde, "saldo_inicial", "", "",
+			"", "", amount(suscripcion.SaldoInicial.Dolar), "", "", amount(suscripcion.SaldoInicial.Bolivar)})
+		for _, mov := range suscripcion.Movimientos {
+			writer.Write([]string{suscripcion.Ncontrol, mov.Fecha, mov.Tipo, mov.Ncontrol, mov.Documento,
+				amount(mov.Debe.Dolar), amount(mov.Haber.Dolar), amount(mov.Saldo.Dolar),
+				amount(mov.Debe.Bolivar), amount(mov.Haber.Bolivar), amount(mov.Saldo.Bolivar)})
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func GenerateEstadoCuentaPdf(estado models.EstadoCuenta) ([]byte, error) {
+	pdf := newBesserPdf("Estado de cuenta "+estado.DocId, estado.DatosBesser)
+
+	pdf.SetY(45)
+	pdf.SetFont("Tomorrow", "", 16)
+	pdf.CellFormat(0, 8, "ESTADO DE CUENTA", "", 1, "L", false, 0, "")
+	pdf.SetFont("Tomorrow", "", 9)
+	pdf.CellFormat(120, 5, estado.Nombre, "", 0, "L", false, 0, "")
+	pdf.CellFormat(0, 5, "Desde "+estado.Desde+" hasta "+estado.Hasta, "", 1, "R", false, 0, "")
+	pdf.CellFormat(0, 5, "RIF/CI: "+estado.DocId, "B", 1, "L", false, 0, "")
+	pdf.Ln(3)
+
+	widths := []float64{20, 26, 22, 28, 18, 18, 20, 34}
+	headers := []string{"Fecha", "Tipo", "Nro.", "Documento", "Debe $", "Haber $", "Saldo $", "Saldo Bs."}
+	for _, suscripcion := range estado.Suscripciones {
+		pdf.SetFont("Tomorrow", "", 10)
+		pdf.CellFormat(0, 7, estadoCuentaSuscripcion(suscripcion), "", 1, "L", false, 0, "")
+
+		pdf.SetFont("Tomorrow", "", 7)
+		pdf.SetFillColor(235, 235, 235)
+		for i, header := range headers {
+			align := "L"
+			if i >= 4 {
+				align = "R"
+			}
+			pdf.CellFormat(widths[i], 6, header, "", 0, align, true, 0, "")
+		}
+		pdf.Ln(-1)
+
+		pdf.CellFormat(widths[0], 5, estado.Desde, "B", 0, "L", false, 0, "")
+		pdf.CellFormat(widths[1]+widths[2]+widths[3]+widths[4]+widths[5], 5, "Saldo inicial", "B", 0, "L", false, 0, "")
+		pdf.CellFormat(widths[6], 5, fmt.Sprintf("%.2f", suscripcion.SaldoInicial.Dolar), "B", 0, "R", false, 0, "")
+		pdf.CellFormat(widths[7], 5, fmt.Sprintf("%.2f", suscripcion.SaldoInicial.Bolivar), "B", 1, "R", false, 0, "")
+		for _, mov := range suscripcion.Movimientos {
+			pdf.CellFormat(widths[0], 5, mov.Fecha, "B", 0, "L", false, 0, "")
+			pdf.CellFormat(widths[1], 5, estadoCuentaTipos[mov.Tipo], "B", 0, "L", false, 0, "")
+			pdf.CellFormat(widths[2], 5, mov.Ncontrol, "B", 0, "L", false, 0, "")
+			pdf.CellFormat(widths[3], 5, mov.Documento, "B", 0, "L", false, 0, "")
+			pdf.CellFormat(widths[4], 5, fmt.Sprintf("%.2f", mov.Debe.Dolar), "B", 0, "R", false, 0, "")
+			pdf.CellFormat(widths[5], 5, fmt.Sprintf("%.2f", mov.Haber.Dolar), "B", 0, "R", false, 0, "")
+			pdf.CellFormat(widths[6], 5, fmt.Sprintf("%.2f", mov.Saldo.Dolar), "B", 0, "R", false, 0, "")
+			pdf.CellFormat(widths[7], 5, fmt.Sprintf("%.2f", mov.Saldo.Bolivar), "B", 1, "R", false, 0, "")
+		}
+
+		pdf.SetFont("Tomorrow", "", 9)
+		pdf.CellFormat(0, 7, "Saldo final: "+FormatMoneda(suscripcion.SaldoFinal), "", 1, "R", false, 0, "")
+		pdf.Ln(3)
+	}
+
+	pdf.SetFont("Tomorrow", "", 7)
+	pdf.MultiCell(0, 4, "Saldo positivo a favor del cliente, negativo pendiente por pagar. Solo se incluyen los pagos procesados.", "", "L", false)
+
+	return outputPdf(pdf)
+}
@@ -0,0 +1,217 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-pdf/fpdf"
+	"ired.com/micuenta/models"
+)
+
+// render the factura, the fiscal ones (fiscal_maquina, fiscal_talonario) show the
+// fiscal numbers and the tax breakdown, the pre-facturas (nota) only the amounts
+func GenerateFacturaPdf(factura models.FacturaResponse) ([]byte, error) {
+	pdf := newBesserPdf("Factura "+factura.NumReferencia, factura.DatosBesser)
+
+	if factura.TipoFactura == "nota" {
+		facturaNotaHeader(pdf, factura)
+	} else {
+		facturaFiscalHeader(pdf, factura)
+	}
+
+	facturaCliente(pdf, factura)
+	facturaDetalle(pdf, factura)
+
+	if factura.TipoFactura == "nota" {
+		facturaNotaTotales(pdf, factura)
+	} else {
+		facturaFiscalTotales(pdf, factura)
+	}
+
+	facturaRetencionesPagos(pdf, factura)
+
+	return outputPdf(pdf)
+}
+
+func facturaFiscalHeader(pdf *fpdf.Fpdf, factura models.FacturaResponse) {
+	pdf.SetY(45)
+	pdf.SetFont("Tomorrow", "", 16)
+	pdf.CellFormat(100, 8, "FACTURA", "", 0, "L", false, 0, "")
+	pdf.SetFont("Tomorrow", "", 10)
+	pdf.CellFormat(0, 8, "Factura Nro: "+factura.NFactura, "", 1, "R", false, 0, "")
+	pdf.CellFormat(100, 6, "Fecha de emision: "+factura.Fecha, "", 0, "L", false, 0, "")
+	pdf.CellFormat(0, 6, "Nro. Control: "+factura.NControl, "", 1, "R", false, 0, "")
+	if factura.TipoFactura == "fiscal_maquina" {
+		pdf.SetFont("Tomorrow", "", 8)
+		pdf.CellFormat(0, 5, "Representacion de la factura emitida por maquina fiscal", "", 1, "L", false, 0, "")
+		pdf.SetFont("Tomorrow", "", 10)
+	}
+	pdf.CellFormat(0, 2, "", "B", 1, "L", false, 0, "")
+	pdf.Ln(3)
+}
+
+func facturaNotaHeader(pdf *fpdf.Fpdf, factura models.FacturaResponse) {
+	pdf.SetY(45)
+	pdf.SetFont("Tomorrow", "", 16)
+	pdf.CellFormat(100, 8, "PRE-FACTURA", "", 0, "L", false, 0, "")
+	pdf.SetFont("Tomorrow", "", 10)
+	pdf.CellFormat(0, 8, "Nro: "+factura.NumReferencia, "", 1, "R", false, 0, "")
+	pdf.CellFormat(100, 6, "Fecha: "+factura.Fecha, "", 0, "L", false, 0, "")
+	pdf.CellFormat(0, 6, "Estatus: "+factura.Estatus, "", 1, "R", false, 0, "")
+	pdf.SetFont("Tomorrow", "", 8)
+	pdf.CellFormat(0, 5, "Documento sin validez fiscal", "B", 1, "L", false, 0, "")
+	pdf.SetFont("Tomorrow", "", 10)
+	pdf.Ln(3)
+}
+
+func facturaCliente(pdf *fpdf.Fpdf, factura models.FacturaResponse) {
+	pdf.SetFont("Tomorrow", "", 9)
+	pdf.CellFormat(120, 5, "Razon social: "+factura.RazonSocial, "", 0, "L", false, 0, "")
+	pdf.CellFormat(0, 5, "RIF/CI: "+strings.ToUpper(factura.DocId), "", 1, "R", false, 0, "")
+	pdf.MultiCell(0, 5, "Direccion: "+factura.Direccion, "", "L", false)
+	pdf.CellFormat(0, 5, "Telefono: "+factura.Telefono, "", 1, "L", false, 0, "")
+	pdf.Ln(3)
+}
+
+func facturaDetalle(pdf *fpdf.Fpdf, factura models.FacturaResponse) {
+	pdf.SetFont("Tomorrow", "", 9)
+	pdf.SetFillColor(235, 235, 235)
+	pdf.CellFormat(15, 7, "Cant.", "", 0, "C", true, 0, "")
+	pdf.CellFormat(91, 7, "Concepto", "", 0, "L", true, 0, "")
+	pdf.CellFormat(40, 7, "Precio unitario", "", 0, "R", true, 0, "")
+	pdf.CellFormat(0, 7, "Total", "", 1, "R", true, 0, "")
+	for _, det := range factura.FacturaDet {
+		concepto := det.Concepto
+		if det.TaxStatus != "" && det.TaxStatus != "gravable" {
+			concepto += " (E)"
+		}
+		pdf.CellFormat(15, 6, fmt.Sprintf("%g", det.Qty), "B", 0, "C", false, 0, "")
+		pdf.CellFormat(91, 6, concepto, "B", 0, "L", false, 0, "")
+		pdf.CellFormat(40, 6, fmt.Sprintf("Bs. %.2f", det.PriceUnit.Bolivar), "B", 0, "R", false, 0, "")
+		pdf.CellFormat(0, 6, fmt.Sprintf("Bs. %.2f", det.PriceTot.Bolivar), "B", 1, "R", false, 0, "")
+	}
+	pdf.Ln(3)
+}
+
+// totals in bolivares and dolares, the IGTF is shown only when it was charged
+func facturaFiscalTotales(pdf *fpdf.Fpdf, factura models.FacturaResponse) {
+	type total struct {
+		etiqueta string
+		monto    models.Moneda
+	}
+	totales := []total{
+		{"Subtotal", factura.SubTotal},
+		{fmt.Sprintf("Descuento (%g%%)", factura.DescPorc), factura.DescMonto},
+		{"Base imponible", factura.BaseImponible},
+		{fmt.Sprintf("IVA (%g%%)", factura.IvaPorc), factura.IvaMonto},
+	}
+	if factura.IgtfPorc > 0 {
+		totales = append(totales,
+			total{"Base imponible IGTF", factura.IgtfBase},
+			total{fmt.Sprintf("IGTF (%g%%)", factura.IgtfPorc), factura.IgtfMonto},
+		)
+	}
+	totales = append(totales, total{"Total a pagar", factura.Total})
+
+	pdf.SetFont("Tomorrow", "", 9)
+	pdf.SetX(91)
+	pdf.CellFormat(45, 6, "", "", 0, "L", false, 0, "")
+	pdf.CellFormat(35, 6, "Bolivares", "B", 0, "R", false, 0, "")
+	pdf.CellFormat(0, 6, "Dolares", "B", 1, "R", false, 0, "")
+	for _, item := range totales {
+		pdf.SetX(91)
+		pdf.CellFormat(45, 6, item.etiqueta, "", 0, "L", false, 0, "")
+		pdf.CellFormat(35, 6, fmt.Sprintf("%.2f", item.monto.Bolivar), "", 0, "R", false, 0, "")
+		pdf.CellFormat(0, 6, fmt.Sprintf("%.2f", item.monto.Dolar), "", 1, "R", false, 0, "")
+	}
+	if factura.TasaCambio > 0 {
+		pdf.SetFont("Tomorrow", "", 8)
+		pdf.CellFormat(0, 6, "Tasa de cambio BCV: "+FormatTasa(factura.TasaCambio), "", 1, "R", false, 0, "")
+	}
+	pdf.Ln(3)
+}
+
+func facturaNotaTotales(pdf *fpdf.Fpdf, factura models.FacturaResponse) {
+	pdf.SetFont("Tomorrow", "", 12)
+	pdf.CellFormat(0, 8, "Total: "+FormatMoneda(factura.Total), "", 1, "R", false, 0, "")
+	pdf.Ln(3)
+}
+
+func facturaRetencionesPagos(pdf *fpdf.Fpdf, factura models.FacturaResponse) {
+	pdf.SetFont("Tomorrow", "", 9)
+	pdf.SetFillColor(235, 235, 235)
+	if len(factura.Retenciones) > 0 {
+		pdf.CellFormat(0, 7, "Retenciones", "", 1, "L", true, 0, "")
+		for _, retencion := range factura.Retenciones {
+			pdf.CellFormat(60, 6, "Comprobante "+retencion.NComprobante, "B", 0, "L", false, 0, "")
+			pdf.CellFormat(40, 6, retencion.TipoRetencion, "B", 0, "L", false, 0, "")
+			pdf.CellFormat(30, 6, retencion.FechaRetencion, "B", 0, "L", false, 0, "")
+			pdf.CellFormat(0, 6, FormatMoneda(retencion.MontoRetenido), "B", 1, "R", false, 0, "")
+		}
+		pdf.Ln(3)
+	}
+	if len(factura.Payments) > 0 {
+		pdf.CellFormat(0, 7, "Pagos", "", 1, "L", true, 0, "")
+		for _, payment := range factura.Payments {
+			pdf.CellFormat(60, 6, "Recibo "+payment.Ncontrol, "B", 0, "L", false, 0, "")
+			pdf.CellFormat(40, 6, payment.Referencia, "B", 0, "L", false, 0, "")
+			pdf.CellFormat(30, 6, payment.Fecha, "B", 0, "L", false, 0, "")
+			pdf.CellFormat(0, 6, FormatMoneda(payment.MontoTotal), "B", 1, "R", false, 0, "")
+		}
+	}
+}
+
+// folder of the pdf of the facturas already rendered, FACTURA_PDF_CACHE_FOLDER or ./cache/facturas
+func facturaPdfCacheFolder() string {
+	if folder := os.Getenv("FACTURA_PDF_CACHE_FOLDER"); folder != "" {
+		return folder
+	}
+	return "./cache/facturas"
+}
+
+// the name of the cached pdf changes when the factura, its payments or retenciones are updated
+func facturaPdfCachePath(facturaId string, version string) string {
+	return filepath.Join(facturaPdfCacheFolder(), facturaId+"_"+version+".pdf")
+}
+
+func GetFacturaPdfCache(facturaId string, version string) ([]byte, bool) {
+	content, err := os.ReadFile(facturaPdfCachePath(facturaId, version))
+	if err != nil {
+		return nil, false
+	}
+	return content, true
+}
+
+// save the pdf and remove the versions of the factura that are outdated
+func SaveFacturaPdfCache(facturaId string, version string, content []byte) error {
+	if err := os.MkdirAll(facturaPdfCacheFolder(), 0755); err != nil {
+		return err
+	}
+
+	path := facturaPdfCachePath(facturaId, version)
+	old, _ := filepath.Glob(filepath.Join(facturaPdfCacheFolder(), facturaId+"_*.pdf"))
+	for _, file := range old {
+		if file != path {
+			os.Remove(file)
+		}
+	}
+
+	// written on a temp file first to not serve a half written pdf
+	tmp, err := os.CreateTemp(facturaPdfCacheFolder(), facturaId+"_*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
@@ -10,6 +10,7 @@ import (
 	"html"
 	"math/big"
 	"regexp"
+	"strings"
 	"time"
 )
 
@@ -94,3 +95,45 @@ func StringToTime(input string) *time.Time {
 
 	return &parsedTime
 }
+
+// sha256 hex of a token, used to save tokens without keeping the plain value
+func HashToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
+
+// random numeric code used for one-time codes
+func GenerateNumericCode(digits int) (string, error) {
+	max := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(digits)), nil)
+	n, err := rand.Int(rand.Reader, max)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%0*d", digits, n), nil
+}
+
+// hide most of an email to show where a code was sent, e.g. j***@gmail.com
+func MaskEmail(email string) string {
+	at := strings.LastIndex(email, "@")
+	if at < 1 {
+		return "***"
+	}
+	return email[:1] + "***" + email[at:]
+}
+
+// hide most of a name to confirm who is the owner of an account, e.g. Ju** Pe***
+func MaskName(name string) string {
+	var words []string
+	for _, word := range strings.Fields(name) {
+		runes := []rune(word)
+		visible := 2
+		if len(runes) <= 3 {
+			visible = 1
+		}
+		words = append(words, string(runes[:visible])+strings.Repeat("*", len(runes)-visible))
+	}
+	if len(words) == 0 {
+		return "***"
+	}
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,348 @@
+package utils
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"ired.com/micuenta/models"
+)
+
+const (
+	// other instances pick up the keys created by the rotation after this time
+	jwtKeysReloadTtl = 5 * time.Minute
+	// min time between reloads caused by an unknown kid
+	jwtKeysReloadMin = 10 * time.Second
+	// the kid is the creation time (utc) of the key, a copy or restore of the files
+	// changes their mtime so the age of a key is taken from its name
+	jwtKidLayout = "20060102T150405"
+)
+
+type jwtKey struct {
+	Kid       string
+	Method    jwt.SigningMethod
+	Private   crypto.Signer
+	CreatedAt time.Time
+}
+
+// signing and verification keys loaded from JWT_KEYS_DIR, each file <kid>.pem
+// has a private key in PKCS8, the newest key signs and all of them verify
+type jwtKeySet struct {
+	mu       sync.RWMutex
+	keys     map[string]*jwtKey
+	signing  *jwtKey
+	loadedAt time.Time
+}
+
+var jwtKeys = &jwtKeySet{keys: map[string]*jwtKey{}}
+
+func jwtKeysDir() string {
+	dir := os.Getenv("JWT_KEYS_DIR")
+	if dir == "" {
+		dir = "./keys"
+	}
+	return dir
+}
+
+// load the keys of the directory, if there is no key one is created
+func LoadJwtKeys() error {
+	if err := os.MkdirAll(jwtKeysDir(), 0700); err != nil {
+		return err
+	}
+
+	if err := jwtKeys.reload(); err != nil {
+		return err
+	}
+
+	jwtKeys.mu.RLock()
+	empty := jwtKeys.signing == nil
+	jwtKeys.mu.RUnlock()
+	if empty {
+		if _, err := createJwtKey(); err != nil {
+			return err
+		}
+		return jwtKeys.reload()
+	}
+
+	return nil
+}
+
+func (ks *jwtKeySet) reload() error {
+	files, err := filepath.Glob(filepath.Join(jwtKeysDir(), "*.pem"))
+	if err != nil {
+		return err
+	}
+
+	keys := map[string]*jwtKey{}
+	var signing *jwtKey
+	for _, file := range files {
+		key, err := readJwtKey(file)
+		if err != nil {
+			Logline("error reading jwt key", file, err)
+			continue
+		}
+		keys[key.Kid] = key
+		if signing == nil || key.CreatedAt.After(signing.CreatedAt) {
+			signing = key
+		}
+	}
+
+	ks.mu.Lock()
+	ks.keys = keys
+	ks.signing = signing
+	ks.loadedAt = time.Now()
+	ks.mu.Unlock()
+
+	return nil
+}
+
+func readJwtKey(file string) (*jwtKey, error) {
+	kid := strings.TrimSuffix(filepath.Base(file), ".pem")
+	createdAt, err := time.Parse(jwtKidLayout, kid)
+	if err != nil {
+		return nil, fmt.Errorf("kid %s is not a creation time %s", kid, jwtKidLayout)
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("invalid pem file")
+	}
+
+	var private any
+	switch block.Type {
+	case "PRIVATE KEY":
+		private, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		private, err = x509.ParseECPrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		private, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("pem type %s not supported", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	key := &jwtKey{
+		Kid:       kid,
+		CreatedAt: createdAt,
+	}
+	switch k := private.(type) {
+	case *ecdsa.PrivateKey:
+		if k.Curve != elliptic.P256() {
+			return nil, errors.New("only P-256 curve is supported")
+		}
+		key.Method = jwt.SigningMethodES256
+		key.Private = k
+	case *rsa.PrivateKey:
+		key.Method = jwt.SigningMethodRS256
+		key.Private = k
+	default:
+		return nil, errors.New("key type not supported")
+	}
+
+	return key, nil
+}
+
+// create a new key with the algorithm of JWT_ALG (ES256 by default)
+func createJwtKey() (string, error) {
+	var private crypto.Signer
+	var err error
+	switch os.Getenv("JWT_ALG") {
+	case "RS256":
+		private, err = rsa.GenerateKey(rand.Reader, 2048)
+	case "", "ES256":
+		private, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	default:
+		return "", fmt.Errorf("JWT_ALG %s not supported", os.Getenv("JWT_ALG"))
+	}
+	if err != nil {
+		return "", err
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return "", err
+	}
+
+	// write to a temp file first so the other instances never read half a key,
+	// link fails if other instance already created the key of this second
+	kid := time.Now().UTC().Format(jwtKidLayout)
+	file := filepath.Join(jwtKeysDir(), kid+".pem")
+	tmp := file + "." + strconv.Itoa(os.Getpid()) + ".tmp"
+	if err := os.WriteFile(tmp, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0600); err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp)
+	if err := os.Link(tmp, file); err != nil {
+		if errors.Is(err, os.ErrExist) {
+			return kid, nil
+		}
+		return "", err
+	}
+
+	Logline("jwt key created", kid)
+	return kid, nil
+}
+
+// create a new signing key when the current one is older than JWT_KEY_ROTATION_DAYS,
+// keys replaced longer than the max life of a token are removed
+func RotateJwtKeys() error {
+	rotationDays, err := strconv.Atoi(os.Getenv("JWT_KEY_ROTATION_DAYS"))
+	if err != nil {
+		Logline("error parsing JWT_KEY_ROTATION_DAYS", err)
+		return err
+	}
+	tokenMaxAge, err := strconv.Atoi(os.Getenv("REFRESH_MAX_AGE_REMEMBER"))
+	if err != nil {
+		Logline("error parsing REFRESH_MAX_AGE_REMEMBER", err)
+		return err
+	}
+
+	if err := jwtKeys.reload(); err != nil {
+		return err
+	}
+
+	jwtKeys.mu.RLock()
+	signing := jwtKeys.signing
+	keys := make([]*jwtKey, 0, len(jwtKeys.keys))
+	for _, key := range jwtKeys.keys {
+		keys = append(keys, key)
+	}
+	jwtKeys.mu.RUnlock()
+
+	if signing == nil || time.Since(signing.CreatedAt) > time.Duration(rotationDays)*24*time.Hour {
+		if _, err := createJwtKey(); err != nil {
+			return err
+		}
+	}
+
+	// a key signs until the next one exists (plus the reload of the instances)
+	// and its tokens are valid until the max age after that
+	sort.Slice(keys, func(i, j int) bool { return keys[i].CreatedAt.Before(keys[j].CreatedAt) })
+	grace := time.Duration(tokenMaxAge)*time.Second + jwtKeysReloadTtl
+	for i := 0; i < len(keys)-1; i++ {
+		if time.Since(keys[i+1].CreatedAt) > grace {
+			if err := os.Remove(filepath.Join(jwtKeysDir(), keys[i].Kid+".pem")); err != nil {
+				Logline("error removing jwt key", keys[i].Kid, err)
+				continue
+			}
+			Logline("jwt key removed", keys[i].Kid)
+		}
+	}
+
+	return jwtKeys.reload()
+}
+
+// sign the claims with the current key, the kid goes on the header
+func SignJwt(claims jwt.Claims) (string, error) {
+	jwtKeys.mu.RLock()
+	signing := jwtKeys.signing
+	loadedAt := jwtKeys.loadedAt
+	jwtKeys.mu.RUnlock()
+
+	if signing == nil || time.Since(loadedAt) > jwtKeysReloadTtl {
+		if err := jwtKeys.reload(); err != nil {
+			Logline("error reloading jwt keys", err)
+		}
+		jwtKeys.mu.RLock()
+		signing = jwtKeys.signing
+		jwtKeys.mu.RUnlock()
+	}
+	if signing == nil {
+		return "", errors.New("there is no jwt key to sign")
+	}
+
+	token := jwt.NewWithClaims(signing.Method, claims)
+	token.Header["kid"] = signing.Kid
+	return token.SignedString(signing.Private)
+}
+
+// parse and validate a token signed with any of the keys of the set
+func ParseJwt(tokenString string, claims jwt.Claims) (*jwt.Token, error) {
+	return jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		key := jwtKeys.get(kid)
+		if key == nil {
+			return nil, fmt.Errorf("unknown kid %q", kid)
+		}
+		if key.Method.Alg() != token.Method.Alg() {
+			return nil, errors.New("signing method does not match the key")
+		}
+		return key.Private.Public(), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodES256.Alg(), jwt.SigningMethodRS256.Alg()}))
+}
+
+// get the key of the kid, a key created by other instance is loaded on demand
+func (ks *jwtKeySet) get(kid string) *jwtKey {
+	ks.mu.RLock()
+	key := ks.keys[kid]
+	loadedAt := ks.loadedAt
+	ks.mu.RUnlock()
+
+	if key == nil && kid != "" && time.Since(loadedAt) > jwtKeysReloadMin {
+		if err := ks.reload(); err != nil {
+			Logline("error reloading jwt keys", err)
+			return nil
+		}
+		ks.mu.RLock()
+		key = ks.keys[kid]
+		ks.mu.RUnlock()
+	}
+
+	return key
+}
+
+// public keys of the set in JWK format
+func JwtKeysJwks() models.JwksResponse {
+	jwtKeys.mu.RLock()
+	if time.Since(jwtKeys.loadedAt) > jwtKeysReloadTtl {
+		jwtKeys.mu.RUnlock()
+		if err := jwtKeys.reload(); err != nil {
+			Logline("error reloading jwt keys", err)
+		}
+		jwtKeys.mu.RLock()
+	}
+	defer jwtKeys.mu.RUnlock()
+
+	jwks := models.JwksResponse{Keys: []models.Jwk{}}
+	for _, key := range jwtKeys.keys {
+		jwk := models.Jwk{Kid: key.Kid, Use: "sig", Alg: key.Method.Alg()}
+		switch public := key.Private.Public().(type) {
+		case *ecdsa.PublicKey:
+			jwk.Kty = "EC"
+			jwk.Crv = "P-256"
+			jwk.X = base64.RawURLEncoding.EncodeToString(public.X.FillBytes(make([]byte, 32)))
+			jwk.Y = base64.RawURLEncoding.EncodeToString(public.Y.FillBytes(make([]byte, 32)))
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+		}
+		jwks.Keys = append(jwks.Keys, jwk)
+	}
+	sort.Slice(jwks.Keys, func(i, j int) bool { return jwks.Keys[i].Kid > jwks.Keys[j].Kid })
+
+	return jwks
+}
@@ -0,0 +1,241 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+	"os"
+	"strconv"
+
+	"golang.org/x/image/draw"
+)
+
+var (
+	ErrFileType      = errors.New("file type not allowed")
+	ErrFileSize      = errors.New("file size not allowed")
+	ErrFileDimension = errors.New("image dimension not allowed")
+	ErrFileInvalid   = errors.New("file is damaged or not allowed")
+)
+
+const (
+	imageMinSide = 200
+	imageMaxSide = 6000
+	// a phone photo of 12MP is decoded in ~18MB of memory and its rotation takes other
+	// ~48MB, this is the max for a proof
+	imageMaxPixels = 16_000_000
+	// rows converted to rgba at a time when the image is rotated
+	orientationStripRows = 64
+	thumbnailSide        = 320
+)
+
+// file ready to save, the images are encoded again without metadata
+type ProcessedFile struct {
+	Content     []byte
+	ContentType string
+	Ext         string
+	Thumbnail   []byte
+}
+
+// max size of a proof in bytes, PAYMENT_FILE_MAX_SIZE or 5MB
+func PaymentFileMaxSize() int64 {
+	maxSize, err := strconv.ParseInt(os.Getenv("PAYMENT_FILE_MAX_SIZE"), 10, 64)
+	if err != nil || maxSize <= 0 {
+		return 5 << 20
+	}
+	return maxSize
+}
+
+// validate the proof by its content (not the extension), images are png or jpeg
+// and are encoded again to remove the exif/gps data, pdf files are kept as they are
+func ProcessPaymentFile(content []byte) (*ProcessedFile, error) {
+	if len(content) == 0 || int64(len(content)) > PaymentFileMaxSize() {
+		return nil, ErrFileSize
+	}
+
+	switch http.DetectContentType(content) {
+	case "image/png", "image/jpeg":
+		return processImage(content)
+	case "application/pdf":
+		return processPdf(content)
+	}
+
+	return nil, ErrFileType
+}
+
+func processImage(content []byte) (*ProcessedFile, error) {
+	config, format, err := image.DecodeConfig(bytes.NewReader(content))
+	if err != nil {
+		return nil, ErrFileInvalid
+	}
+	if config.Width < imageMinSide || config.Height < imageMinSide || config.Width > imageMaxSide || config.Height > imageMaxSide ||
+		config.Width*config.Height > imageMaxPixels {
+		return nil, ErrFileDimension
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(content))
+	if err != nil {
+		return nil, ErrFileInvalid
+	}
+
+	// the metadata is lost when encoding, the rotation of the camera must be applied first
+	if format == "jpeg" {
+		img = applyOrientation(img, jpegOrientation(content))
+	}
+
+	var buf bytes.Buffer
+	processed := ProcessedFile{}
+	if format == "png" {
+		err = png.Encode(&buf, img)
+		processed.ContentType, processed.Ext = "image/png", ".png"
+	} else {
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: 85})
+		processed.ContentType, processed.Ext = "image/jpeg", ".jpg"
+	}
+	if err != nil {
+		return nil, err
+	}
+	processed.Content = buf.Bytes()
+
+	var thumb bytes.Buffer
+	if err := jpeg.Encode(&thumb, resizeImage(img, thumbnailSide), &jpeg.Options{Quality: 75}); err != nil {
+		return nil, err
+	}
+	processed.Thumbnail = thumb.Bytes()
+
+	return &processed, nil
+}
+
+// the pdf must be complete and without scripts, attached files, encryption or object streams
+func processPdf(content []byte) (*ProcessedFile, error) {
+	if !bytes.HasPrefix(content, []byte("%PDF-")) || !bytes.Contains(content[max(0, len(content)-1024):], []byte("%%EOF")) {
+		return nil, ErrFileInvalid
+	}
+	if err := checkPdf(content); err != nil {
+		return nil, ErrFileInvalid
+	}
+
+	return &ProcessedFile{Content: content, ContentType: "application/pdf", Ext: ".pdf"}, nil
+}
+
+// scale the image to fit in a square of side, the bilinear kernel takes all the
+// pixels of the area so the thumbnail has no aliasing
+func resizeImage(img image.Image, side int) image.Image {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width <= side && height <= side {
+		return img
+	}
+
+	newWidth, newHeight := side, height*side/width
+	if height > width {
+		newWidth, newHeight = width*side/height, side
+	}
+	newWidth, newHeight = max(newWidth, 1), max(newHeight, 1)
+
+	thumb := image.NewRGBA(image.Rect(0, 0, newWidth, newHeight))
+	draw.BiLinear.Scale(thumb, thumb.Bounds(), img, bounds, draw.Src, nil)
+
+	return thumb
+}
+
+// orientation tag (0x0112) of the exif segment of a jpeg, 1 when it is not present
+func jpegOrientation(content []byte) int {
+	reader := bytes.NewReader(content)
+	var marker [2]byte
+	if _, err := reader.Read(marker[:]); err != nil || marker != [2]byte{0xFF, 0xD8} {
+		return 1
+	}
+
+	for {
+		var header [4]byte
+		if _, err := reader.Read(header[:]); err != nil || header[0] != 0xFF {
+			return 1
+		}
+		size := int(binary.BigEndian.Uint16(header[2:])) - 2
+		if size < 0 {
+			return 1
+		}
+		segment := make([]byte, size)
+		if _, err := reader.Read(segment); err != nil {
+			return 1
+		}
+		// start of scan, there is no more metadata
+		if header[1] == 0xDA {
+			return 1
+		}
+		if header[1] != 0xE1 || len(segment) < 14 || string(segment[:6]) != "Exif\x00\x00" {
+			continue
+		}
+
+		tiff := segment[6:]
+		var order binary.ByteOrder = binary.BigEndian
+		if string(tiff[:2]) == "II" {
+			order = binary.LittleEndian
+		}
+		offset := int(order.Uint32(tiff[4:8]))
+		if offset+2 > len(tiff) {
+			return 1
+		}
+		entries := int(order.Uint16(tiff[offset:]))
+		for i := 0; i < entries; i++ {
+			entry := offset + 2 + i*12
+			if entry+12 > len(tiff) {
+				return 1
+			}
+			if order.Uint16(tiff[entry:]) == 0x0112 {
+				return int(order.Uint16(tiff[entry+8:]))
+			}
+		}
+		return 1
+	}
+}
+
+// rotate or flip the image as the exif orientation says, the rows are converted to rgba
+// by strips so the pixels are copied without the color conversion of At/Set and without
+// a full copy of the image
+func applyOrientation(img image.Image, orientation int) image.Image {
+	if orientation < 2 || orientation > 8 {
+		return img
+	}
+
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	out := image.NewRGBA(image.Rect(0, 0, width, height))
+	if orientation >= 5 {
+		out = image.NewRGBA(image.Rect(0, 0, height, width))
+	}
+
+	src := image.NewRGBA(image.Rect(0, 0, width, orientationStripRows))
+	for y := 0; y < height; y++ {
+		strip := y % orientationStripRows
+		if strip == 0 {
+			draw.Draw(src, src.Bounds(), img, image.Pt(bounds.Min.X, bounds.Min.Y+y), draw.Src)
+		}
+		for x := 0; x < width; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2:
+				dx, dy = width-1-x, y
+			case 3:
+				dx, dy = width-1-x, height-1-y
+			case 4:
+				dx, dy = x, height-1-y
+			case 5:
+				dx, dy = y, x
+			case 6:
+				dx, dy = height-1-y, x
+			case 7:
+				dx, dy = height-1-y, width-1-x
+			case 8:
+				dx, dy = y, width-1-x
+			}
+			copy(out.Pix[out.PixOffset(dx, dy):out.PixOffset(dx, dy)+4], src.Pix[src.PixOffset(x, strip):src.PixOffset(x, strip)+4])
+		}
+	}
+
+	return out
+}
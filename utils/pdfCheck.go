@@ -0,0 +1,204 @@
+package utils
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// names of the pdf that run code or carry other files when it is opened, an empty
+// EmbeddedFiles tree is written by many generators, the files need the EF key.
+// The encrypted files and the object streams (compressed objects) can hide the other
+// names from this check, so they are rejected too
+var pdfForbiddenNames = map[string]bool{
+	"JavaScript":   true,
+	"JS":           true,
+	"Launch":       true,
+	"EmbeddedFile": true,
+	"EF":           true,
+	"RichMedia":    true,
+	"XFA":          true,
+	"Encrypt":      true,
+	"ObjStm":       true,
+}
+
+// objects with only an integer, used as /Length of the streams
+var pdfIntObjRegexp = regexp.MustCompile(`(\d+)\s+(\d+)\s+obj\s*(\d+)\s*endobj`)
+
+// the tokens are read from the bytes of the file, so the names written with #xx escapes
+// are found too. The strings and the data of the streams are skipped
+type pdfScanner struct {
+	data    []byte
+	pos     int
+	lengths map[string]int
+}
+
+func checkPdf(content []byte) error {
+	s := &pdfScanner{data: content, lengths: map[string]int{}}
+	for _, match := range pdfIntObjRegexp.FindAllSubmatch(content, -1) {
+		length, _ := strconv.Atoi(string(match[3]))
+		s.lengths[string(match[1])+" "+string(match[2])] = length
+	}
+
+	// the last /Length read is the one of the stream that follows
+	length := -1
+	for {
+		token, isName, ok := s.token()
+		if !ok {
+			return nil
+		}
+		switch {
+		case isName && pdfForbiddenNames[token]:
+			return fmt.Errorf("pdf with /%s", token)
+		case isName && token == "Length":
+			length = s.lengthValue()
+		case !isName && token == "stream":
+			if err := s.skipStream(length); err != nil {
+				return err
+			}
+			length = -1
+		}
+	}
+}
+
+func pdfIsSpace(c byte) bool {
+	return c == 0 || c == '\t' || c == '\n' || c == '\f' || c == '\r' || c == ' '
+}
+
+func pdfIsDelimiter(c byte) bool {
+	return bytes.IndexByte([]byte("()<>[]{}/%"), c) >= 0
+}
+
+// next token and if it is a name (without the slash and the #xx escapes decoded),
+// the strings are returned empty. False at the end of the data
+func (s *pdfScanner) token() (string, bool, bool) {
+	for s.pos < len(s.data) {
+		c := s.data[s.pos]
+		if pdfIsSpace(c) {
+			s.pos++
+			continue
+		}
+		if c == '%' {
+			for s.pos < len(s.data) && s.data[s.pos] != '\n' && s.data[s.pos] != '\r' {
+				s.pos++
+			}
+			continue
+		}
+		break
+	}
+	if s.pos >= len(s.data) {
+		return "", false, false
+	}
+
+	c := s.data[s.pos]
+	switch {
+	case c == '/':
+		s.pos++
+		var name []byte
+		for s.pos < len(s.data) && !pdfIsSpace(s.data[s.pos]) && !pdfIsDelimiter(s.data[s.pos]) {
+			if s.data[s.pos] == '#' && s.pos+2 < len(s.data) {
+				if b, err := strconv.ParseUint(string(s.data[s.pos+1:s.pos+3]), 16, 8); err == nil {
+					name = append(name, byte(b))
+					s.pos += 3
+					continue
+				}
+			}
+			name = append(name, s.data[s.pos])
+			s.pos++
+		}
+		return string(name), true, true
+	case c == '(':
+		s.skipLiteralString()
+		return "", false, true
+	case c == '<' && (s.pos+1 >= len(s.data) || s.data[s.pos+1] != '<'):
+		if end := bytes.IndexByte(s.data[s.pos:], '>'); end >= 0 {
+			s.pos += end + 1
+		} else {
+			s.pos = len(s.data)
+		}
+		return "", false, true
+	case (c == '<' || c == '>') && s.pos+1 < len(s.data) && s.data[s.pos+1] == c:
+		s.pos += 2
+		return string(s.data[s.pos-2 : s.pos]), false, true
+	case pdfIsDelimiter(c) || c == '>':
+		s.pos++
+		return string(c), false, true
+	}
+
+	start := s.pos
+	for s.pos < len(s.data) && !pdfIsSpace(s.data[s.pos]) && !pdfIsDelimiter(s.data[s.pos]) {
+		s.pos++
+	}
+	return string(s.data[start:s.pos]), false, true
+}
+
+// the strings can have balanced parentheses and escaped ones
+func (s *pdfScanner) skipLiteralString() {
+	depth := 0
+	for s.pos++; s.pos < len(s.data); s.pos++ {
+		switch s.data[s.pos] {
+		case '\\':
+			s.pos++
+		case '(':
+			depth++
+		case ')':
+			if depth == 0 {
+				s.pos++
+				return
+			}
+			depth--
+		}
+	}
+}
+
+// value of /Length, a number or a reference (N G R) to an object with the number, -1 if unknown
+func (s *pdfScanner) lengthValue() int {
+	start := s.pos
+	num, _, _ := s.token()
+	length, err := strconv.Atoi(num)
+	if err != nil {
+		s.pos = start
+		return -1
+	}
+
+	afterNum := s.pos
+	gen, _, _ := s.token()
+	keyword, _, _ := s.token()
+	if _, err := strconv.Atoi(gen); err == nil && keyword == "R" {
+		if length, ok := s.lengths[num+" "+gen]; ok {
+			return length
+		}
+		return -1
+	}
+	s.pos = afterNum
+	return length
+}
+
+// move after endstream, the data is taken by its length when it ends on the keyword,
+// with a wrong length the data ends on the first endstream
+func (s *pdfScanner) skipStream(length int) error {
+	if s.pos < len(s.data) && s.data[s.pos] == '\r' {
+		s.pos++
+	}
+	if s.pos < len(s.data) && s.data[s.pos] == '\n' {
+		s.pos++
+	}
+
+	if length >= 0 && s.pos+length <= len(s.data) {
+		end := s.pos + length
+		rest := bytes.TrimLeft(s.data[end:min(end+16, len(s.data))], "\r\n \t")
+		if bytes.HasPrefix(rest, []byte("endstream")) {
+			s.pos = end + bytes.Index(s.data[end:], []byte("endstream")) + len("endstream")
+			return nil
+		}
+	}
+
+	end := bytes.Index(s.data[s.pos:], []byte("endstream"))
+	if end < 0 {
+		return errors.New("pdf stream without endstream")
+	}
+	s.pos += end + len("endstream")
+	return nil
+}
@@ -0,0 +1,95 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/go-pdf/fpdf"
+)
+
+// a pdf with the objects given, the scanner does not use the xref table so it is not written
+func testPdf(objects ...string) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	for i, object := range objects {
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+	buf.WriteString("trailer\n<< /Root 1 0 R /Size " + fmt.Sprint(len(objects)+1) + " >>\nstartxref\n0\n%%EOF\n")
+	return buf.Bytes()
+}
+
+// a receipt like the ones of the banks: text, a table and compressed streams
+func testBankPdf(t *testing.T, setup func(pdf *fpdf.Fpdf)) []byte {
+	t.Helper()
+	pdf := fpdf.New("P", "mm", "Letter", "")
+	if setup != nil {
+		setup(pdf)
+	}
+	pdf.AddPage()
+	pdf.SetFont("Helvetica", "B", 14)
+	pdf.Cell(0, 10, "Comprobante de transferencia")
+	pdf.Ln(12)
+	pdf.SetFont("Helvetica", "", 10)
+	for _, row := range [][2]string{{"Referencia", "000123456789"}, {"Monto", "Bs. 1.250,00"}, {"Fecha", "15/01/2025"}} {
+		pdf.CellFormat(50, 8, row[0], "1", 0, "L", false, 0, "")
+		pdf.CellFormat(80, 8, row[1], "1", 1, "L", false, 0, "")
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestProcessPdf(t *testing.T) {
+	catalog := "<< /Type /Catalog /Pages 2 0 R >>"
+	pages := "<< /Type /Pages /Kids [] /Count 0 >>"
+	binary := "\x00\xff/JavaScript (\x8f<<"
+
+	tests := []struct {
+		name    string
+		content []byte
+		ok      bool
+	}{
+		{"bank receipt", testBankPdf(t, nil), true},
+		{"bank receipt without compression", testBankPdf(t, func(pdf *fpdf.Fpdf) { pdf.SetCompression(false) }), true},
+		{"encrypted bank receipt", testBankPdf(t, func(pdf *fpdf.Fpdf) { pdf.SetProtection(fpdf.CnProtectPrint, "", "owner") }), false},
+		{"bank receipt with javascript", testBankPdf(t, func(pdf *fpdf.Fpdf) { pdf.SetJavascript("app.alert('hola');") }), false},
+		{"minimal", testPdf(catalog, pages), true},
+		{"empty embedded files tree", testPdf("<< /Type /Catalog /Pages 2 0 R /Names << /EmbeddedFiles << /Names [] >> >> >>", pages), true},
+		{"names inside strings", testPdf("<< /Type /Catalog /Pages 2 0 R /Title (see /JavaScript and \\) /JS \\( here) /Subject (a (nested /Launch) one) >>", pages), true},
+		{"names inside hex strings", testPdf("<< /Type /Catalog /Pages 2 0 R /ID [<4a53> <2f4a53>] >>", pages), true},
+		{"binary stream", testPdf(catalog, pages, fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(binary), binary)), true},
+		{"binary stream with indirect length", testPdf(catalog, pages, "<< /Length 4 0 R >>\nstream\n"+binary+"\nendstream", fmt.Sprint(len(binary))), true},
+		{"binary stream with wrong length", testPdf(catalog, pages, "<< /Length 3 >>\nstream\n"+binary+"\nendstream"), true},
+		{"open action javascript", testPdf("<< /Type /Catalog /Pages 2 0 R /OpenAction 3 0 R >>", pages, "<< /S /JavaScript /JS (app.alert(1)) >>"), false},
+		{"escaped name", testPdf("<< /Type /Catalog /Pages 2 0 R /OpenAction << /S /J#61va#53cript /J#53 (x) >> >>", pages), false},
+		{"launch action", testPdf("<< /Type /Catalog /Pages 2 0 R /OpenAction << /S /Launch /F (cmd.exe) >> >>", pages), false},
+		{"attached file", testPdf(catalog, pages, "<< /Type /Filespec /F (a.exe) /EF << /F 4 0 R >> >>", "<< /Type /EmbeddedFile /Length 2 >>\nstream\nMZ\nendstream"), false},
+		{"xfa form", testPdf("<< /Type /Catalog /Pages 2 0 R /AcroForm << /XFA 3 0 R >> >>", pages), false},
+		{"rich media", testPdf(catalog, pages, "<< /Type /Annot /Subtype /RichMedia >>"), false},
+		{"encrypt on trailer", []byte(strings.Replace(string(testPdf(catalog, pages)), "/Size", "/Encrypt 9 0 R /Size", 1)), false},
+		{"object stream", testPdf(catalog, pages, "<< /Type /ObjStm /N 1 /First 4 /Length 10 >>\nstream\n5 0 << >>\nendstream"), false},
+		{"stream without endstream", testPdf(catalog, pages, "<< /Length 100 >>\nstream\n/JS hidden"), false},
+		{"without header", []byte("<< /JS (x) >>\n%%EOF\n"), false},
+		{"truncated", testBankPdf(t, nil)[:500], false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			processed, err := processPdf(test.content)
+			if test.ok && err != nil {
+				t.Fatalf("expected valid pdf, got %v", err)
+			}
+			if !test.ok && err == nil {
+				t.Fatal("expected pdf to be rejected")
+			}
+			if test.ok && processed.ContentType != "application/pdf" {
+				t.Fatalf("unexpected content type %s", processed.ContentType)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/go-pdf/fpdf"
+	"github.com/skip2/go-qrcode"
+	"ired.com/micuenta/models"
+)
+
+const (
+	receiptLogo = "public/assets/logo_mail.png"
+	receiptFont = "public/fonts/Tomorrow-Regular.ttf"
+)
+
+// amount in both currencies as shown on the comprobantes
+func FormatMoneda(monto models.Moneda) string {
+	return fmt.Sprintf("$ %.2f / Bs. %.2f", monto.Dolar, monto.Bolivar)
+}
+
+func FormatTasa(tasa float64) string {
+	return fmt.Sprintf("Bs. %.4f", tasa)
+}
+
+// new letter size document with the logo and the data of the company on the header
+func newBesserPdf(titulo string, empresa models.FacturaDatosBesser) *fpdf.Fpdf {
+	pdf := fpdf.New("P", "mm", "Letter", "")
+	pdf.SetTitle(titulo, true)
+	pdf.SetAuthor("Besser Solutions", true)
+	pdf.AddUTF8Font("Tomorrow", "", receiptFont)
+	pdf.SetMargins(15, 15, 15)
+	pdf.SetAutoPageBreak(true, 20)
+	pdf.AddPage()
+
+	// header: logo and company
+	pdf.ImageOptions(receiptLogo, 15, 15, 45, 0, false, fpdf.ImageOptions{ImageType: "PNG", ReadDpi: true}, 0, "")
+	pdf.SetFont("Tomorrow", "", 8)
+	pdf.SetXY(110, 15)
+	pdf.MultiCell(91, 4, fmt.Sprintf("Besser Solutions, RIF: %s\n%s\nTelf: %s\n%s",
+		empresa.Docid, empresa.Direccion, empresa.Telefono, empresa.Email), "", "R", false)
+
+	return pdf
+}
+
+func outputPdf(pdf *fpdf.Fpdf) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// render a comprobante with a QR with the url to verify it
+func GenerateReceiptPdf(receipt models.ReceiptPdf) ([]byte, error) {
+	pdf := newBesserPdf(receipt.Titulo+" "+receipt.Ncontrol, receipt.Empresa)
+
+	// title and control number
+	pdf.SetY(45)
+	pdf.SetFont("Tomorrow", "", 16)
+	pdf.CellFormat(0, 8, receipt.Titulo, "", 1, "L", false, 0, "")
+	pdf.SetFont("Tomorrow", "", 10)
+	pdf.CellFormat(0, 6, "Nro. Control: "+receipt.Ncontrol+"    Fecha: "+receipt.Fecha, "B", 1, "L", false, 0, "")
+	pdf.Ln(4)
+
+	// main data
+	for _, linea := range receipt.Lineas {
+		pdf.SetTextColor(110, 110, 110)
+		pdf.CellFormat(50, 7, linea.Etiqueta, "", 0, "L", false, 0, "")
+		pdf.SetTextColor(0, 0, 0)
+		pdf.MultiCell(0, 7, linea.Valor, "", "L", false)
+	}
+
+	// detail of the suscripciones / facturas paid
+	if len(receipt.Detalle) > 0 {
+		pdf.Ln(4)
+		pdf.SetFillColor(235, 235, 235)
+		pdf.CellFormat(120, 7, "Detalle", "", 0, "L", true, 0, "")
+		pdf.CellFormat(0, 7, "Monto", "", 1, "R", true, 0, "")
+		for _, linea := range receipt.Detalle {
+			pdf.CellFormat(120, 7, linea.Etiqueta, "B", 0, "L", false, 0, "")
+			pdf.CellFormat(0, 7, linea.Valor, "B", 1, "R", false, 0, "")
+		}
+	}
+
+	pdf.Ln(2)
+	pdf.SetFont("Tomorrow", "", 12)
+	pdf.CellFormat(0, 8, "Total: "+FormatMoneda(receipt.Total), "", 1, "R", false, 0, "")
+
+	// QR to verify the comprobante
+	if receipt.VerifyUrl != "" {
+		png, err := qrcode.Encode(receipt.VerifyUrl, qrcode.Medium, 256)
+		if err != nil {
+			return nil, err
+		}
+		pdf.RegisterImageOptionsReader("qr", fpdf.ImageOptions{ImageType: "PNG"}, bytes.NewReader(png))
+
+		y := pdf.GetY() + 8
+		pdf.ImageOptions("qr", 15, y, 35, 35, false, fpdf.ImageOptions{ImageType: "PNG"}, 0, receipt.VerifyUrl)
+		pdf.SetFont("Tomorrow", "", 8)
+		pdf.SetXY(55, y+12)
+		pdf.MultiCell(0, 4, "Escanee el codigo QR para verificar la autenticidad de este comprobante.", "", "L", false)
+	}
+
+	return outputPdf(pdf)
+}
@@ -0,0 +1,19 @@
+package utils
+
+import (
+	"os"
+
+	"github.com/gorilla/securecookie"
+)
+
+// name of the cookie and the session of the clientes
+const SessionName = "auth_session"
+
+// decode the data column of cliente_session_store, it is used to read the
+// sessions of the other devices of the cliente
+func DecodeSessionValues(data string) (map[interface{}]interface{}, error) {
+	values := map[interface{}]interface{}{}
+	codecs := securecookie.CodecsFromPairs([]byte(os.Getenv("SECRET")))
+	err := securecookie.DecodeMulti(SessionName, data, &values, codecs...)
+	return values, err
+}
@@ -0,0 +1,150 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"sync"
+	"time"
+)
+
+// provider used to send the sms, selected with SMS_PROVIDER
+type SmsProvider interface {
+	Send(to string, message string) error
+}
+
+// gateway that receives a json post with the number and the message
+type httpSmsProvider struct {
+	url    string
+	token  string
+	from   string
+	client *http.Client
+}
+
+func (p *httpSmsProvider) Send(to string, message string) error {
+	body, err := json.Marshal(map[string]string{"from": p.from, "to": to, "message": message})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if p.token != "" {
+		req.Header.Set("Authorization", "Bearer "+p.token)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("sms gateway responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// stub for development and tests, the messages are written to a file
+type logSmsProvider struct {
+	mu   sync.Mutex
+	file string
+}
+
+func (p *logSmsProvider) Send(to string, message string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	f, err := os.OpenFile(p.file, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = fmt.Fprintf(f, "%s\t%s\t%s\n", time.Now().Format(time.RFC3339), to, message)
+	return err
+}
+
+var (
+	smsProvider     SmsProvider
+	smsProviderOnce sync.Once
+	smsProviderErr  error
+)
+
+func getSmsProvider() (SmsProvider, error) {
+	smsProviderOnce.Do(func() {
+		switch os.Getenv("SMS_PROVIDER") {
+		case "http":
+			if os.Getenv("SMS_API_URL") == "" {
+				smsProviderErr = errors.New("SMS_API_URL is required for the http sms provider")
+				return
+			}
+			smsProvider = &httpSmsProvider{
+				url:    os.Getenv("SMS_API_URL"),
+				token:  os.Getenv("SMS_API_TOKEN"),
+				from:   os.Getenv("SMS_FROM"),
+				client: &http.Client{Timeout: 10 * time.Second},
+			}
+		case "":
+			// the stub must be chosen explicitly, if not the codes would only be written to a file
+			smsProviderErr = errors.New("SMS_PROVIDER is not defined")
+		case "log":
+			file := os.Getenv("SMS_LOG_FILE")
+			if file == "" {
+				file = "logs/sms.log"
+			}
+			smsProvider = &logSmsProvider{file: file}
+		default:
+			smsProviderErr = fmt.Errorf("SMS_PROVIDER %s not supported", os.Getenv("SMS_PROVIDER"))
+		}
+	})
+	return smsProvider, smsProviderErr
+}
+
+func SendSms(to string, message string) error {
+	provider, err := getSmsProvider()
+	if err != nil {
+		return err
+	}
+	return provider.Send(NormalizeCelular(to), message)
+}
+
+// venezuelan mobile numbers in international format, e.g. 04141234567 -> +584141234567
+func NormalizeCelular(phone string) string {
+	digits := ExtractNumbers(phone)
+	if len(digits) == 11 && digits[0] == '0' {
+		return "+58" + digits[1:]
+	}
+	if len(digits) == 12 && digits[:2] == "58" {
+		return "+" + digits
+	}
+	return phone
+}
+
+var celularRegexp = regexp.MustCompile(`^(58|0)4(12|16|26|14|24)[0-9]{7}$`)
+
+// first mobile number of the list, the cliente could have landlines too
+func FirstCelular(phones []string) string {
+	for _, phone := range phones {
+		if celularRegexp.MatchString(ExtractNumbers(phone)) {
+			return phone
+		}
+	}
+	return ""
+}
+
+// hide most of a phone to show where a code was sent, e.g. 0414***4567
+func MaskPhone(phone string) string {
+	digits := ExtractNumbers(phone)
+	if len(digits) < 8 {
+		return "***"
+	}
+	return digits[:4] + "***" + digits[len(digits)-4:]
+}
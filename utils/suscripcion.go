@@ -1,8 +1,10 @@
 package utils
 
 import (
+	"math"
 	"regexp"
 	"strings"
+	"time"
 
 	"ired.com/micuenta/models"
 )
@@ -66,25 +68,54 @@ func PaymentReqAmountHasDupIds(payments []models.PaymentReqDetail) bool {
 	return false // No duplicates found
 }
 
-// // GetNextRenewalDate calculates the next renewal date based on the installed date
-// func NextRenewalDate(subscribedDate sql.NullTime) (time.Time, error) {
-// 	if !subscribedDate.Valid {
-// 		return time.Time{}, fmt.Errorf("invalid subscription date")
-// 	}
+// now in Caracas, the renewal dates and the auto-pay use it and not the timezone of the server or the db
+func NowCaracas() time.Time {
+	loc, err := time.LoadLocation("America/Caracas")
+	if err != nil {
+		loc = time.Local
+	}
+
+	return time.Now().In(loc)
+}
 
-// 	// Get current date
-// 	now := time.Now()
+// renewal date of the suscripcion on a month, the suscripciones installed on a day that the
+// month does not have (29, 30, 31) are renewed on the last day of the month
+func RenewalDateOfMonth(year int, month time.Month, renewalDay int, loc *time.Location) time.Time {
+	lastDay := time.Date(year, month+1, 0, 0, 0, 0, 0, loc).Day()
+	return time.Date(year, month, min(renewalDay, lastDay), 0, 0, 0, 0, loc)
+}
 
-// 	// Extract the day from the subscribed date
-// 	renewalDay := subscribedDate.Time.Day()
+// next renewal date from the install date, when today is the renewal day the next renewal is today
+func NextRenewalDate(installDate time.Time, now time.Time) time.Time {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
 
-// 	// Get next renewal month & year
-// 	nextRenewal := time.Date(now.Year(), now.Month(), renewalDay, 0, 0, 0, 0, now.Location())
+	nextRenewal := RenewalDateOfMonth(today.Year(), today.Month(), installDate.Day(), today.Location())
+	if nextRenewal.Before(today) {
+		nextRenewal = RenewalDateOfMonth(today.Year(), today.Month()+1, installDate.Day(), today.Location())
+	}
 
-// 	// If today's date is past renewalDay, set renewal for the next month
-// 	if now.Day() >= renewalDay {
-// 		nextRenewal = nextRenewal.AddDate(0, 1, 0)
-// 	}
+	return nextRenewal
+}
 
-// 	return nextRenewal, nil
-// }
+// billing calendar of the suscripcion, the amounts are projected with the tasa_cambio of today
+func SuscripcionCalendario(installDate time.Time, now time.Time, costo float64, tasaCambio float64, meses int) models.SuscripcionCalendario {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	nextRenewal := NextRenewalDate(installDate, today)
+	monto := models.Moneda{Dolar: costo, Bolivar: RoundToTwoDecimalPlaces(costo * tasaCambio)}
+
+	calendario := models.SuscripcionCalendario{
+		FechaInstalacion:  installDate.Format("2006-01-02"),
+		DiaRenovacion:     installDate.Day(),
+		ProximaRenovacion: nextRenewal.Format("2006-01-02"),
+		DiasRestantes:     int(math.Round(nextRenewal.Sub(today).Hours() / 24)),
+		MontoProyectado:   monto,
+		TasaCambio:        tasaCambio,
+		Proyeccion:        []models.SuscripcionProyeccion{},
+	}
+	for i := 0; i < meses; i++ {
+		fecha := RenewalDateOfMonth(nextRenewal.Year(), nextRenewal.Month()+time.Month(i), installDate.Day(), nextRenewal.Location())
+		calendario.Proyeccion = append(calendario.Proyeccion, models.SuscripcionProyeccion{Fecha: fecha.Format("2006-01-02"), Monto: monto})
+	}
+
+	return calendario
+}
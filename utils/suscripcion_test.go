@@ -0,0 +1,83 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+var testCaracas = time.FixedZone("VET", -4*60*60)
+
+func testDate(value string) time.Time {
+	date, err := time.ParseInLocation(time.DateTime, value, testCaracas)
+	if err != nil {
+		date, _ = time.ParseInLocation(time.DateOnly, value, testCaracas)
+	}
+	return date
+}
+
+func TestRenewalDateOfMonth(t *testing.T) {
+	tests := []struct {
+		name       string
+		year       int
+		month      time.Month
+		renewalDay int
+		expected   string
+	}{
+		{"day 29 on february of leap year", 2024, time.February, 29, "2024-02-29"},
+		{"day 30 on february of leap year", 2024, time.February, 30, "2024-02-29"},
+		{"day 31 on february of leap year", 2024, time.February, 31, "2024-02-29"},
+		{"day 29 on february", 2025, time.February, 29, "2025-02-28"},
+		{"day 30 on february", 2025, time.February, 30, "2025-02-28"},
+		{"day 31 on february", 2025, time.February, 31, "2025-02-28"},
+		{"day 31 on month of 30 days", 2025, time.April, 31, "2025-04-30"},
+		{"day 15", 2025, time.March, 15, "2025-03-15"},
+		{"month after december", 2024, time.December + 1, 31, "2025-01-31"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := RenewalDateOfMonth(test.year, test.month, test.renewalDay, testCaracas)
+			if got.Format(time.DateOnly) != test.expected {
+				t.Fatalf("expected %s, got %s", test.expected, got.Format(time.DateOnly))
+			}
+			if got.Location() != testCaracas || got.Hour() != 0 {
+				t.Fatalf("expected midnight of Caracas, got %s", got)
+			}
+		})
+	}
+}
+
+func TestNextRenewalDate(t *testing.T) {
+	tests := []struct {
+		name     string
+		install  string
+		now      string
+		expected string
+	}{
+		{"install 29 before february of leap year", "2023-01-29", "2024-02-10 08:00:00", "2024-02-29"},
+		{"install 30 before february of leap year", "2023-01-30", "2024-02-10 08:00:00", "2024-02-29"},
+		{"install 31 before february of leap year", "2023-01-31", "2024-02-10 08:00:00", "2024-02-29"},
+		{"install 29 before february", "2023-01-29", "2025-02-10 08:00:00", "2025-02-28"},
+		{"install 30 before february", "2023-01-30", "2025-02-10 08:00:00", "2025-02-28"},
+		{"install 31 before february", "2023-01-31", "2025-02-10 08:00:00", "2025-02-28"},
+		{"install 31 on the last day of february", "2023-01-31", "2025-02-28 23:59:00", "2025-02-28"},
+		{"install 30 on the last day of february of leap year", "2023-01-30", "2024-02-29 00:00:00", "2024-02-29"},
+		{"install 30 after the renewal of january", "2023-01-30", "2025-01-31 10:00:00", "2025-02-28"},
+		{"install 31 after the renewal of february", "2023-01-31", "2025-03-01 00:00:00", "2025-03-31"},
+		{"today is the renewal day", "2023-05-15", "2025-06-15 00:00:00", "2025-06-15"},
+		{"today is the renewal day at night", "2023-05-15", "2025-06-15 23:59:59", "2025-06-15"},
+		{"day after the renewal", "2023-05-15", "2025-06-16 00:00:00", "2025-07-15"},
+		{"december after the renewal", "2023-05-15", "2024-12-20 12:00:00", "2025-01-15"},
+		{"december before the renewal", "2023-05-15", "2024-12-10 12:00:00", "2024-12-15"},
+		{"install 31 on the last day of december", "2023-05-31", "2024-12-31 18:00:00", "2024-12-31"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := NextRenewalDate(testDate(test.install), testDate(test.now))
+			if got.Format(time.DateOnly) != test.expected {
+				t.Fatalf("expected %s, got %s", test.expected, got.Format(time.DateOnly))
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package utils
+
+import (
+	"encoding/base32"
+	"testing"
+	"time"
+)
+
+// RFC 6238 appendix B, HMAC-SHA1 with the ascii secret 12345678901234567890. The RFC gives
+// codes of 8 digits, the ones of 6 digits are the last 6 of them
+func TestTotpRfc6238Vectors(t *testing.T) {
+	secret := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+	tests := []struct {
+		unix     int64
+		expected string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+
+	for _, test := range tests {
+		code, err := totpCode(secret, test.unix/totpPeriod)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if code != test.expected {
+			t.Errorf("time %d: expected %s, got %s", test.unix, test.expected, code)
+		}
+
+		step, ok := ValidateTotp(secret, test.expected, time.Unix(test.unix, 0))
+		if !ok || step != test.unix/totpPeriod {
+			t.Errorf("time %d: expected code valid on step %d, got %d %v", test.unix, test.unix/totpPeriod, step, ok)
+		}
+	}
+}
+
+func TestValidateTotpSkew(t *testing.T) {
+	secret := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+	// 1111111111 is on the step 37037037, its code is 050471
+	tests := []struct {
+		name  string
+		unix  int64
+		code  string
+		valid bool
+	}{
+		{"same step", 1111111111, "050471", true},
+		{"one step later", 1111111111 + totpPeriod, "050471", true},
+		{"one step before", 1111111111 - totpPeriod, "050471", true},
+		{"two steps later", 1111111111 + 2*totpPeriod, "050471", false},
+		{"two steps before", 1111111111 - 2*totpPeriod, "050471", false},
+		{"wrong code", 1111111111, "050472", false},
+		{"code of 8 digits", 1111111111, "14050471", false},
+		{"empty code", 1111111111, "", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, ok := ValidateTotp(secret, test.code, time.Unix(test.unix, 0)); ok != test.valid {
+				t.Fatalf("expected valid %v, got %v", test.valid, ok)
+			}
+		})
+	}
+}